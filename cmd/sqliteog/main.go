@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"github.com/aousomran/sqlite-og/pkg/driver"
+)
+
+const usage = `usage: sqliteog <command> [flags] <dsn>
+
+commands:
+  backup    writes a copy of the database to a local file
+  restore   replaces the database with a local file
+
+dsn: sqliteog://host:port/path/to/db.db?tls=true&token=...
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var err error
+	switch os.Args[1] {
+	case "backup":
+		err = backup(ctx, os.Args[2:])
+	case "restore":
+		err = restore(ctx, os.Args[2:])
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(os.Stdout, usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %s\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sqliteog %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+// backup writes the backup next to the output file, it's renamed once it's
+// complete & verified so that the output is never a partial copy
+func backup(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("backup", flag.ExitOnError)
+	output := flags.String("o", "", "file the backup is written to (default: the name of the database in the working directory)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: sqliteog backup [-o file] <dsn>")
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	dsn := flags.Arg(0)
+	cfg, err := driver.ParseDSN(dsn)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		if cfg.DBName == "" {
+			return fmt.Errorf("the dsn has no database name, set the output file with -o")
+		}
+		*output = filepath.Base(cfg.DBName)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(*output), filepath.Base(*output)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	size, err := driver.Backup(ctx, dsn, f)
+	if errClose := f.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(f.Name(), *output); err != nil {
+		return err
+	}
+	fmt.Printf("%s: %d bytes\n", *output, size)
+	return nil
+}
+
+func restore(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("restore", flag.ExitOnError)
+	force := flags.Bool("force", false, "closes the sessions open on the database, the restore is refused otherwise")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: sqliteog restore [-force] <file> <dsn>")
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+	if flags.NArg() != 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	f, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := driver.Restore(ctx, flags.Arg(1), f, *force); err != nil {
+		return err
+	}
+	fmt.Printf("%s restored\n", flags.Arg(0))
+	return nil
+}
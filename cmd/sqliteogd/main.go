@@ -9,26 +9,48 @@ import (
 	"net/http"
 	_ "net/http/pprof"
 	"os"
-	"sort"
 	"strings"
 	"time"
 
 	"golang.org/x/exp/slog"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	// lets clients compress their messages with compress=gzip
+	_ "google.golang.org/grpc/encoding/gzip"
 	"google.golang.org/grpc/reflection"
 
 	pb "github.com/aousomran/sqlite-og/gen/proto"
+	"github.com/aousomran/sqlite-og/internal/auth"
+	"github.com/aousomran/sqlite-og/internal/callback"
+	"github.com/aousomran/sqlite-og/internal/changelog"
 	"github.com/aousomran/sqlite-og/internal/connections"
+	"github.com/aousomran/sqlite-og/internal/dbwrapper"
 	"github.com/aousomran/sqlite-og/internal/server"
 )
 
 var (
-	port             = flag.Int("port", 9091, "The server port")
-	statsInterval    = flag.Duration("stats-interval", 0*time.Second, "interval in seconds for logging stats, use 0s to disable (default 0s)")
-	logLevel         = flag.String("log-level", "info", "minimum log level to print out, choices (debug,info,warn,error) ")
-	logFormat        = flag.String("log-format", "text", "log format choices (text,json)")
-	pprofEnabled     = flag.Bool("enable-pprof", false, "enabled pprof at localhost:6060")
-	discoveryEnabled = flag.Bool("enable-discovery", false, "enables grpc service discovery")
+	port                = flag.Int("port", 9091, "The server port")
+	statsInterval       = flag.Duration("stats-interval", 0*time.Second, "interval in seconds for logging stats, use 0s to disable (default 0s)")
+	logLevel            = flag.String("log-level", "info", "minimum log level to print out, choices (debug,info,warn,error) ")
+	logFormat           = flag.String("log-format", "text", "log format choices (text,json)")
+	pprofEnabled        = flag.Bool("enable-pprof", false, "enabled pprof at localhost:6060")
+	discoveryEnabled    = flag.Bool("enable-discovery", false, "enables grpc service discovery")
+	adminEnabled        = flag.Bool("enable-admin", false, "enables the Admin service creating, dropping & renaming the databases")
+	callbackTimeout     = flag.Duration("callback-timeout", callback.DefaultTimeout, "maximum time a client callback may take, the statement invoking it fails after that, use 0s to wait forever")
+	changelogRetention  = flag.Duration("changelog-retention", 7*24*time.Hour, "changelog entries older than this are deleted, use 0s to keep them")
+	changelogMaxEntries = flag.Int64("changelog-max-entries", 0, "maximum number of entries kept in a changelog, use 0 for no limit")
+	changelogCompact    = flag.Bool("changelog-compact", false, "deletes changelog entries superseded by a later change of the same row")
+	changelogInterval   = flag.Duration("changelog-compact-interval", time.Minute, "interval between applying the changelog retention, use 0s to disable")
+	tlsCert             = flag.String("tls-cert", "", "certificate file of the server, enables TLS with -tls-key")
+	tlsKey              = flag.String("tls-key", "", "private key file of the server certificate")
+	tlsClientCA         = flag.String("tls-client-ca", "", "CA bundle verifying the client certificates, clients presenting a certificate must be signed by it")
+	tlsRequireClient    = flag.Bool("tls-require-client-cert", false, "rejects the clients without a certificate signed by -tls-client-ca (mutual TLS)")
+	dataDir             = flag.String("data-dir", "", "directory of the databases, clients cannot open files outside of it (default: the working directory, unconfined)")
+	createDatabases     = flag.Bool("create-databases", true, "lets clients create the databases that do not exist")
+	authTokens          = flag.String("auth-tokens", "", "file of `<identity> <token>` lines, clients must send one of the tokens as a bearer token, sessions can only be used by the identity that opened them (without authentication, by the IP address or certificate of the client)")
+	authMTLS            = flag.Bool("auth-mtls", false, "authenticates the clients by the common name of their certificate, requires -tls-client-ca")
+	authPolicy          = flag.String("auth-policy", "", "JSON file mapping the identities to the databases they may open & their access, requires authentication")
+	sessionIdleTimeout  = flag.Duration("session-idle-timeout", 30*time.Minute, "sessions unused for longer are closed, e.g. the sessions of clients that went away without closing them, use 0s to keep them")
 )
 
 func loggingInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
@@ -68,16 +90,34 @@ func connectionStats(manager *connections.Manager, duration time.Duration) {
 	for {
 		select {
 		case <-ticker:
-			keys := make([]string, 0)
-			for k, _ := range manager.CnxMap {
-				keys = append(keys, k)
-			}
-			sort.Strings(keys)
-			slog.Info("db connection stats", "count", len(keys), "ids", keys)
+			dbnames := manager.Names()
+			slog.Info("db connection stats", "count", len(dbnames), "dbnames", dbnames)
 		}
 	}
 }
 
+func reapSessions(manager *connections.Manager, timeout time.Duration) {
+	if timeout <= 0 {
+		slog.Info("idle sessions are kept")
+		return
+	}
+	ticker := time.NewTicker(timeout / 2).C
+	for range ticker {
+		manager.ReapSessions(context.Background())
+	}
+}
+
+func compactChangelogs(manager *connections.Manager, interval time.Duration) {
+	if interval <= 0 {
+		slog.Info("changelog retention disabled")
+		return
+	}
+	ticker := time.NewTicker(interval).C
+	for range ticker {
+		manager.CompactChangelogs(context.Background())
+	}
+}
+
 func initLogger(level, format string) {
 	l := slog.Default()
 	loggerOpts := &slog.HandlerOptions{
@@ -121,23 +161,96 @@ func main() {
 		}()
 	}
 
+	if *dataDir != "" {
+		if info, err := os.Stat(*dataDir); err != nil || !info.IsDir() {
+			log.Fatalf("-data-dir %s is not a directory", *dataDir)
+		}
+	} else {
+		slog.Warn("databases are not confined, clients can open any file, set -data-dir")
+	}
+
 	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", *port))
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
 
-	s := grpc.NewServer(
-		grpc.UnaryInterceptor(loggingInterceptor),
-	)
+	var authenticators auth.Authenticators
+	if *authTokens != "" {
+		tokens, err := auth.LoadTokens(*authTokens)
+		if err != nil {
+			log.Fatalf("failed to load tokens: %v", err)
+		}
+		if *tlsCert == "" {
+			slog.Warn("tokens are sent in plaintext, enable TLS with -tls-cert & -tls-key")
+		}
+		authenticators = append(authenticators, tokens)
+	}
+	if *authMTLS {
+		if *tlsClientCA == "" {
+			log.Fatalf("-auth-mtls requires -tls-client-ca")
+		}
+		authenticators = append(authenticators, auth.MTLS{})
+	}
+	var policy *auth.Policy
+	if *authPolicy != "" {
+		if len(authenticators) == 0 {
+			log.Fatalf("-auth-policy requires -auth-tokens or -auth-mtls")
+		}
+		if policy, err = auth.LoadPolicy(*authPolicy); err != nil {
+			log.Fatalf("failed to load policy: %v", err)
+		}
+	}
+
+	unaryInterceptors := []grpc.UnaryServerInterceptor{loggingInterceptor}
+	var streamInterceptors []grpc.StreamServerInterceptor
+	if len(authenticators) > 0 {
+		unaryInterceptors = append(unaryInterceptors, auth.UnaryServerInterceptor(authenticators))
+		streamInterceptors = append(streamInterceptors, auth.StreamServerInterceptor(authenticators))
+	} else {
+		slog.Warn("authentication is disabled, any client can open any database & sessions are only bound to the IP address of the client that opened them, clients sharing an address (e.g. behind a NAT or proxy) can use each other's sessions")
+	}
+	serverOpts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+	}
+	if *tlsCert != "" || *tlsKey != "" {
+		tlsConfig, err := server.NewTLSConfig(server.TLSOptions{
+			CertFile:          *tlsCert,
+			KeyFile:           *tlsKey,
+			ClientCAFile:      *tlsClientCA,
+			RequireClientCert: *tlsRequireClient,
+		})
+		if err != nil {
+			log.Fatalf("failed to configure TLS: %v", err)
+		}
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	} else {
+		slog.Warn("TLS is disabled, connections are not encrypted")
+	}
+	s := grpc.NewServer(serverOpts...)
 
 	if *discoveryEnabled {
 		reflection.Register(s)
 	}
 
 	manager := connections.NewManager()
+	manager.CallbackTimeout = *callbackTimeout
+	manager.DataDir = dbwrapper.DataDir{Root: *dataDir, Create: *createDatabases}
+	manager.SessionIdleTimeout = *sessionIdleTimeout
+	manager.ChangelogRetention = changelog.Retention{
+		MaxAge:     *changelogRetention,
+		MaxEntries: *changelogMaxEntries,
+		Compact:    *changelogCompact,
+	}
 	go connectionStats(manager, *statsInterval)
+	go compactChangelogs(manager, *changelogInterval)
+	go reapSessions(manager, *sessionIdleTimeout)
 	srv := server.New(manager)
+	srv.Policy = policy
 	pb.RegisterSqliteOGServer(s, srv)
+	if *adminEnabled {
+		pb.RegisterAdminServer(s, server.NewAdmin(srv))
+	}
 	slog.Info("server listening ", "addr", listener.Addr())
 
 	// close things gracefully
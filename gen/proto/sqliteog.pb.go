@@ -20,6 +20,208 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type AccessMode int32
+
+const (
+	AccessMode_READ_WRITE AccessMode = 0
+	// the database is opened read only, only TEMP objects can be written
+	AccessMode_READ_ONLY AccessMode = 1
+	// rows can be written but the schema cannot be changed
+	AccessMode_NO_DDL AccessMode = 2
+)
+
+// Enum value maps for AccessMode.
+var (
+	AccessMode_name = map[int32]string{
+		0: "READ_WRITE",
+		1: "READ_ONLY",
+		2: "NO_DDL",
+	}
+	AccessMode_value = map[string]int32{
+		"READ_WRITE": 0,
+		"READ_ONLY":  1,
+		"NO_DDL":     2,
+	}
+)
+
+func (x AccessMode) Enum() *AccessMode {
+	p := new(AccessMode)
+	*p = x
+	return p
+}
+
+func (x AccessMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AccessMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_sqliteog_proto_enumTypes[0].Descriptor()
+}
+
+func (AccessMode) Type() protoreflect.EnumType {
+	return &file_proto_sqliteog_proto_enumTypes[0]
+}
+
+func (x AccessMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AccessMode.Descriptor instead.
+func (AccessMode) EnumDescriptor() ([]byte, []int) {
+	return file_proto_sqliteog_proto_rawDescGZIP(), []int{0}
+}
+
+type TxLock int32
+
+const (
+	TxLock_DEFERRED  TxLock = 0
+	TxLock_IMMEDIATE TxLock = 1
+	TxLock_EXCLUSIVE TxLock = 2
+)
+
+// Enum value maps for TxLock.
+var (
+	TxLock_name = map[int32]string{
+		0: "DEFERRED",
+		1: "IMMEDIATE",
+		2: "EXCLUSIVE",
+	}
+	TxLock_value = map[string]int32{
+		"DEFERRED":  0,
+		"IMMEDIATE": 1,
+		"EXCLUSIVE": 2,
+	}
+)
+
+func (x TxLock) Enum() *TxLock {
+	p := new(TxLock)
+	*p = x
+	return p
+}
+
+func (x TxLock) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TxLock) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_sqliteog_proto_enumTypes[1].Descriptor()
+}
+
+func (TxLock) Type() protoreflect.EnumType {
+	return &file_proto_sqliteog_proto_enumTypes[1]
+}
+
+func (x TxLock) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TxLock.Descriptor instead.
+func (TxLock) EnumDescriptor() ([]byte, []int) {
+	return file_proto_sqliteog_proto_rawDescGZIP(), []int{1}
+}
+
+type InvokeType int32
+
+const (
+	InvokeType_FUNCTION       InvokeType = 0
+	InvokeType_AGGREGATE_STEP InvokeType = 1
+	InvokeType_AGGREGATE_DONE InvokeType = 2
+	// compares the two text args, the result is a negative, zero or positive integer
+	InvokeType_COLLATION InvokeType = 3
+)
+
+// Enum value maps for InvokeType.
+var (
+	InvokeType_name = map[int32]string{
+		0: "FUNCTION",
+		1: "AGGREGATE_STEP",
+		2: "AGGREGATE_DONE",
+		3: "COLLATION",
+	}
+	InvokeType_value = map[string]int32{
+		"FUNCTION":       0,
+		"AGGREGATE_STEP": 1,
+		"AGGREGATE_DONE": 2,
+		"COLLATION":      3,
+	}
+)
+
+func (x InvokeType) Enum() *InvokeType {
+	p := new(InvokeType)
+	*p = x
+	return p
+}
+
+func (x InvokeType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (InvokeType) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_sqliteog_proto_enumTypes[2].Descriptor()
+}
+
+func (InvokeType) Type() protoreflect.EnumType {
+	return &file_proto_sqliteog_proto_enumTypes[2]
+}
+
+func (x InvokeType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use InvokeType.Descriptor instead.
+func (InvokeType) EnumDescriptor() ([]byte, []int) {
+	return file_proto_sqliteog_proto_rawDescGZIP(), []int{2}
+}
+
+type ChangeOperation int32
+
+const (
+	ChangeOperation_INSERT ChangeOperation = 0
+	ChangeOperation_UPDATE ChangeOperation = 1
+	ChangeOperation_DELETE ChangeOperation = 2
+)
+
+// Enum value maps for ChangeOperation.
+var (
+	ChangeOperation_name = map[int32]string{
+		0: "INSERT",
+		1: "UPDATE",
+		2: "DELETE",
+	}
+	ChangeOperation_value = map[string]int32{
+		"INSERT": 0,
+		"UPDATE": 1,
+		"DELETE": 2,
+	}
+)
+
+func (x ChangeOperation) Enum() *ChangeOperation {
+	p := new(ChangeOperation)
+	*p = x
+	return p
+}
+
+func (x ChangeOperation) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ChangeOperation) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_sqliteog_proto_enumTypes[3].Descriptor()
+}
+
+func (ChangeOperation) Type() protoreflect.EnumType {
+	return &file_proto_sqliteog_proto_enumTypes[3]
+}
+
+func (x ChangeOperation) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ChangeOperation.Descriptor instead.
+func (ChangeOperation) EnumDescriptor() ([]byte, []int) {
+	return file_proto_sqliteog_proto_rawDescGZIP(), []int{3}
+}
+
 type Empty struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -110,9 +312,22 @@ type ConnectionRequest struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	DbName      string   `protobuf:"bytes,1,opt,name=db_name,json=dbName,proto3" json:"db_name,omitempty"`
-	Functions   []string `protobuf:"bytes,2,rep,name=functions,proto3" json:"functions,omitempty"`
-	Aggregators []string `protobuf:"bytes,3,rep,name=aggregators,proto3" json:"aggregators,omitempty"`
+	DbName string `protobuf:"bytes,1,opt,name=db_name,json=dbName,proto3" json:"db_name,omitempty"`
+	// aggregators are the names of deterministic aggregate functions, see
+	// aggregate_functions
+	Aggregators []string    `protobuf:"bytes,3,rep,name=aggregators,proto3" json:"aggregators,omitempty"`
+	Functions   []*Function `protobuf:"bytes,4,rep,name=functions,proto3" json:"functions,omitempty"`
+	// collations compared by the client
+	Collations []string `protobuf:"bytes,5,rep,name=collations,proto3" json:"collations,omitempty"`
+	// collations compared by the server, without a round trip per comparison
+	BuiltinCollations []string `protobuf:"bytes,6,rep,name=builtin_collations,json=builtinCollations,proto3" json:"builtin_collations,omitempty"`
+	// access restricts the statements of the session, it has full access when unset
+	Access *Access `protobuf:"bytes,7,opt,name=access,proto3" json:"access,omitempty"`
+	// options are PRAGMAs applied when the session's connection is opened,
+	// e.g. busy_timeout=5000, foreign_keys=on, journal_mode=wal
+	Options map[string]string `protobuf:"bytes,8,rep,name=options,proto3" json:"options,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// aggregate functions, the servers ignoring them register aggregators
+	AggregateFunctions []*Function `protobuf:"bytes,9,rep,name=aggregate_functions,json=aggregateFunctions,proto3" json:"aggregate_functions,omitempty"`
 }
 
 func (x *ConnectionRequest) Reset() {
@@ -154,31 +369,73 @@ func (x *ConnectionRequest) GetDbName() string {
 	return ""
 }
 
-func (x *ConnectionRequest) GetFunctions() []string {
+func (x *ConnectionRequest) GetAggregators() []string {
+	if x != nil {
+		return x.Aggregators
+	}
+	return nil
+}
+
+func (x *ConnectionRequest) GetFunctions() []*Function {
 	if x != nil {
 		return x.Functions
 	}
 	return nil
 }
 
-func (x *ConnectionRequest) GetAggregators() []string {
+func (x *ConnectionRequest) GetCollations() []string {
 	if x != nil {
-		return x.Aggregators
+		return x.Collations
 	}
 	return nil
 }
 
-type InvocationResult struct {
+func (x *ConnectionRequest) GetBuiltinCollations() []string {
+	if x != nil {
+		return x.BuiltinCollations
+	}
+	return nil
+}
+
+func (x *ConnectionRequest) GetAccess() *Access {
+	if x != nil {
+		return x.Access
+	}
+	return nil
+}
+
+func (x *ConnectionRequest) GetOptions() map[string]string {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *ConnectionRequest) GetAggregateFunctions() []*Function {
+	if x != nil {
+		return x.AggregateFunctions
+	}
+	return nil
+}
+
+// Access is enforced by sqlite's authorizer on the session's connection.
+// Table & column names are case insensitive, columns are named table.column.
+// Allowed lists are ignored when empty, a denied name is always denied.
+type Access struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Initial bool     `protobuf:"varint,1,opt,name=initial,proto3" json:"initial,omitempty"`
-	Result  []string `protobuf:"bytes,2,rep,name=result,proto3" json:"result,omitempty"`
+	Mode          AccessMode `protobuf:"varint,1,opt,name=mode,proto3,enum=AccessMode" json:"mode,omitempty"`
+	AllowedTables []string   `protobuf:"bytes,2,rep,name=allowed_tables,json=allowedTables,proto3" json:"allowed_tables,omitempty"`
+	DeniedTables  []string   `protobuf:"bytes,3,rep,name=denied_tables,json=deniedTables,proto3" json:"denied_tables,omitempty"`
+	// allowed_columns restrict the columns of the tables they name
+	AllowedColumns []string `protobuf:"bytes,4,rep,name=allowed_columns,json=allowedColumns,proto3" json:"allowed_columns,omitempty"`
+	DeniedColumns  []string `protobuf:"bytes,5,rep,name=denied_columns,json=deniedColumns,proto3" json:"denied_columns,omitempty"`
 }
 
-func (x *InvocationResult) Reset() {
-	*x = InvocationResult{}
+func (x *Access) Reset() {
+	*x = Access{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_proto_sqliteog_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -186,13 +443,13 @@ func (x *InvocationResult) Reset() {
 	}
 }
 
-func (x *InvocationResult) String() string {
+func (x *Access) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*InvocationResult) ProtoMessage() {}
+func (*Access) ProtoMessage() {}
 
-func (x *InvocationResult) ProtoReflect() protoreflect.Message {
+func (x *Access) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_sqliteog_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -204,36 +461,61 @@ func (x *InvocationResult) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use InvocationResult.ProtoReflect.Descriptor instead.
-func (*InvocationResult) Descriptor() ([]byte, []int) {
+// Deprecated: Use Access.ProtoReflect.Descriptor instead.
+func (*Access) Descriptor() ([]byte, []int) {
 	return file_proto_sqliteog_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *InvocationResult) GetInitial() bool {
+func (x *Access) GetMode() AccessMode {
 	if x != nil {
-		return x.Initial
+		return x.Mode
 	}
-	return false
+	return AccessMode_READ_WRITE
 }
 
-func (x *InvocationResult) GetResult() []string {
+func (x *Access) GetAllowedTables() []string {
 	if x != nil {
-		return x.Result
+		return x.AllowedTables
 	}
 	return nil
 }
 
-type Invoke struct {
+func (x *Access) GetDeniedTables() []string {
+	if x != nil {
+		return x.DeniedTables
+	}
+	return nil
+}
+
+func (x *Access) GetAllowedColumns() []string {
+	if x != nil {
+		return x.AllowedColumns
+	}
+	return nil
+}
+
+func (x *Access) GetDeniedColumns() []string {
+	if x != nil {
+		return x.DeniedColumns
+	}
+	return nil
+}
+
+// Function is a client side function called through the Callback stream
+type Function struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	FunctionName string   `protobuf:"bytes,1,opt,name=functionName,proto3" json:"functionName,omitempty"`
-	Args         []string `protobuf:"bytes,2,rep,name=args,proto3" json:"args,omitempty"`
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// number of arguments, -1 when the function is variadic
+	NumArgs int32 `protobuf:"varint,2,opt,name=num_args,json=numArgs,proto3" json:"num_args,omitempty"`
+	// deterministic functions can be used in indexes & CHECK constraints
+	Deterministic bool `protobuf:"varint,3,opt,name=deterministic,proto3" json:"deterministic,omitempty"`
 }
 
-func (x *Invoke) Reset() {
-	*x = Invoke{}
+func (x *Function) Reset() {
+	*x = Function{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_proto_sqliteog_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -241,13 +523,13 @@ func (x *Invoke) Reset() {
 	}
 }
 
-func (x *Invoke) String() string {
+func (x *Function) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Invoke) ProtoMessage() {}
+func (*Function) ProtoMessage() {}
 
-func (x *Invoke) ProtoReflect() protoreflect.Message {
+func (x *Function) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_sqliteog_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -259,36 +541,44 @@ func (x *Invoke) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Invoke.ProtoReflect.Descriptor instead.
-func (*Invoke) Descriptor() ([]byte, []int) {
+// Deprecated: Use Function.ProtoReflect.Descriptor instead.
+func (*Function) Descriptor() ([]byte, []int) {
 	return file_proto_sqliteog_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *Invoke) GetFunctionName() string {
+func (x *Function) GetName() string {
 	if x != nil {
-		return x.FunctionName
+		return x.Name
 	}
 	return ""
 }
 
-func (x *Invoke) GetArgs() []string {
+func (x *Function) GetNumArgs() int32 {
 	if x != nil {
-		return x.Args
+		return x.NumArgs
 	}
-	return nil
+	return 0
 }
 
-type ExecuteOrQueryResult struct {
+func (x *Function) GetDeterministic() bool {
+	if x != nil {
+		return x.Deterministic
+	}
+	return false
+}
+
+type BeginRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	QueryResult   *QueryResult   `protobuf:"bytes,1,opt,name=query_result,json=queryResult,proto3" json:"query_result,omitempty"`
-	ExecuteResult *ExecuteResult `protobuf:"bytes,2,opt,name=execute_result,json=executeResult,proto3" json:"execute_result,omitempty"`
+	CnxId    string `protobuf:"bytes,1,opt,name=cnx_id,json=cnxId,proto3" json:"cnx_id,omitempty"`
+	ReadOnly bool   `protobuf:"varint,2,opt,name=read_only,json=readOnly,proto3" json:"read_only,omitempty"`
+	Lock     TxLock `protobuf:"varint,3,opt,name=lock,proto3,enum=TxLock" json:"lock,omitempty"`
 }
 
-func (x *ExecuteOrQueryResult) Reset() {
-	*x = ExecuteOrQueryResult{}
+func (x *BeginRequest) Reset() {
+	*x = BeginRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_proto_sqliteog_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -296,13 +586,13 @@ func (x *ExecuteOrQueryResult) Reset() {
 	}
 }
 
-func (x *ExecuteOrQueryResult) String() string {
+func (x *BeginRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ExecuteOrQueryResult) ProtoMessage() {}
+func (*BeginRequest) ProtoMessage() {}
 
-func (x *ExecuteOrQueryResult) ProtoReflect() protoreflect.Message {
+func (x *BeginRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_sqliteog_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -314,37 +604,48 @@ func (x *ExecuteOrQueryResult) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ExecuteOrQueryResult.ProtoReflect.Descriptor instead.
-func (*ExecuteOrQueryResult) Descriptor() ([]byte, []int) {
+// Deprecated: Use BeginRequest.ProtoReflect.Descriptor instead.
+func (*BeginRequest) Descriptor() ([]byte, []int) {
 	return file_proto_sqliteog_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *ExecuteOrQueryResult) GetQueryResult() *QueryResult {
+func (x *BeginRequest) GetCnxId() string {
 	if x != nil {
-		return x.QueryResult
+		return x.CnxId
 	}
-	return nil
+	return ""
 }
 
-func (x *ExecuteOrQueryResult) GetExecuteResult() *ExecuteResult {
+func (x *BeginRequest) GetReadOnly() bool {
 	if x != nil {
-		return x.ExecuteResult
+		return x.ReadOnly
 	}
-	return nil
+	return false
 }
 
-type Statement struct {
+func (x *BeginRequest) GetLock() TxLock {
+	if x != nil {
+		return x.Lock
+	}
+	return TxLock_DEFERRED
+}
+
+type InvocationResult struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Sql    string   `protobuf:"bytes,1,opt,name=sql,proto3" json:"sql,omitempty"`
-	Params []string `protobuf:"bytes,2,rep,name=params,proto3" json:"params,omitempty"`
-	CnxId  string   `protobuf:"bytes,3,opt,name=cnx_id,json=cnxId,proto3" json:"cnx_id,omitempty"`
+	Initial bool `protobuf:"varint,1,opt,name=initial,proto3" json:"initial,omitempty"`
+	// unset or NULL when the callback has no result
+	Result *Value `protobuf:"bytes,5,opt,name=result,proto3" json:"result,omitempty"`
+	// id of the Invoke this is the result of
+	Id uint64 `protobuf:"varint,3,opt,name=id,proto3" json:"id,omitempty"`
+	// set when the callback failed, the statement invoking it fails with it
+	Error string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
 }
 
-func (x *Statement) Reset() {
-	*x = Statement{}
+func (x *InvocationResult) Reset() {
+	*x = InvocationResult{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_proto_sqliteog_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -352,13 +653,13 @@ func (x *Statement) Reset() {
 	}
 }
 
-func (x *Statement) String() string {
+func (x *InvocationResult) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Statement) ProtoMessage() {}
+func (*InvocationResult) ProtoMessage() {}
 
-func (x *Statement) ProtoReflect() protoreflect.Message {
+func (x *InvocationResult) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_sqliteog_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -370,42 +671,55 @@ func (x *Statement) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Statement.ProtoReflect.Descriptor instead.
-func (*Statement) Descriptor() ([]byte, []int) {
+// Deprecated: Use InvocationResult.ProtoReflect.Descriptor instead.
+func (*InvocationResult) Descriptor() ([]byte, []int) {
 	return file_proto_sqliteog_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *Statement) GetSql() string {
+func (x *InvocationResult) GetInitial() bool {
 	if x != nil {
-		return x.Sql
+		return x.Initial
 	}
-	return ""
+	return false
 }
 
-func (x *Statement) GetParams() []string {
+func (x *InvocationResult) GetResult() *Value {
 	if x != nil {
-		return x.Params
+		return x.Result
 	}
 	return nil
 }
 
-func (x *Statement) GetCnxId() string {
+func (x *InvocationResult) GetId() uint64 {
 	if x != nil {
-		return x.CnxId
+		return x.Id
+	}
+	return 0
+}
+
+func (x *InvocationResult) GetError() string {
+	if x != nil {
+		return x.Error
 	}
 	return ""
 }
 
-type Row struct {
+type Invoke struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Fields []string `protobuf:"bytes,1,rep,name=fields,proto3" json:"fields,omitempty"`
+	FunctionName string     `protobuf:"bytes,1,opt,name=functionName,proto3" json:"functionName,omitempty"`
+	Args         []*Value   `protobuf:"bytes,6,rep,name=args,proto3" json:"args,omitempty"`
+	Type         InvokeType `protobuf:"varint,3,opt,name=type,proto3,enum=InvokeType" json:"type,omitempty"`
+	// identifies the aggregate group, the client keeps one state per id
+	StateId int64 `protobuf:"varint,4,opt,name=state_id,json=stateId,proto3" json:"state_id,omitempty"`
+	// correlates the invocation with its InvocationResult
+	Id uint64 `protobuf:"varint,5,opt,name=id,proto3" json:"id,omitempty"`
 }
 
-func (x *Row) Reset() {
-	*x = Row{}
+func (x *Invoke) Reset() {
+	*x = Invoke{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_proto_sqliteog_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -413,13 +727,13 @@ func (x *Row) Reset() {
 	}
 }
 
-func (x *Row) String() string {
+func (x *Invoke) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Row) ProtoMessage() {}
+func (*Invoke) ProtoMessage() {}
 
-func (x *Row) ProtoReflect() protoreflect.Message {
+func (x *Invoke) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_sqliteog_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -431,45 +745,1335 @@ func (x *Row) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Row.ProtoReflect.Descriptor instead.
-func (*Row) Descriptor() ([]byte, []int) {
+// Deprecated: Use Invoke.ProtoReflect.Descriptor instead.
+func (*Invoke) Descriptor() ([]byte, []int) {
 	return file_proto_sqliteog_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *Row) GetFields() []string {
+func (x *Invoke) GetFunctionName() string {
 	if x != nil {
-		return x.Fields
+		return x.FunctionName
+	}
+	return ""
+}
+
+func (x *Invoke) GetArgs() []*Value {
+	if x != nil {
+		return x.Args
 	}
 	return nil
 }
 
-type QueryResult struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
+func (x *Invoke) GetType() InvokeType {
+	if x != nil {
+		return x.Type
+	}
+	return InvokeType_FUNCTION
+}
 
-	Columns     []string `protobuf:"bytes,1,rep,name=columns,proto3" json:"columns,omitempty"`
-	ColumnTypes []string `protobuf:"bytes,2,rep,name=columnTypes,proto3" json:"columnTypes,omitempty"`
-	Rows        []*Row   `protobuf:"bytes,3,rep,name=rows,proto3" json:"rows,omitempty"`
+func (x *Invoke) GetStateId() int64 {
+	if x != nil {
+		return x.StateId
+	}
+	return 0
 }
 
-func (x *QueryResult) Reset() {
+func (x *Invoke) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ExecuteOrQueryResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	QueryResult   *QueryResult   `protobuf:"bytes,1,opt,name=query_result,json=queryResult,proto3" json:"query_result,omitempty"`
+	ExecuteResult *ExecuteResult `protobuf:"bytes,2,opt,name=execute_result,json=executeResult,proto3" json:"execute_result,omitempty"`
+}
+
+func (x *ExecuteOrQueryResult) Reset() {
+	*x = ExecuteOrQueryResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_sqliteog_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExecuteOrQueryResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteOrQueryResult) ProtoMessage() {}
+
+func (x *ExecuteOrQueryResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sqliteog_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteOrQueryResult.ProtoReflect.Descriptor instead.
+func (*ExecuteOrQueryResult) Descriptor() ([]byte, []int) {
+	return file_proto_sqliteog_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ExecuteOrQueryResult) GetQueryResult() *QueryResult {
+	if x != nil {
+		return x.QueryResult
+	}
+	return nil
+}
+
+func (x *ExecuteOrQueryResult) GetExecuteResult() *ExecuteResult {
+	if x != nil {
+		return x.ExecuteResult
+	}
+	return nil
+}
+
+type Statement struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sql    string       `protobuf:"bytes,1,opt,name=sql,proto3" json:"sql,omitempty"`
+	Params []*Parameter `protobuf:"bytes,2,rep,name=params,proto3" json:"params,omitempty"`
+	CnxId  string       `protobuf:"bytes,3,opt,name=cnx_id,json=cnxId,proto3" json:"cnx_id,omitempty"`
+	// handle of a prepared statement, sql is ignored when set
+	StmtId string `protobuf:"bytes,4,opt,name=stmt_id,json=stmtId,proto3" json:"stmt_id,omitempty"`
+}
+
+func (x *Statement) Reset() {
+	*x = Statement{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_sqliteog_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Statement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Statement) ProtoMessage() {}
+
+func (x *Statement) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sqliteog_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Statement.ProtoReflect.Descriptor instead.
+func (*Statement) Descriptor() ([]byte, []int) {
+	return file_proto_sqliteog_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Statement) GetSql() string {
+	if x != nil {
+		return x.Sql
+	}
+	return ""
+}
+
+func (x *Statement) GetParams() []*Parameter {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+func (x *Statement) GetCnxId() string {
+	if x != nil {
+		return x.CnxId
+	}
+	return ""
+}
+
+func (x *Statement) GetStmtId() string {
+	if x != nil {
+		return x.StmtId
+	}
+	return ""
+}
+
+type PreparedStatement struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id          string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	NumInput    int32    `protobuf:"varint,2,opt,name=num_input,json=numInput,proto3" json:"num_input,omitempty"`
+	Columns     []string `protobuf:"bytes,3,rep,name=columns,proto3" json:"columns,omitempty"`
+	ColumnTypes []string `protobuf:"bytes,4,rep,name=columnTypes,proto3" json:"columnTypes,omitempty"`
+}
+
+func (x *PreparedStatement) Reset() {
+	*x = PreparedStatement{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_sqliteog_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PreparedStatement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PreparedStatement) ProtoMessage() {}
+
+func (x *PreparedStatement) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sqliteog_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PreparedStatement.ProtoReflect.Descriptor instead.
+func (*PreparedStatement) Descriptor() ([]byte, []int) {
+	return file_proto_sqliteog_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *PreparedStatement) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *PreparedStatement) GetNumInput() int32 {
+	if x != nil {
+		return x.NumInput
+	}
+	return 0
+}
+
+func (x *PreparedStatement) GetColumns() []string {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
+func (x *PreparedStatement) GetColumnTypes() []string {
+	if x != nil {
+		return x.ColumnTypes
+	}
+	return nil
+}
+
+type Value struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Value:
+	//	*Value_Null
+	//	*Value_Integer
+	//	*Value_Real
+	//	*Value_Text
+	//	*Value_Blob
+	Value isValue_Value `protobuf_oneof:"value"`
+}
+
+func (x *Value) Reset() {
+	*x = Value{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_sqliteog_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Value) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Value) ProtoMessage() {}
+
+func (x *Value) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sqliteog_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Value.ProtoReflect.Descriptor instead.
+func (*Value) Descriptor() ([]byte, []int) {
+	return file_proto_sqliteog_proto_rawDescGZIP(), []int{11}
+}
+
+func (m *Value) GetValue() isValue_Value {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (x *Value) GetNull() *Empty {
+	if x, ok := x.GetValue().(*Value_Null); ok {
+		return x.Null
+	}
+	return nil
+}
+
+func (x *Value) GetInteger() int64 {
+	if x, ok := x.GetValue().(*Value_Integer); ok {
+		return x.Integer
+	}
+	return 0
+}
+
+func (x *Value) GetReal() float64 {
+	if x, ok := x.GetValue().(*Value_Real); ok {
+		return x.Real
+	}
+	return 0
+}
+
+func (x *Value) GetText() string {
+	if x, ok := x.GetValue().(*Value_Text); ok {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *Value) GetBlob() []byte {
+	if x, ok := x.GetValue().(*Value_Blob); ok {
+		return x.Blob
+	}
+	return nil
+}
+
+type isValue_Value interface {
+	isValue_Value()
+}
+
+type Value_Null struct {
+	Null *Empty `protobuf:"bytes,1,opt,name=null,proto3,oneof"`
+}
+
+type Value_Integer struct {
+	Integer int64 `protobuf:"zigzag64,2,opt,name=integer,proto3,oneof"`
+}
+
+type Value_Real struct {
+	Real float64 `protobuf:"fixed64,3,opt,name=real,proto3,oneof"`
+}
+
+type Value_Text struct {
+	Text string `protobuf:"bytes,4,opt,name=text,proto3,oneof"`
+}
+
+type Value_Blob struct {
+	Blob []byte `protobuf:"bytes,5,opt,name=blob,proto3,oneof"`
+}
+
+func (*Value_Null) isValue_Value() {}
+
+func (*Value_Integer) isValue_Value() {}
+
+func (*Value_Real) isValue_Value() {}
+
+func (*Value_Text) isValue_Value() {}
+
+func (*Value_Blob) isValue_Value() {}
+
+type Row struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Fields []*Value `protobuf:"bytes,1,rep,name=fields,proto3" json:"fields,omitempty"`
+}
+
+func (x *Row) Reset() {
+	*x = Row{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_sqliteog_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Row) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Row) ProtoMessage() {}
+
+func (x *Row) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sqliteog_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Row.ProtoReflect.Descriptor instead.
+func (*Row) Descriptor() ([]byte, []int) {
+	return file_proto_sqliteog_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Row) GetFields() []*Value {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+type QueryResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Columns     []string `protobuf:"bytes,1,rep,name=columns,proto3" json:"columns,omitempty"`
+	ColumnTypes []string `protobuf:"bytes,2,rep,name=columnTypes,proto3" json:"columnTypes,omitempty"`
+	Rows        []*Row   `protobuf:"bytes,3,rep,name=rows,proto3" json:"rows,omitempty"`
+}
+
+func (x *QueryResult) Reset() {
 	*x = QueryResult{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_sqliteog_proto_msgTypes[8]
+		mi := &file_proto_sqliteog_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueryResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryResult) ProtoMessage() {}
+
+func (x *QueryResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sqliteog_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryResult.ProtoReflect.Descriptor instead.
+func (*QueryResult) Descriptor() ([]byte, []int) {
+	return file_proto_sqliteog_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *QueryResult) GetColumns() []string {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
+func (x *QueryResult) GetColumnTypes() []string {
+	if x != nil {
+		return x.ColumnTypes
+	}
+	return nil
+}
+
+func (x *QueryResult) GetRows() []*Row {
+	if x != nil {
+		return x.Rows
+	}
+	return nil
+}
+
+type ExecuteResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	LastInsertId int64 `protobuf:"varint,1,opt,name=lastInsertId,proto3" json:"lastInsertId,omitempty"`
+	AffectedRows int64 `protobuf:"varint,2,opt,name=affectedRows,proto3" json:"affectedRows,omitempty"`
+}
+
+func (x *ExecuteResult) Reset() {
+	*x = ExecuteResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_sqliteog_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExecuteResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteResult) ProtoMessage() {}
+
+func (x *ExecuteResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sqliteog_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteResult.ProtoReflect.Descriptor instead.
+func (*ExecuteResult) Descriptor() ([]byte, []int) {
+	return file_proto_sqliteog_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ExecuteResult) GetLastInsertId() int64 {
+	if x != nil {
+		return x.LastInsertId
+	}
+	return 0
+}
+
+func (x *ExecuteResult) GetAffectedRows() int64 {
+	if x != nil {
+		return x.AffectedRows
+	}
+	return 0
+}
+
+type Parameter struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Value:
+	//	*Parameter_I
+	//	*Parameter_D
+	//	*Parameter_B
+	//	*Parameter_Y
+	//	*Parameter_S
+	//	*Parameter_Null
+	Value isParameter_Value `protobuf_oneof:"value"`
+	Name  string            `protobuf:"bytes,6,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (x *Parameter) Reset() {
+	*x = Parameter{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_sqliteog_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Parameter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Parameter) ProtoMessage() {}
+
+func (x *Parameter) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sqliteog_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Parameter.ProtoReflect.Descriptor instead.
+func (*Parameter) Descriptor() ([]byte, []int) {
+	return file_proto_sqliteog_proto_rawDescGZIP(), []int{15}
+}
+
+func (m *Parameter) GetValue() isParameter_Value {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (x *Parameter) GetI() int64 {
+	if x, ok := x.GetValue().(*Parameter_I); ok {
+		return x.I
+	}
+	return 0
+}
+
+func (x *Parameter) GetD() float64 {
+	if x, ok := x.GetValue().(*Parameter_D); ok {
+		return x.D
+	}
+	return 0
+}
+
+func (x *Parameter) GetB() bool {
+	if x, ok := x.GetValue().(*Parameter_B); ok {
+		return x.B
+	}
+	return false
+}
+
+func (x *Parameter) GetY() []byte {
+	if x, ok := x.GetValue().(*Parameter_Y); ok {
+		return x.Y
+	}
+	return nil
+}
+
+func (x *Parameter) GetS() string {
+	if x, ok := x.GetValue().(*Parameter_S); ok {
+		return x.S
+	}
+	return ""
+}
+
+func (x *Parameter) GetNull() *Empty {
+	if x, ok := x.GetValue().(*Parameter_Null); ok {
+		return x.Null
+	}
+	return nil
+}
+
+func (x *Parameter) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type isParameter_Value interface {
+	isParameter_Value()
+}
+
+type Parameter_I struct {
+	I int64 `protobuf:"zigzag64,1,opt,name=i,proto3,oneof"`
+}
+
+type Parameter_D struct {
+	D float64 `protobuf:"fixed64,2,opt,name=d,proto3,oneof"`
+}
+
+type Parameter_B struct {
+	B bool `protobuf:"varint,3,opt,name=b,proto3,oneof"`
+}
+
+type Parameter_Y struct {
+	Y []byte `protobuf:"bytes,4,opt,name=y,proto3,oneof"`
+}
+
+type Parameter_S struct {
+	S string `protobuf:"bytes,5,opt,name=s,proto3,oneof"`
+}
+
+type Parameter_Null struct {
+	Null *Empty `protobuf:"bytes,7,opt,name=null,proto3,oneof"`
+}
+
+func (*Parameter_I) isParameter_Value() {}
+
+func (*Parameter_D) isParameter_Value() {}
+
+func (*Parameter_B) isParameter_Value() {}
+
+func (*Parameter_Y) isParameter_Value() {}
+
+func (*Parameter_S) isParameter_Value() {}
+
+func (*Parameter_Null) isParameter_Value() {}
+
+type WatchRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DbName string `protobuf:"bytes,1,opt,name=db_name,json=dbName,proto3" json:"db_name,omitempty"`
+	// tables to watch, every table when empty
+	Tables []string `protobuf:"bytes,2,rep,name=tables,proto3" json:"tables,omitempty"`
+	// send the values of inserted & updated rows
+	IncludeValues bool `protobuf:"varint,3,opt,name=include_values,json=includeValues,proto3" json:"include_values,omitempty"`
+}
+
+func (x *WatchRequest) Reset() {
+	*x = WatchRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_sqliteog_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRequest) ProtoMessage() {}
+
+func (x *WatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sqliteog_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRequest.ProtoReflect.Descriptor instead.
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return file_proto_sqliteog_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *WatchRequest) GetDbName() string {
+	if x != nil {
+		return x.DbName
+	}
+	return ""
+}
+
+func (x *WatchRequest) GetTables() []string {
+	if x != nil {
+		return x.Tables
+	}
+	return nil
+}
+
+func (x *WatchRequest) GetIncludeValues() bool {
+	if x != nil {
+		return x.IncludeValues
+	}
+	return false
+}
+
+// ChangeEvent is a committed change of a row
+type ChangeEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Table     string          `protobuf:"bytes,1,opt,name=table,proto3" json:"table,omitempty"`
+	Operation ChangeOperation `protobuf:"varint,2,opt,name=operation,proto3,enum=ChangeOperation" json:"operation,omitempty"`
+	Rowid     int64           `protobuf:"varint,3,opt,name=rowid,proto3" json:"rowid,omitempty"`
+	// the row as read right after the commit, when values are requested,
+	// empty if the row no longer exists
+	Columns []string `protobuf:"bytes,4,rep,name=columns,proto3" json:"columns,omitempty"`
+	Values  *Row     `protobuf:"bytes,5,opt,name=values,proto3" json:"values,omitempty"`
+	// resume token of the change, only set by Changes
+	Token uint64 `protobuf:"varint,6,opt,name=token,proto3" json:"token,omitempty"`
+}
+
+func (x *ChangeEvent) Reset() {
+	*x = ChangeEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_sqliteog_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChangeEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangeEvent) ProtoMessage() {}
+
+func (x *ChangeEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sqliteog_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangeEvent.ProtoReflect.Descriptor instead.
+func (*ChangeEvent) Descriptor() ([]byte, []int) {
+	return file_proto_sqliteog_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ChangeEvent) GetTable() string {
+	if x != nil {
+		return x.Table
+	}
+	return ""
+}
+
+func (x *ChangeEvent) GetOperation() ChangeOperation {
+	if x != nil {
+		return x.Operation
+	}
+	return ChangeOperation_INSERT
+}
+
+func (x *ChangeEvent) GetRowid() int64 {
+	if x != nil {
+		return x.Rowid
+	}
+	return 0
+}
+
+func (x *ChangeEvent) GetColumns() []string {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
+func (x *ChangeEvent) GetValues() *Row {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+func (x *ChangeEvent) GetToken() uint64 {
+	if x != nil {
+		return x.Token
+	}
+	return 0
+}
+
+type ChangelogRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DbName string `protobuf:"bytes,1,opt,name=db_name,json=dbName,proto3" json:"db_name,omitempty"`
+	// tables whose changes are logged, every table when empty
+	Tables []string `protobuf:"bytes,2,rep,name=tables,proto3" json:"tables,omitempty"`
+}
+
+func (x *ChangelogRequest) Reset() {
+	*x = ChangelogRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_sqliteog_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChangelogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangelogRequest) ProtoMessage() {}
+
+func (x *ChangelogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sqliteog_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangelogRequest.ProtoReflect.Descriptor instead.
+func (*ChangelogRequest) Descriptor() ([]byte, []int) {
+	return file_proto_sqliteog_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ChangelogRequest) GetDbName() string {
+	if x != nil {
+		return x.DbName
+	}
+	return ""
+}
+
+func (x *ChangelogRequest) GetTables() []string {
+	if x != nil {
+		return x.Tables
+	}
+	return nil
+}
+
+type ChangesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DbName string `protobuf:"bytes,1,opt,name=db_name,json=dbName,proto3" json:"db_name,omitempty"`
+	// resume token, the changes logged after it are sent, 0 sends every
+	// retained change
+	After uint64 `protobuf:"varint,2,opt,name=after,proto3" json:"after,omitempty"`
+	// tables to follow, every logged table when empty
+	Tables []string `protobuf:"bytes,3,rep,name=tables,proto3" json:"tables,omitempty"`
+}
+
+func (x *ChangesRequest) Reset() {
+	*x = ChangesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_sqliteog_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChangesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangesRequest) ProtoMessage() {}
+
+func (x *ChangesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sqliteog_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangesRequest.ProtoReflect.Descriptor instead.
+func (*ChangesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_sqliteog_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ChangesRequest) GetDbName() string {
+	if x != nil {
+		return x.DbName
+	}
+	return ""
+}
+
+func (x *ChangesRequest) GetAfter() uint64 {
+	if x != nil {
+		return x.After
+	}
+	return 0
+}
+
+func (x *ChangesRequest) GetTables() []string {
+	if x != nil {
+		return x.Tables
+	}
+	return nil
+}
+
+type DatabaseInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// name of the database relative to the data directory
+	DbName string `protobuf:"bytes,1,opt,name=db_name,json=dbName,proto3" json:"db_name,omitempty"`
+	// size in bytes of the database file, without its journal
+	Size        int64  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	PageCount   int64  `protobuf:"varint,3,opt,name=page_count,json=pageCount,proto3" json:"page_count,omitempty"`
+	PageSize    int64  `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	JournalMode string `protobuf:"bytes,5,opt,name=journal_mode,json=journalMode,proto3" json:"journal_mode,omitempty"`
+	// number of sessions open on the database
+	Sessions int32 `protobuf:"varint,6,opt,name=sessions,proto3" json:"sessions,omitempty"`
+}
+
+func (x *DatabaseInfo) Reset() {
+	*x = DatabaseInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_sqliteog_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DatabaseInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DatabaseInfo) ProtoMessage() {}
+
+func (x *DatabaseInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sqliteog_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DatabaseInfo.ProtoReflect.Descriptor instead.
+func (*DatabaseInfo) Descriptor() ([]byte, []int) {
+	return file_proto_sqliteog_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *DatabaseInfo) GetDbName() string {
+	if x != nil {
+		return x.DbName
+	}
+	return ""
+}
+
+func (x *DatabaseInfo) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *DatabaseInfo) GetPageCount() int64 {
+	if x != nil {
+		return x.PageCount
+	}
+	return 0
+}
+
+func (x *DatabaseInfo) GetPageSize() int64 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *DatabaseInfo) GetJournalMode() string {
+	if x != nil {
+		return x.JournalMode
+	}
+	return ""
+}
+
+func (x *DatabaseInfo) GetSessions() int32 {
+	if x != nil {
+		return x.Sessions
+	}
+	return 0
+}
+
+type DatabaseList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Databases []*DatabaseInfo `protobuf:"bytes,1,rep,name=databases,proto3" json:"databases,omitempty"`
+}
+
+func (x *DatabaseList) Reset() {
+	*x = DatabaseList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_sqliteog_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DatabaseList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DatabaseList) ProtoMessage() {}
+
+func (x *DatabaseList) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sqliteog_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DatabaseList.ProtoReflect.Descriptor instead.
+func (*DatabaseList) Descriptor() ([]byte, []int) {
+	return file_proto_sqliteog_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *DatabaseList) GetDatabases() []*DatabaseInfo {
+	if x != nil {
+		return x.Databases
+	}
+	return nil
+}
+
+type CreateDatabaseRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DbName string `protobuf:"bytes,1,opt,name=db_name,json=dbName,proto3" json:"db_name,omitempty"`
+	// database copied to create the new one, it's empty when unset
+	Template string `protobuf:"bytes,2,opt,name=template,proto3" json:"template,omitempty"`
+}
+
+func (x *CreateDatabaseRequest) Reset() {
+	*x = CreateDatabaseRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_sqliteog_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreateDatabaseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateDatabaseRequest) ProtoMessage() {}
+
+func (x *CreateDatabaseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sqliteog_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateDatabaseRequest.ProtoReflect.Descriptor instead.
+func (*CreateDatabaseRequest) Descriptor() ([]byte, []int) {
+	return file_proto_sqliteog_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *CreateDatabaseRequest) GetDbName() string {
+	if x != nil {
+		return x.DbName
+	}
+	return ""
+}
+
+func (x *CreateDatabaseRequest) GetTemplate() string {
+	if x != nil {
+		return x.Template
+	}
+	return ""
+}
+
+type DropDatabaseRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DbName string `protobuf:"bytes,1,opt,name=db_name,json=dbName,proto3" json:"db_name,omitempty"`
+	// closes the sessions open on the database, it's refused otherwise
+	Force bool `protobuf:"varint,2,opt,name=force,proto3" json:"force,omitempty"`
+}
+
+func (x *DropDatabaseRequest) Reset() {
+	*x = DropDatabaseRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_sqliteog_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DropDatabaseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DropDatabaseRequest) ProtoMessage() {}
+
+func (x *DropDatabaseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sqliteog_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DropDatabaseRequest.ProtoReflect.Descriptor instead.
+func (*DropDatabaseRequest) Descriptor() ([]byte, []int) {
+	return file_proto_sqliteog_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *DropDatabaseRequest) GetDbName() string {
+	if x != nil {
+		return x.DbName
+	}
+	return ""
+}
+
+func (x *DropDatabaseRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+type RenameDatabaseRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DbName    string `protobuf:"bytes,1,opt,name=db_name,json=dbName,proto3" json:"db_name,omitempty"`
+	NewDbName string `protobuf:"bytes,2,opt,name=new_db_name,json=newDbName,proto3" json:"new_db_name,omitempty"`
+}
+
+func (x *RenameDatabaseRequest) Reset() {
+	*x = RenameDatabaseRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_sqliteog_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RenameDatabaseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenameDatabaseRequest) ProtoMessage() {}
+
+func (x *RenameDatabaseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sqliteog_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenameDatabaseRequest.ProtoReflect.Descriptor instead.
+func (*RenameDatabaseRequest) Descriptor() ([]byte, []int) {
+	return file_proto_sqliteog_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *RenameDatabaseRequest) GetDbName() string {
+	if x != nil {
+		return x.DbName
+	}
+	return ""
+}
+
+func (x *RenameDatabaseRequest) GetNewDbName() string {
+	if x != nil {
+		return x.NewDbName
+	}
+	return ""
+}
+
+type BackupRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DbName string `protobuf:"bytes,1,opt,name=db_name,json=dbName,proto3" json:"db_name,omitempty"`
+}
+
+func (x *BackupRequest) Reset() {
+	*x = BackupRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_sqliteog_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BackupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackupRequest) ProtoMessage() {}
+
+func (x *BackupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sqliteog_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackupRequest.ProtoReflect.Descriptor instead.
+func (*BackupRequest) Descriptor() ([]byte, []int) {
+	return file_proto_sqliteog_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *BackupRequest) GetDbName() string {
+	if x != nil {
+		return x.DbName
+	}
+	return ""
+}
+
+// FileChunk is a part of a database file, the last chunk of the file
+// carries its size & checksum
+type FileChunk struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	// hex encoded SHA-256 of the whole file, set on the last chunk
+	Sha256 string `protobuf:"bytes,2,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	// size of the whole file, set on the last chunk
+	Size int64 `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+}
+
+func (x *FileChunk) Reset() {
+	*x = FileChunk{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_sqliteog_proto_msgTypes[26]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *QueryResult) String() string {
+func (x *FileChunk) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*QueryResult) ProtoMessage() {}
+func (*FileChunk) ProtoMessage() {}
 
-func (x *QueryResult) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sqliteog_proto_msgTypes[8]
+func (x *FileChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sqliteog_proto_msgTypes[26]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -480,58 +2084,63 @@ func (x *QueryResult) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use QueryResult.ProtoReflect.Descriptor instead.
-func (*QueryResult) Descriptor() ([]byte, []int) {
-	return file_proto_sqliteog_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use FileChunk.ProtoReflect.Descriptor instead.
+func (*FileChunk) Descriptor() ([]byte, []int) {
+	return file_proto_sqliteog_proto_rawDescGZIP(), []int{26}
 }
 
-func (x *QueryResult) GetColumns() []string {
+func (x *FileChunk) GetData() []byte {
 	if x != nil {
-		return x.Columns
+		return x.Data
 	}
 	return nil
 }
 
-func (x *QueryResult) GetColumnTypes() []string {
+func (x *FileChunk) GetSha256() string {
 	if x != nil {
-		return x.ColumnTypes
+		return x.Sha256
 	}
-	return nil
+	return ""
 }
 
-func (x *QueryResult) GetRows() []*Row {
+func (x *FileChunk) GetSize() int64 {
 	if x != nil {
-		return x.Rows
+		return x.Size
 	}
-	return nil
+	return 0
 }
 
-type ExecuteResult struct {
+// RestoreRequest streams the file restored, the first message names the
+// database
+type RestoreRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	LastInsertId int64 `protobuf:"varint,1,opt,name=lastInsertId,proto3" json:"lastInsertId,omitempty"`
-	AffectedRows int64 `protobuf:"varint,2,opt,name=affectedRows,proto3" json:"affectedRows,omitempty"`
+	DbName string `protobuf:"bytes,1,opt,name=db_name,json=dbName,proto3" json:"db_name,omitempty"`
+	// closes the sessions open on the database once their statements are
+	// done, the restore is refused otherwise
+	Force bool       `protobuf:"varint,2,opt,name=force,proto3" json:"force,omitempty"`
+	Chunk *FileChunk `protobuf:"bytes,3,opt,name=chunk,proto3" json:"chunk,omitempty"`
 }
 
-func (x *ExecuteResult) Reset() {
-	*x = ExecuteResult{}
+func (x *RestoreRequest) Reset() {
+	*x = RestoreRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_sqliteog_proto_msgTypes[9]
+		mi := &file_proto_sqliteog_proto_msgTypes[27]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *ExecuteResult) String() string {
+func (x *RestoreRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ExecuteResult) ProtoMessage() {}
+func (*RestoreRequest) ProtoMessage() {}
 
-func (x *ExecuteResult) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_sqliteog_proto_msgTypes[9]
+func (x *RestoreRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sqliteog_proto_msgTypes[27]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -542,23 +2151,30 @@ func (x *ExecuteResult) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ExecuteResult.ProtoReflect.Descriptor instead.
-func (*ExecuteResult) Descriptor() ([]byte, []int) {
-	return file_proto_sqliteog_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use RestoreRequest.ProtoReflect.Descriptor instead.
+func (*RestoreRequest) Descriptor() ([]byte, []int) {
+	return file_proto_sqliteog_proto_rawDescGZIP(), []int{27}
 }
 
-func (x *ExecuteResult) GetLastInsertId() int64 {
+func (x *RestoreRequest) GetDbName() string {
 	if x != nil {
-		return x.LastInsertId
+		return x.DbName
 	}
-	return 0
+	return ""
 }
 
-func (x *ExecuteResult) GetAffectedRows() int64 {
+func (x *RestoreRequest) GetForce() bool {
 	if x != nil {
-		return x.AffectedRows
+		return x.Force
 	}
-	return 0
+	return false
+}
+
+func (x *RestoreRequest) GetChunk() *FileChunk {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
 }
 
 var File_proto_sqliteog_proto protoreflect.FileDescriptor
@@ -568,75 +2184,296 @@ var file_proto_sqliteog_proto_rawDesc = []byte{
 	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x07, 0x0a, 0x05, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22,
 	0x1e, 0x0a, 0x0c, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12,
 	0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22,
-	0x6c, 0x0a, 0x11, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71,
+	0xa0, 0x03, 0x0a, 0x11, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x64, 0x62, 0x5f, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x62, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x20,
+	0x0a, 0x0b, 0x61, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x6f, 0x72, 0x73, 0x18, 0x03, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x0b, 0x61, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x6f, 0x72, 0x73,
+	0x12, 0x27, 0x0a, 0x09, 0x66, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x04, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x46, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x09,
+	0x66, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x6f, 0x6c,
+	0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x63,
+	0x6f, 0x6c, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x2d, 0x0a, 0x12, 0x62, 0x75, 0x69,
+	0x6c, 0x74, 0x69, 0x6e, 0x5f, 0x63, 0x6f, 0x6c, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18,
+	0x06, 0x20, 0x03, 0x28, 0x09, 0x52, 0x11, 0x62, 0x75, 0x69, 0x6c, 0x74, 0x69, 0x6e, 0x43, 0x6f,
+	0x6c, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1f, 0x0a, 0x06, 0x61, 0x63, 0x63, 0x65,
+	0x73, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x07, 0x2e, 0x41, 0x63, 0x63, 0x65, 0x73,
+	0x73, 0x52, 0x06, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x39, 0x0a, 0x07, 0x6f, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x43, 0x6f, 0x6e,
+	0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x4f,
+	0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x6f, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x12, 0x3a, 0x0a, 0x13, 0x61, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74,
+	0x65, 0x5f, 0x66, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x09, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x09, 0x2e, 0x46, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x12, 0x61, 0x67,
+	0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x65, 0x46, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x1a, 0x3a, 0x0a, 0x0c, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b,
+	0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x4a, 0x04, 0x08, 0x02,
+	0x10, 0x03, 0x22, 0xc5, 0x01, 0x0a, 0x06, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x1f, 0x0a,
+	0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0b, 0x2e, 0x41, 0x63,
+	0x63, 0x65, 0x73, 0x73, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x12, 0x25,
+	0x0a, 0x0e, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x5f, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x73,
+	0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0d, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x54,
+	0x61, 0x62, 0x6c, 0x65, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x64, 0x65, 0x6e, 0x69, 0x65, 0x64, 0x5f,
+	0x74, 0x61, 0x62, 0x6c, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x64, 0x65,
+	0x6e, 0x69, 0x65, 0x64, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x61, 0x6c,
+	0x6c, 0x6f, 0x77, 0x65, 0x64, 0x5f, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x18, 0x04, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x0e, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x43, 0x6f, 0x6c, 0x75,
+	0x6d, 0x6e, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x64, 0x65, 0x6e, 0x69, 0x65, 0x64, 0x5f, 0x63, 0x6f,
+	0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0d, 0x64, 0x65, 0x6e,
+	0x69, 0x65, 0x64, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x22, 0x5f, 0x0a, 0x08, 0x46, 0x75,
+	0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x6e, 0x75,
+	0x6d, 0x5f, 0x61, 0x72, 0x67, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x6e, 0x75,
+	0x6d, 0x41, 0x72, 0x67, 0x73, 0x12, 0x24, 0x0a, 0x0d, 0x64, 0x65, 0x74, 0x65, 0x72, 0x6d, 0x69,
+	0x6e, 0x69, 0x73, 0x74, 0x69, 0x63, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x64, 0x65,
+	0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x69, 0x73, 0x74, 0x69, 0x63, 0x22, 0x5f, 0x0a, 0x0c, 0x42,
+	0x65, 0x67, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x63,
+	0x6e, 0x78, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x63, 0x6e, 0x78,
+	0x49, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x72, 0x65, 0x61, 0x64, 0x5f, 0x6f, 0x6e, 0x6c, 0x79, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x72, 0x65, 0x61, 0x64, 0x4f, 0x6e, 0x6c, 0x79, 0x12,
+	0x1b, 0x0a, 0x04, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x07, 0x2e,
+	0x54, 0x78, 0x4c, 0x6f, 0x63, 0x6b, 0x52, 0x04, 0x6c, 0x6f, 0x63, 0x6b, 0x22, 0x78, 0x0a, 0x10,
+	0x49, 0x6e, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x12, 0x18, 0x0a, 0x07, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x07, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c, 0x12, 0x1e, 0x0a, 0x06, 0x72, 0x65,
+	0x73, 0x75, 0x6c, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x06, 0x2e, 0x56, 0x61, 0x6c,
+	0x75, 0x65, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x69, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72,
+	0x72, 0x6f, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72,
+	0x4a, 0x04, 0x08, 0x02, 0x10, 0x03, 0x22, 0x9a, 0x01, 0x0a, 0x06, 0x49, 0x6e, 0x76, 0x6f, 0x6b,
+	0x65, 0x12, 0x22, 0x0a, 0x0c, 0x66, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x4e, 0x61, 0x6d,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x66, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x04, 0x61, 0x72, 0x67, 0x73, 0x18, 0x06, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x06, 0x2e, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x04, 0x61, 0x72, 0x67,
+	0x73, 0x12, 0x1f, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x0b, 0x2e, 0x49, 0x6e, 0x76, 0x6f, 0x6b, 0x65, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79,
+	0x70, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x73, 0x74, 0x61, 0x74, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x73, 0x74, 0x61, 0x74, 0x65, 0x49, 0x64, 0x12, 0x0e, 0x0a,
+	0x02, 0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x69, 0x64, 0x4a, 0x04, 0x08,
+	0x02, 0x10, 0x03, 0x22, 0x7e, 0x0a, 0x14, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65, 0x4f, 0x72,
+	0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x2f, 0x0a, 0x0c, 0x71,
+	0x75, 0x65, 0x72, 0x79, 0x5f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0c, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52,
+	0x0b, 0x71, 0x75, 0x65, 0x72, 0x79, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x35, 0x0a, 0x0e,
+	0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65, 0x5f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65, 0x52, 0x65,
+	0x73, 0x75, 0x6c, 0x74, 0x52, 0x0d, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65, 0x52, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x22, 0x71, 0x0a, 0x09, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74,
+	0x12, 0x10, 0x0a, 0x03, 0x73, 0x71, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73,
+	0x71, 0x6c, 0x12, 0x22, 0x0a, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x52, 0x06,
+	0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x15, 0x0a, 0x06, 0x63, 0x6e, 0x78, 0x5f, 0x69, 0x64,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x63, 0x6e, 0x78, 0x49, 0x64, 0x12, 0x17, 0x0a,
+	0x07, 0x73, 0x74, 0x6d, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x73, 0x74, 0x6d, 0x74, 0x49, 0x64, 0x22, 0x7c, 0x0a, 0x11, 0x50, 0x72, 0x65, 0x70, 0x61, 0x72,
+	0x65, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x6e,
+	0x75, 0x6d, 0x5f, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08,
+	0x6e, 0x75, 0x6d, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6c, 0x75,
+	0x6d, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6c, 0x75, 0x6d,
+	0x6e, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x54, 0x79, 0x70, 0x65,
+	0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x54,
+	0x79, 0x70, 0x65, 0x73, 0x22, 0x8c, 0x01, 0x0a, 0x05, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x1c,
+	0x0a, 0x04, 0x6e, 0x75, 0x6c, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x06, 0x2e, 0x45,
+	0x6d, 0x70, 0x74, 0x79, 0x48, 0x00, 0x52, 0x04, 0x6e, 0x75, 0x6c, 0x6c, 0x12, 0x1a, 0x0a, 0x07,
+	0x69, 0x6e, 0x74, 0x65, 0x67, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x12, 0x48, 0x00, 0x52,
+	0x07, 0x69, 0x6e, 0x74, 0x65, 0x67, 0x65, 0x72, 0x12, 0x14, 0x0a, 0x04, 0x72, 0x65, 0x61, 0x6c,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x48, 0x00, 0x52, 0x04, 0x72, 0x65, 0x61, 0x6c, 0x12, 0x14,
+	0x0a, 0x04, 0x74, 0x65, 0x78, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04,
+	0x74, 0x65, 0x78, 0x74, 0x12, 0x14, 0x0a, 0x04, 0x62, 0x6c, 0x6f, 0x62, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x0c, 0x48, 0x00, 0x52, 0x04, 0x62, 0x6c, 0x6f, 0x62, 0x42, 0x07, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x22, 0x25, 0x0a, 0x03, 0x52, 0x6f, 0x77, 0x12, 0x1e, 0x0a, 0x06, 0x66, 0x69,
+	0x65, 0x6c, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x06, 0x2e, 0x56, 0x61, 0x6c,
+	0x75, 0x65, 0x52, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x73, 0x22, 0x63, 0x0a, 0x0b, 0x51, 0x75,
+	0x65, 0x72, 0x79, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6c,
+	0x75, 0x6d, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6c, 0x75,
+	0x6d, 0x6e, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x54, 0x79, 0x70,
+	0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e,
+	0x54, 0x79, 0x70, 0x65, 0x73, 0x12, 0x18, 0x0a, 0x04, 0x72, 0x6f, 0x77, 0x73, 0x18, 0x03, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x04, 0x2e, 0x52, 0x6f, 0x77, 0x52, 0x04, 0x72, 0x6f, 0x77, 0x73, 0x22,
+	0x57, 0x0a, 0x0d, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x12, 0x22, 0x0a, 0x0c, 0x6c, 0x61, 0x73, 0x74, 0x49, 0x6e, 0x73, 0x65, 0x72, 0x74, 0x49, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x6c, 0x61, 0x73, 0x74, 0x49, 0x6e, 0x73, 0x65,
+	0x72, 0x74, 0x49, 0x64, 0x12, 0x22, 0x0a, 0x0c, 0x61, 0x66, 0x66, 0x65, 0x63, 0x74, 0x65, 0x64,
+	0x52, 0x6f, 0x77, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x61, 0x66, 0x66, 0x65,
+	0x63, 0x74, 0x65, 0x64, 0x52, 0x6f, 0x77, 0x73, 0x22, 0x96, 0x01, 0x0a, 0x09, 0x50, 0x61, 0x72,
+	0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x12, 0x0e, 0x0a, 0x01, 0x69, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x12, 0x48, 0x00, 0x52, 0x01, 0x69, 0x12, 0x0e, 0x0a, 0x01, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x01, 0x48, 0x00, 0x52, 0x01, 0x64, 0x12, 0x0e, 0x0a, 0x01, 0x62, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x08, 0x48, 0x00, 0x52, 0x01, 0x62, 0x12, 0x0e, 0x0a, 0x01, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0c, 0x48, 0x00, 0x52, 0x01, 0x79, 0x12, 0x0e, 0x0a, 0x01, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x09, 0x48, 0x00, 0x52, 0x01, 0x73, 0x12, 0x1c, 0x0a, 0x04, 0x6e, 0x75, 0x6c, 0x6c, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x06, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x48, 0x00, 0x52, 0x04,
+	0x6e, 0x75, 0x6c, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x42, 0x07, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x22, 0x66, 0x0a, 0x0c, 0x57, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x17, 0x0a, 0x07, 0x64, 0x62, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x64, 0x62, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x61,
+	0x62, 0x6c, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x74, 0x61, 0x62, 0x6c,
+	0x65, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x5f, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x69, 0x6e, 0x63, 0x6c,
+	0x75, 0x64, 0x65, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x22, 0xb7, 0x01, 0x0a, 0x0b, 0x43, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x61, 0x62,
+	0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x12,
+	0x2e, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x10, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x4f, 0x70, 0x65, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x52, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x14, 0x0a, 0x05, 0x72, 0x6f, 0x77, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05,
+	0x72, 0x6f, 0x77, 0x69, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73,
+	0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x12,
+	0x1c, 0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x04, 0x2e, 0x52, 0x6f, 0x77, 0x52, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x12, 0x14, 0x0a,
+	0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x74, 0x6f,
+	0x6b, 0x65, 0x6e, 0x22, 0x43, 0x0a, 0x10, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x6c, 0x6f, 0x67,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x64, 0x62, 0x5f, 0x6e, 0x61,
+	0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x62, 0x4e, 0x61, 0x6d, 0x65,
+	0x12, 0x16, 0x0a, 0x06, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x06, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x73, 0x22, 0x57, 0x0a, 0x0e, 0x43, 0x68, 0x61, 0x6e,
+	0x67, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x64, 0x62,
+	0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x62, 0x4e,
+	0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x66, 0x74, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x05, 0x61, 0x66, 0x74, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x61, 0x62,
+	0x6c, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x74, 0x61, 0x62, 0x6c, 0x65,
+	0x73, 0x22, 0xb6, 0x01, 0x0a, 0x0c, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x49, 0x6e,
+	0x66, 0x6f, 0x12, 0x17, 0x0a, 0x07, 0x64, 0x62, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x62, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x73,
+	0x69, 0x7a, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12,
+	0x1d, 0x0a, 0x0a, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x09, 0x70, 0x61, 0x67, 0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1b,
+	0x0a, 0x09, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x08, 0x70, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x6a,
+	0x6f, 0x75, 0x72, 0x6e, 0x61, 0x6c, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x6a, 0x6f, 0x75, 0x72, 0x6e, 0x61, 0x6c, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x1a,
+	0x0a, 0x08, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x08, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x3b, 0x0a, 0x0c, 0x44, 0x61,
+	0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x2b, 0x0a, 0x09, 0x64, 0x61,
+	0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e,
+	0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x09, 0x64, 0x61,
+	0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x73, 0x22, 0x4c, 0x0a, 0x15, 0x43, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x17, 0x0a, 0x07, 0x64, 0x62, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x64, 0x62, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x74, 0x65, 0x6d,
+	0x70, 0x6c, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x65, 0x6d,
+	0x70, 0x6c, 0x61, 0x74, 0x65, 0x22, 0x44, 0x0a, 0x13, 0x44, 0x72, 0x6f, 0x70, 0x44, 0x61, 0x74,
+	0x61, 0x62, 0x61, 0x73, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07,
+	0x64, 0x62, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64,
+	0x62, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x22, 0x50, 0x0a, 0x15, 0x52,
+	0x65, 0x6e, 0x61, 0x6d, 0x65, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x52, 0x65, 0x71,
 	0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x64, 0x62, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x62, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a,
-	0x09, 0x66, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09,
-	0x52, 0x09, 0x66, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x61,
-	0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x6f, 0x72, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09,
-	0x52, 0x0b, 0x61, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x6f, 0x72, 0x73, 0x22, 0x44, 0x0a,
-	0x10, 0x49, 0x6e, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x75, 0x6c,
-	0x74, 0x12, 0x18, 0x0a, 0x07, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x08, 0x52, 0x07, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x72,
-	0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x73,
-	0x75, 0x6c, 0x74, 0x22, 0x40, 0x0a, 0x06, 0x49, 0x6e, 0x76, 0x6f, 0x6b, 0x65, 0x12, 0x22, 0x0a,
-	0x0c, 0x66, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x0c, 0x66, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x4e, 0x61, 0x6d,
-	0x65, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x72, 0x67, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52,
-	0x04, 0x61, 0x72, 0x67, 0x73, 0x22, 0x7e, 0x0a, 0x14, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65,
-	0x4f, 0x72, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x2f, 0x0a,
-	0x0c, 0x71, 0x75, 0x65, 0x72, 0x79, 0x5f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x65, 0x73, 0x75, 0x6c,
-	0x74, 0x52, 0x0b, 0x71, 0x75, 0x65, 0x72, 0x79, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x35,
-	0x0a, 0x0e, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65, 0x5f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65,
-	0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x0d, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65, 0x52,
-	0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x4c, 0x0a, 0x09, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65,
-	0x6e, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x71, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x03, 0x73, 0x71, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x02,
-	0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x15, 0x0a, 0x06,
-	0x63, 0x6e, 0x78, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x63, 0x6e,
-	0x78, 0x49, 0x64, 0x22, 0x1d, 0x0a, 0x03, 0x52, 0x6f, 0x77, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x69,
-	0x65, 0x6c, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x66, 0x69, 0x65, 0x6c,
-	0x64, 0x73, 0x22, 0x63, 0x0a, 0x0b, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x65, 0x73, 0x75, 0x6c,
-	0x74, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03,
-	0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x63,
-	0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09,
-	0x52, 0x0b, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x73, 0x12, 0x18, 0x0a,
-	0x04, 0x72, 0x6f, 0x77, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x04, 0x2e, 0x52, 0x6f,
-	0x77, 0x52, 0x04, 0x72, 0x6f, 0x77, 0x73, 0x22, 0x57, 0x0a, 0x0d, 0x45, 0x78, 0x65, 0x63, 0x75,
-	0x74, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x22, 0x0a, 0x0c, 0x6c, 0x61, 0x73, 0x74,
-	0x49, 0x6e, 0x73, 0x65, 0x72, 0x74, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c,
-	0x6c, 0x61, 0x73, 0x74, 0x49, 0x6e, 0x73, 0x65, 0x72, 0x74, 0x49, 0x64, 0x12, 0x22, 0x0a, 0x0c,
-	0x61, 0x66, 0x66, 0x65, 0x63, 0x74, 0x65, 0x64, 0x52, 0x6f, 0x77, 0x73, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x03, 0x52, 0x0c, 0x61, 0x66, 0x66, 0x65, 0x63, 0x74, 0x65, 0x64, 0x52, 0x6f, 0x77, 0x73,
-	0x32, 0x80, 0x03, 0x0a, 0x08, 0x53, 0x71, 0x6c, 0x69, 0x74, 0x65, 0x4f, 0x47, 0x12, 0x23, 0x0a,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x62, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1e, 0x0a,
+	0x0b, 0x6e, 0x65, 0x77, 0x5f, 0x64, 0x62, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x6e, 0x65, 0x77, 0x44, 0x62, 0x4e, 0x61, 0x6d, 0x65, 0x22, 0x28, 0x0a,
+	0x0d, 0x42, 0x61, 0x63, 0x6b, 0x75, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17,
+	0x0a, 0x07, 0x64, 0x62, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x64, 0x62, 0x4e, 0x61, 0x6d, 0x65, 0x22, 0x4b, 0x0a, 0x09, 0x46, 0x69, 0x6c, 0x65, 0x43,
+	0x68, 0x75, 0x6e, 0x6b, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x68, 0x61, 0x32,
+	0x35, 0x36, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x68, 0x61, 0x32, 0x35, 0x36,
+	0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04,
+	0x73, 0x69, 0x7a, 0x65, 0x22, 0x61, 0x0a, 0x0e, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x64, 0x62, 0x5f, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x62, 0x4e, 0x61, 0x6d, 0x65, 0x12,
+	0x14, 0x0a, 0x05, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05,
+	0x66, 0x6f, 0x72, 0x63, 0x65, 0x12, 0x20, 0x0a, 0x05, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x43, 0x68, 0x75, 0x6e, 0x6b,
+	0x52, 0x05, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x2a, 0x37, 0x0a, 0x0a, 0x41, 0x63, 0x63, 0x65, 0x73,
+	0x73, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x0e, 0x0a, 0x0a, 0x52, 0x45, 0x41, 0x44, 0x5f, 0x57, 0x52,
+	0x49, 0x54, 0x45, 0x10, 0x00, 0x12, 0x0d, 0x0a, 0x09, 0x52, 0x45, 0x41, 0x44, 0x5f, 0x4f, 0x4e,
+	0x4c, 0x59, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x4e, 0x4f, 0x5f, 0x44, 0x44, 0x4c, 0x10, 0x02,
+	0x2a, 0x34, 0x0a, 0x06, 0x54, 0x78, 0x4c, 0x6f, 0x63, 0x6b, 0x12, 0x0c, 0x0a, 0x08, 0x44, 0x45,
+	0x46, 0x45, 0x52, 0x52, 0x45, 0x44, 0x10, 0x00, 0x12, 0x0d, 0x0a, 0x09, 0x49, 0x4d, 0x4d, 0x45,
+	0x44, 0x49, 0x41, 0x54, 0x45, 0x10, 0x01, 0x12, 0x0d, 0x0a, 0x09, 0x45, 0x58, 0x43, 0x4c, 0x55,
+	0x53, 0x49, 0x56, 0x45, 0x10, 0x02, 0x2a, 0x51, 0x0a, 0x0a, 0x49, 0x6e, 0x76, 0x6f, 0x6b, 0x65,
+	0x54, 0x79, 0x70, 0x65, 0x12, 0x0c, 0x0a, 0x08, 0x46, 0x55, 0x4e, 0x43, 0x54, 0x49, 0x4f, 0x4e,
+	0x10, 0x00, 0x12, 0x12, 0x0a, 0x0e, 0x41, 0x47, 0x47, 0x52, 0x45, 0x47, 0x41, 0x54, 0x45, 0x5f,
+	0x53, 0x54, 0x45, 0x50, 0x10, 0x01, 0x12, 0x12, 0x0a, 0x0e, 0x41, 0x47, 0x47, 0x52, 0x45, 0x47,
+	0x41, 0x54, 0x45, 0x5f, 0x44, 0x4f, 0x4e, 0x45, 0x10, 0x02, 0x12, 0x0d, 0x0a, 0x09, 0x43, 0x4f,
+	0x4c, 0x4c, 0x41, 0x54, 0x49, 0x4f, 0x4e, 0x10, 0x03, 0x2a, 0x35, 0x0a, 0x0f, 0x43, 0x68, 0x61,
+	0x6e, 0x67, 0x65, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x0a, 0x0a, 0x06,
+	0x49, 0x4e, 0x53, 0x45, 0x52, 0x54, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x55, 0x50, 0x44, 0x41,
+	0x54, 0x45, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x44, 0x45, 0x4c, 0x45, 0x54, 0x45, 0x10, 0x02,
+	0x32, 0x81, 0x07, 0x0a, 0x08, 0x53, 0x71, 0x6c, 0x69, 0x74, 0x65, 0x4f, 0x47, 0x12, 0x23, 0x0a,
 	0x05, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x0a, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65,
 	0x6e, 0x74, 0x1a, 0x0c, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
-	0x22, 0x00, 0x12, 0x27, 0x0a, 0x07, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65, 0x12, 0x0a, 0x2e,
-	0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x1a, 0x0e, 0x2e, 0x45, 0x78, 0x65, 0x63,
-	0x75, 0x74, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x00, 0x12, 0x35, 0x0a, 0x0e, 0x45,
-	0x78, 0x65, 0x63, 0x75, 0x74, 0x65, 0x4f, 0x72, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x0a, 0x2e,
-	0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x1a, 0x15, 0x2e, 0x45, 0x78, 0x65, 0x63,
-	0x75, 0x74, 0x65, 0x4f, 0x72, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
-	0x22, 0x00, 0x12, 0x2c, 0x0a, 0x08, 0x43, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x12, 0x11,
-	0x2e, 0x49, 0x6e, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x75, 0x6c,
-	0x74, 0x1a, 0x07, 0x2e, 0x49, 0x6e, 0x76, 0x6f, 0x6b, 0x65, 0x22, 0x00, 0x28, 0x01, 0x30, 0x01,
-	0x12, 0x31, 0x0a, 0x0a, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12,
-	0x2e, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x1a, 0x0d, 0x2e, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x49,
-	0x64, 0x22, 0x00, 0x12, 0x20, 0x0a, 0x05, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x12, 0x0d, 0x2e, 0x43,
-	0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x1a, 0x06, 0x2e, 0x45, 0x6d,
-	0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x22, 0x0a, 0x07, 0x49, 0x73, 0x56, 0x61, 0x6c, 0x69, 0x64,
+	0x22, 0x00, 0x12, 0x2b, 0x0a, 0x0b, 0x51, 0x75, 0x65, 0x72, 0x79, 0x53, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x12, 0x0a, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x1a, 0x0c, 0x2e,
+	0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x00, 0x30, 0x01, 0x12,
+	0x27, 0x0a, 0x07, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65, 0x12, 0x0a, 0x2e, 0x53, 0x74, 0x61,
+	0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x1a, 0x0e, 0x2e, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65,
+	0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x00, 0x12, 0x35, 0x0a, 0x0e, 0x45, 0x78, 0x65, 0x63,
+	0x75, 0x74, 0x65, 0x4f, 0x72, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x0a, 0x2e, 0x53, 0x74, 0x61,
+	0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x1a, 0x15, 0x2e, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65,
+	0x4f, 0x72, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x00, 0x12,
+	0x2c, 0x0a, 0x08, 0x43, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x12, 0x11, 0x2e, 0x49, 0x6e,
+	0x76, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x1a, 0x07,
+	0x2e, 0x49, 0x6e, 0x76, 0x6f, 0x6b, 0x65, 0x22, 0x00, 0x28, 0x01, 0x30, 0x01, 0x12, 0x31, 0x0a,
+	0x0a, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x2e, 0x43, 0x6f,
+	0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x0d, 0x2e, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x22, 0x00,
+	0x12, 0x20, 0x0a, 0x05, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x12, 0x0d, 0x2e, 0x43, 0x6f, 0x6e, 0x6e,
+	0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x1a, 0x06, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79,
+	0x22, 0x00, 0x12, 0x22, 0x0a, 0x07, 0x49, 0x73, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x12, 0x0d, 0x2e,
+	0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x1a, 0x06, 0x2e, 0x45,
+	0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x18, 0x0a, 0x04, 0x50, 0x69, 0x6e, 0x67, 0x12, 0x06,
+	0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x06, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00,
+	0x12, 0x2e, 0x0a, 0x0c, 0x52, 0x65, 0x73, 0x65, 0x74, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
 	0x12, 0x0d, 0x2e, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x1a,
-	0x06, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x18, 0x0a, 0x04, 0x50, 0x69, 0x6e,
-	0x67, 0x12, 0x06, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x06, 0x2e, 0x45, 0x6d, 0x70, 0x74,
-	0x79, 0x22, 0x00, 0x12, 0x2e, 0x0a, 0x0c, 0x52, 0x65, 0x73, 0x65, 0x74, 0x53, 0x65, 0x73, 0x73,
-	0x69, 0x6f, 0x6e, 0x12, 0x0d, 0x2e, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e,
-	0x49, 0x64, 0x1a, 0x0d, 0x2e, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x49,
-	0x64, 0x22, 0x00, 0x42, 0x1f, 0x5a, 0x1d, 0x67, 0x69, 0x74, 0x6c, 0x61, 0x62, 0x2e, 0x63, 0x6f,
-	0x6d, 0x2f, 0x61, 0x6f, 0x75, 0x73, 0x2e, 0x6f, 0x6d, 0x72, 0x2f, 0x73, 0x71, 0x6c, 0x69, 0x74,
-	0x65, 0x2d, 0x6f, 0x67, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x0d, 0x2e, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x22, 0x00,
+	0x12, 0x20, 0x0a, 0x05, 0x42, 0x65, 0x67, 0x69, 0x6e, 0x12, 0x0d, 0x2e, 0x42, 0x65, 0x67, 0x69,
+	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x06, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79,
+	0x22, 0x00, 0x12, 0x21, 0x0a, 0x06, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x12, 0x0d, 0x2e, 0x43,
+	0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x1a, 0x06, 0x2e, 0x45, 0x6d,
+	0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x23, 0x0a, 0x08, 0x52, 0x6f, 0x6c, 0x6c, 0x62, 0x61, 0x63,
+	0x6b, 0x12, 0x0d, 0x2e, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64,
+	0x1a, 0x06, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x2b, 0x0a, 0x07, 0x50, 0x72,
+	0x65, 0x70, 0x61, 0x72, 0x65, 0x12, 0x0a, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e,
+	0x74, 0x1a, 0x12, 0x2e, 0x50, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x6d, 0x65, 0x6e, 0x74, 0x22, 0x00, 0x12, 0x2c, 0x0a, 0x0c, 0x45, 0x78, 0x65, 0x63, 0x50,
+	0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x12, 0x0a, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d,
+	0x65, 0x6e, 0x74, 0x1a, 0x0e, 0x2e, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65, 0x52, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x22, 0x00, 0x12, 0x2d, 0x0a, 0x0d, 0x51, 0x75, 0x65, 0x72, 0x79, 0x50, 0x72,
+	0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x12, 0x0a, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65,
+	0x6e, 0x74, 0x1a, 0x0c, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x22, 0x00, 0x30, 0x01, 0x12, 0x25, 0x0a, 0x0d, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x50, 0x72, 0x65,
+	0x70, 0x61, 0x72, 0x65, 0x64, 0x12, 0x0a, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e,
+	0x74, 0x1a, 0x06, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x28, 0x0a, 0x05, 0x57,
+	0x61, 0x74, 0x63, 0x68, 0x12, 0x0d, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x0c, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x22, 0x00, 0x30, 0x01, 0x12, 0x2e, 0x0a, 0x0f, 0x45, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x43,
+	0x68, 0x61, 0x6e, 0x67, 0x65, 0x6c, 0x6f, 0x67, 0x12, 0x11, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x67,
+	0x65, 0x6c, 0x6f, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x06, 0x2e, 0x45, 0x6d,
+	0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x2f, 0x0a, 0x10, 0x44, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65,
+	0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x6c, 0x6f, 0x67, 0x12, 0x11, 0x2e, 0x43, 0x68, 0x61, 0x6e,
+	0x67, 0x65, 0x6c, 0x6f, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x06, 0x2e, 0x45,
+	0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x2c, 0x0a, 0x07, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65,
+	0x73, 0x12, 0x0f, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x0c, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74,
+	0x22, 0x00, 0x30, 0x01, 0x32, 0xb0, 0x02, 0x0a, 0x05, 0x41, 0x64, 0x6d, 0x69, 0x6e, 0x12, 0x28,
+	0x0a, 0x0d, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x73, 0x12,
+	0x06, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x0d, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61,
+	0x73, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x22, 0x00, 0x12, 0x39, 0x0a, 0x0e, 0x43, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x12, 0x16, 0x2e, 0x43, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x0d, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x49, 0x6e, 0x66,
+	0x6f, 0x22, 0x00, 0x12, 0x2e, 0x0a, 0x0c, 0x44, 0x72, 0x6f, 0x70, 0x44, 0x61, 0x74, 0x61, 0x62,
+	0x61, 0x73, 0x65, 0x12, 0x14, 0x2e, 0x44, 0x72, 0x6f, 0x70, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61,
+	0x73, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x06, 0x2e, 0x45, 0x6d, 0x70, 0x74,
+	0x79, 0x22, 0x00, 0x12, 0x39, 0x0a, 0x0e, 0x52, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x44, 0x61, 0x74,
+	0x61, 0x62, 0x61, 0x73, 0x65, 0x12, 0x16, 0x2e, 0x52, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x44, 0x61,
+	0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0d, 0x2e,
+	0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x22, 0x00, 0x12, 0x28,
+	0x0a, 0x06, 0x42, 0x61, 0x63, 0x6b, 0x75, 0x70, 0x12, 0x0e, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x75,
+	0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0a, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x43,
+	0x68, 0x75, 0x6e, 0x6b, 0x22, 0x00, 0x30, 0x01, 0x12, 0x2d, 0x0a, 0x07, 0x52, 0x65, 0x73, 0x74,
+	0x6f, 0x72, 0x65, 0x12, 0x0f, 0x2e, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x0d, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x49,
+	0x6e, 0x66, 0x6f, 0x22, 0x00, 0x28, 0x01, 0x42, 0x20, 0x5a, 0x1e, 0x67, 0x69, 0x74, 0x68, 0x75,
+	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x61, 0x6f, 0x75, 0x73, 0x6f, 0x6d, 0x72, 0x61, 0x6e, 0x2f,
+	0x73, 0x71, 0x6c, 0x69, 0x74, 0x65, 0x2d, 0x6f, 0x67, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
 }
 
 var (
@@ -651,46 +2488,123 @@ func file_proto_sqliteog_proto_rawDescGZIP() []byte {
 	return file_proto_sqliteog_proto_rawDescData
 }
 
-var file_proto_sqliteog_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_proto_sqliteog_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_proto_sqliteog_proto_msgTypes = make([]protoimpl.MessageInfo, 29)
 var file_proto_sqliteog_proto_goTypes = []interface{}{
-	(*Empty)(nil),                // 0: Empty
-	(*ConnectionId)(nil),         // 1: ConnectionId
-	(*ConnectionRequest)(nil),    // 2: ConnectionRequest
-	(*InvocationResult)(nil),     // 3: InvocationResult
-	(*Invoke)(nil),               // 4: Invoke
-	(*ExecuteOrQueryResult)(nil), // 5: ExecuteOrQueryResult
-	(*Statement)(nil),            // 6: Statement
-	(*Row)(nil),                  // 7: Row
-	(*QueryResult)(nil),          // 8: QueryResult
-	(*ExecuteResult)(nil),        // 9: ExecuteResult
+	(AccessMode)(0),               // 0: AccessMode
+	(TxLock)(0),                   // 1: TxLock
+	(InvokeType)(0),               // 2: InvokeType
+	(ChangeOperation)(0),          // 3: ChangeOperation
+	(*Empty)(nil),                 // 4: Empty
+	(*ConnectionId)(nil),          // 5: ConnectionId
+	(*ConnectionRequest)(nil),     // 6: ConnectionRequest
+	(*Access)(nil),                // 7: Access
+	(*Function)(nil),              // 8: Function
+	(*BeginRequest)(nil),          // 9: BeginRequest
+	(*InvocationResult)(nil),      // 10: InvocationResult
+	(*Invoke)(nil),                // 11: Invoke
+	(*ExecuteOrQueryResult)(nil),  // 12: ExecuteOrQueryResult
+	(*Statement)(nil),             // 13: Statement
+	(*PreparedStatement)(nil),     // 14: PreparedStatement
+	(*Value)(nil),                 // 15: Value
+	(*Row)(nil),                   // 16: Row
+	(*QueryResult)(nil),           // 17: QueryResult
+	(*ExecuteResult)(nil),         // 18: ExecuteResult
+	(*Parameter)(nil),             // 19: Parameter
+	(*WatchRequest)(nil),          // 20: WatchRequest
+	(*ChangeEvent)(nil),           // 21: ChangeEvent
+	(*ChangelogRequest)(nil),      // 22: ChangelogRequest
+	(*ChangesRequest)(nil),        // 23: ChangesRequest
+	(*DatabaseInfo)(nil),          // 24: DatabaseInfo
+	(*DatabaseList)(nil),          // 25: DatabaseList
+	(*CreateDatabaseRequest)(nil), // 26: CreateDatabaseRequest
+	(*DropDatabaseRequest)(nil),   // 27: DropDatabaseRequest
+	(*RenameDatabaseRequest)(nil), // 28: RenameDatabaseRequest
+	(*BackupRequest)(nil),         // 29: BackupRequest
+	(*FileChunk)(nil),             // 30: FileChunk
+	(*RestoreRequest)(nil),        // 31: RestoreRequest
+	nil,                           // 32: ConnectionRequest.OptionsEntry
 }
 var file_proto_sqliteog_proto_depIdxs = []int32{
-	8,  // 0: ExecuteOrQueryResult.query_result:type_name -> QueryResult
-	9,  // 1: ExecuteOrQueryResult.execute_result:type_name -> ExecuteResult
-	7,  // 2: QueryResult.rows:type_name -> Row
-	6,  // 3: SqliteOG.Query:input_type -> Statement
-	6,  // 4: SqliteOG.Execute:input_type -> Statement
-	6,  // 5: SqliteOG.ExecuteOrQuery:input_type -> Statement
-	3,  // 6: SqliteOG.Callback:input_type -> InvocationResult
-	2,  // 7: SqliteOG.Connection:input_type -> ConnectionRequest
-	1,  // 8: SqliteOG.Close:input_type -> ConnectionId
-	1,  // 9: SqliteOG.IsValid:input_type -> ConnectionId
-	0,  // 10: SqliteOG.Ping:input_type -> Empty
-	1,  // 11: SqliteOG.ResetSession:input_type -> ConnectionId
-	8,  // 12: SqliteOG.Query:output_type -> QueryResult
-	9,  // 13: SqliteOG.Execute:output_type -> ExecuteResult
-	5,  // 14: SqliteOG.ExecuteOrQuery:output_type -> ExecuteOrQueryResult
-	4,  // 15: SqliteOG.Callback:output_type -> Invoke
-	1,  // 16: SqliteOG.Connection:output_type -> ConnectionId
-	0,  // 17: SqliteOG.Close:output_type -> Empty
-	0,  // 18: SqliteOG.IsValid:output_type -> Empty
-	0,  // 19: SqliteOG.Ping:output_type -> Empty
-	1,  // 20: SqliteOG.ResetSession:output_type -> ConnectionId
-	12, // [12:21] is the sub-list for method output_type
-	3,  // [3:12] is the sub-list for method input_type
-	3,  // [3:3] is the sub-list for extension type_name
-	3,  // [3:3] is the sub-list for extension extendee
-	0,  // [0:3] is the sub-list for field type_name
+	8,  // 0: ConnectionRequest.functions:type_name -> Function
+	7,  // 1: ConnectionRequest.access:type_name -> Access
+	32, // 2: ConnectionRequest.options:type_name -> ConnectionRequest.OptionsEntry
+	8,  // 3: ConnectionRequest.aggregate_functions:type_name -> Function
+	0,  // 4: Access.mode:type_name -> AccessMode
+	1,  // 5: BeginRequest.lock:type_name -> TxLock
+	15, // 6: InvocationResult.result:type_name -> Value
+	15, // 7: Invoke.args:type_name -> Value
+	2,  // 8: Invoke.type:type_name -> InvokeType
+	17, // 9: ExecuteOrQueryResult.query_result:type_name -> QueryResult
+	18, // 10: ExecuteOrQueryResult.execute_result:type_name -> ExecuteResult
+	19, // 11: Statement.params:type_name -> Parameter
+	4,  // 12: Value.null:type_name -> Empty
+	15, // 13: Row.fields:type_name -> Value
+	16, // 14: QueryResult.rows:type_name -> Row
+	4,  // 15: Parameter.null:type_name -> Empty
+	3,  // 16: ChangeEvent.operation:type_name -> ChangeOperation
+	16, // 17: ChangeEvent.values:type_name -> Row
+	24, // 18: DatabaseList.databases:type_name -> DatabaseInfo
+	30, // 19: RestoreRequest.chunk:type_name -> FileChunk
+	13, // 20: SqliteOG.Query:input_type -> Statement
+	13, // 21: SqliteOG.QueryStream:input_type -> Statement
+	13, // 22: SqliteOG.Execute:input_type -> Statement
+	13, // 23: SqliteOG.ExecuteOrQuery:input_type -> Statement
+	10, // 24: SqliteOG.Callback:input_type -> InvocationResult
+	6,  // 25: SqliteOG.Connection:input_type -> ConnectionRequest
+	5,  // 26: SqliteOG.Close:input_type -> ConnectionId
+	5,  // 27: SqliteOG.IsValid:input_type -> ConnectionId
+	4,  // 28: SqliteOG.Ping:input_type -> Empty
+	5,  // 29: SqliteOG.ResetSession:input_type -> ConnectionId
+	9,  // 30: SqliteOG.Begin:input_type -> BeginRequest
+	5,  // 31: SqliteOG.Commit:input_type -> ConnectionId
+	5,  // 32: SqliteOG.Rollback:input_type -> ConnectionId
+	13, // 33: SqliteOG.Prepare:input_type -> Statement
+	13, // 34: SqliteOG.ExecPrepared:input_type -> Statement
+	13, // 35: SqliteOG.QueryPrepared:input_type -> Statement
+	13, // 36: SqliteOG.ClosePrepared:input_type -> Statement
+	20, // 37: SqliteOG.Watch:input_type -> WatchRequest
+	22, // 38: SqliteOG.EnableChangelog:input_type -> ChangelogRequest
+	22, // 39: SqliteOG.DisableChangelog:input_type -> ChangelogRequest
+	23, // 40: SqliteOG.Changes:input_type -> ChangesRequest
+	4,  // 41: Admin.ListDatabases:input_type -> Empty
+	26, // 42: Admin.CreateDatabase:input_type -> CreateDatabaseRequest
+	27, // 43: Admin.DropDatabase:input_type -> DropDatabaseRequest
+	28, // 44: Admin.RenameDatabase:input_type -> RenameDatabaseRequest
+	29, // 45: Admin.Backup:input_type -> BackupRequest
+	31, // 46: Admin.Restore:input_type -> RestoreRequest
+	17, // 47: SqliteOG.Query:output_type -> QueryResult
+	17, // 48: SqliteOG.QueryStream:output_type -> QueryResult
+	18, // 49: SqliteOG.Execute:output_type -> ExecuteResult
+	12, // 50: SqliteOG.ExecuteOrQuery:output_type -> ExecuteOrQueryResult
+	11, // 51: SqliteOG.Callback:output_type -> Invoke
+	5,  // 52: SqliteOG.Connection:output_type -> ConnectionId
+	4,  // 53: SqliteOG.Close:output_type -> Empty
+	4,  // 54: SqliteOG.IsValid:output_type -> Empty
+	4,  // 55: SqliteOG.Ping:output_type -> Empty
+	5,  // 56: SqliteOG.ResetSession:output_type -> ConnectionId
+	4,  // 57: SqliteOG.Begin:output_type -> Empty
+	4,  // 58: SqliteOG.Commit:output_type -> Empty
+	4,  // 59: SqliteOG.Rollback:output_type -> Empty
+	14, // 60: SqliteOG.Prepare:output_type -> PreparedStatement
+	18, // 61: SqliteOG.ExecPrepared:output_type -> ExecuteResult
+	17, // 62: SqliteOG.QueryPrepared:output_type -> QueryResult
+	4,  // 63: SqliteOG.ClosePrepared:output_type -> Empty
+	21, // 64: SqliteOG.Watch:output_type -> ChangeEvent
+	4,  // 65: SqliteOG.EnableChangelog:output_type -> Empty
+	4,  // 66: SqliteOG.DisableChangelog:output_type -> Empty
+	21, // 67: SqliteOG.Changes:output_type -> ChangeEvent
+	25, // 68: Admin.ListDatabases:output_type -> DatabaseList
+	24, // 69: Admin.CreateDatabase:output_type -> DatabaseInfo
+	4,  // 70: Admin.DropDatabase:output_type -> Empty
+	24, // 71: Admin.RenameDatabase:output_type -> DatabaseInfo
+	30, // 72: Admin.Backup:output_type -> FileChunk
+	24, // 73: Admin.Restore:output_type -> DatabaseInfo
+	47, // [47:74] is the sub-list for method output_type
+	20, // [20:47] is the sub-list for method input_type
+	20, // [20:20] is the sub-list for extension type_name
+	20, // [20:20] is the sub-list for extension extendee
+	0,  // [0:20] is the sub-list for field type_name
 }
 
 func init() { file_proto_sqliteog_proto_init() }
@@ -736,7 +2650,7 @@ func file_proto_sqliteog_proto_init() {
 			}
 		}
 		file_proto_sqliteog_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*InvocationResult); i {
+			switch v := v.(*Access); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -748,7 +2662,7 @@ func file_proto_sqliteog_proto_init() {
 			}
 		}
 		file_proto_sqliteog_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Invoke); i {
+			switch v := v.(*Function); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -760,7 +2674,7 @@ func file_proto_sqliteog_proto_init() {
 			}
 		}
 		file_proto_sqliteog_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ExecuteOrQueryResult); i {
+			switch v := v.(*BeginRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -772,7 +2686,7 @@ func file_proto_sqliteog_proto_init() {
 			}
 		}
 		file_proto_sqliteog_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Statement); i {
+			switch v := v.(*InvocationResult); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -784,7 +2698,7 @@ func file_proto_sqliteog_proto_init() {
 			}
 		}
 		file_proto_sqliteog_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Row); i {
+			switch v := v.(*Invoke); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -796,7 +2710,7 @@ func file_proto_sqliteog_proto_init() {
 			}
 		}
 		file_proto_sqliteog_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*QueryResult); i {
+			switch v := v.(*ExecuteOrQueryResult); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -808,6 +2722,66 @@ func file_proto_sqliteog_proto_init() {
 			}
 		}
 		file_proto_sqliteog_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Statement); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_sqliteog_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PreparedStatement); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_sqliteog_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Value); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_sqliteog_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Row); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_sqliteog_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueryResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_sqliteog_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ExecuteResult); i {
 			case 0:
 				return &v.state
@@ -819,19 +2793,191 @@ func file_proto_sqliteog_proto_init() {
 				return nil
 			}
 		}
+		file_proto_sqliteog_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Parameter); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_sqliteog_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_sqliteog_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChangeEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_sqliteog_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChangelogRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_sqliteog_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChangesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_sqliteog_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DatabaseInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_sqliteog_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DatabaseList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_sqliteog_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateDatabaseRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_sqliteog_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DropDatabaseRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_sqliteog_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RenameDatabaseRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_sqliteog_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BackupRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_sqliteog_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FileChunk); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_sqliteog_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RestoreRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_proto_sqliteog_proto_msgTypes[11].OneofWrappers = []interface{}{
+		(*Value_Null)(nil),
+		(*Value_Integer)(nil),
+		(*Value_Real)(nil),
+		(*Value_Text)(nil),
+		(*Value_Blob)(nil),
+	}
+	file_proto_sqliteog_proto_msgTypes[15].OneofWrappers = []interface{}{
+		(*Parameter_I)(nil),
+		(*Parameter_D)(nil),
+		(*Parameter_B)(nil),
+		(*Parameter_Y)(nil),
+		(*Parameter_S)(nil),
+		(*Parameter_Null)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_proto_sqliteog_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   10,
+			NumEnums:      4,
+			NumMessages:   29,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_proto_sqliteog_proto_goTypes,
 		DependencyIndexes: file_proto_sqliteog_proto_depIdxs,
+		EnumInfos:         file_proto_sqliteog_proto_enumTypes,
 		MessageInfos:      file_proto_sqliteog_proto_msgTypes,
 	}.Build()
 	File_proto_sqliteog_proto = out.File
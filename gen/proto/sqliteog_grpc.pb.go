@@ -23,6 +23,7 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type SqliteOGClient interface {
 	Query(ctx context.Context, in *Statement, opts ...grpc.CallOption) (*QueryResult, error)
+	QueryStream(ctx context.Context, in *Statement, opts ...grpc.CallOption) (SqliteOG_QueryStreamClient, error)
 	Execute(ctx context.Context, in *Statement, opts ...grpc.CallOption) (*ExecuteResult, error)
 	ExecuteOrQuery(ctx context.Context, in *Statement, opts ...grpc.CallOption) (*ExecuteOrQueryResult, error)
 	Callback(ctx context.Context, opts ...grpc.CallOption) (SqliteOG_CallbackClient, error)
@@ -31,6 +32,17 @@ type SqliteOGClient interface {
 	IsValid(ctx context.Context, in *ConnectionId, opts ...grpc.CallOption) (*Empty, error)
 	Ping(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
 	ResetSession(ctx context.Context, in *ConnectionId, opts ...grpc.CallOption) (*ConnectionId, error)
+	Begin(ctx context.Context, in *BeginRequest, opts ...grpc.CallOption) (*Empty, error)
+	Commit(ctx context.Context, in *ConnectionId, opts ...grpc.CallOption) (*Empty, error)
+	Rollback(ctx context.Context, in *ConnectionId, opts ...grpc.CallOption) (*Empty, error)
+	Prepare(ctx context.Context, in *Statement, opts ...grpc.CallOption) (*PreparedStatement, error)
+	ExecPrepared(ctx context.Context, in *Statement, opts ...grpc.CallOption) (*ExecuteResult, error)
+	QueryPrepared(ctx context.Context, in *Statement, opts ...grpc.CallOption) (SqliteOG_QueryPreparedClient, error)
+	ClosePrepared(ctx context.Context, in *Statement, opts ...grpc.CallOption) (*Empty, error)
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (SqliteOG_WatchClient, error)
+	EnableChangelog(ctx context.Context, in *ChangelogRequest, opts ...grpc.CallOption) (*Empty, error)
+	DisableChangelog(ctx context.Context, in *ChangelogRequest, opts ...grpc.CallOption) (*Empty, error)
+	Changes(ctx context.Context, in *ChangesRequest, opts ...grpc.CallOption) (SqliteOG_ChangesClient, error)
 }
 
 type sqliteOGClient struct {
@@ -50,6 +62,38 @@ func (c *sqliteOGClient) Query(ctx context.Context, in *Statement, opts ...grpc.
 	return out, nil
 }
 
+func (c *sqliteOGClient) QueryStream(ctx context.Context, in *Statement, opts ...grpc.CallOption) (SqliteOG_QueryStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &SqliteOG_ServiceDesc.Streams[0], "/SqliteOG/QueryStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sqliteOGQueryStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type SqliteOG_QueryStreamClient interface {
+	Recv() (*QueryResult, error)
+	grpc.ClientStream
+}
+
+type sqliteOGQueryStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *sqliteOGQueryStreamClient) Recv() (*QueryResult, error) {
+	m := new(QueryResult)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *sqliteOGClient) Execute(ctx context.Context, in *Statement, opts ...grpc.CallOption) (*ExecuteResult, error) {
 	out := new(ExecuteResult)
 	err := c.cc.Invoke(ctx, "/SqliteOG/Execute", in, out, opts...)
@@ -69,7 +113,7 @@ func (c *sqliteOGClient) ExecuteOrQuery(ctx context.Context, in *Statement, opts
 }
 
 func (c *sqliteOGClient) Callback(ctx context.Context, opts ...grpc.CallOption) (SqliteOG_CallbackClient, error) {
-	stream, err := c.cc.NewStream(ctx, &SqliteOG_ServiceDesc.Streams[0], "/SqliteOG/Callback", opts...)
+	stream, err := c.cc.NewStream(ctx, &SqliteOG_ServiceDesc.Streams[1], "/SqliteOG/Callback", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -144,11 +188,180 @@ func (c *sqliteOGClient) ResetSession(ctx context.Context, in *ConnectionId, opt
 	return out, nil
 }
 
+func (c *sqliteOGClient) Begin(ctx context.Context, in *BeginRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/SqliteOG/Begin", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sqliteOGClient) Commit(ctx context.Context, in *ConnectionId, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/SqliteOG/Commit", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sqliteOGClient) Rollback(ctx context.Context, in *ConnectionId, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/SqliteOG/Rollback", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sqliteOGClient) Prepare(ctx context.Context, in *Statement, opts ...grpc.CallOption) (*PreparedStatement, error) {
+	out := new(PreparedStatement)
+	err := c.cc.Invoke(ctx, "/SqliteOG/Prepare", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sqliteOGClient) ExecPrepared(ctx context.Context, in *Statement, opts ...grpc.CallOption) (*ExecuteResult, error) {
+	out := new(ExecuteResult)
+	err := c.cc.Invoke(ctx, "/SqliteOG/ExecPrepared", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sqliteOGClient) QueryPrepared(ctx context.Context, in *Statement, opts ...grpc.CallOption) (SqliteOG_QueryPreparedClient, error) {
+	stream, err := c.cc.NewStream(ctx, &SqliteOG_ServiceDesc.Streams[2], "/SqliteOG/QueryPrepared", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sqliteOGQueryPreparedClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type SqliteOG_QueryPreparedClient interface {
+	Recv() (*QueryResult, error)
+	grpc.ClientStream
+}
+
+type sqliteOGQueryPreparedClient struct {
+	grpc.ClientStream
+}
+
+func (x *sqliteOGQueryPreparedClient) Recv() (*QueryResult, error) {
+	m := new(QueryResult)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *sqliteOGClient) ClosePrepared(ctx context.Context, in *Statement, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/SqliteOG/ClosePrepared", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sqliteOGClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (SqliteOG_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &SqliteOG_ServiceDesc.Streams[3], "/SqliteOG/Watch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sqliteOGWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type SqliteOG_WatchClient interface {
+	Recv() (*ChangeEvent, error)
+	grpc.ClientStream
+}
+
+type sqliteOGWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *sqliteOGWatchClient) Recv() (*ChangeEvent, error) {
+	m := new(ChangeEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *sqliteOGClient) EnableChangelog(ctx context.Context, in *ChangelogRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/SqliteOG/EnableChangelog", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sqliteOGClient) DisableChangelog(ctx context.Context, in *ChangelogRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/SqliteOG/DisableChangelog", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sqliteOGClient) Changes(ctx context.Context, in *ChangesRequest, opts ...grpc.CallOption) (SqliteOG_ChangesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &SqliteOG_ServiceDesc.Streams[4], "/SqliteOG/Changes", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sqliteOGChangesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type SqliteOG_ChangesClient interface {
+	Recv() (*ChangeEvent, error)
+	grpc.ClientStream
+}
+
+type sqliteOGChangesClient struct {
+	grpc.ClientStream
+}
+
+func (x *sqliteOGChangesClient) Recv() (*ChangeEvent, error) {
+	m := new(ChangeEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // SqliteOGServer is the server API for SqliteOG service.
 // All implementations must embed UnimplementedSqliteOGServer
 // for forward compatibility
 type SqliteOGServer interface {
 	Query(context.Context, *Statement) (*QueryResult, error)
+	QueryStream(*Statement, SqliteOG_QueryStreamServer) error
 	Execute(context.Context, *Statement) (*ExecuteResult, error)
 	ExecuteOrQuery(context.Context, *Statement) (*ExecuteOrQueryResult, error)
 	Callback(SqliteOG_CallbackServer) error
@@ -157,6 +370,17 @@ type SqliteOGServer interface {
 	IsValid(context.Context, *ConnectionId) (*Empty, error)
 	Ping(context.Context, *Empty) (*Empty, error)
 	ResetSession(context.Context, *ConnectionId) (*ConnectionId, error)
+	Begin(context.Context, *BeginRequest) (*Empty, error)
+	Commit(context.Context, *ConnectionId) (*Empty, error)
+	Rollback(context.Context, *ConnectionId) (*Empty, error)
+	Prepare(context.Context, *Statement) (*PreparedStatement, error)
+	ExecPrepared(context.Context, *Statement) (*ExecuteResult, error)
+	QueryPrepared(*Statement, SqliteOG_QueryPreparedServer) error
+	ClosePrepared(context.Context, *Statement) (*Empty, error)
+	Watch(*WatchRequest, SqliteOG_WatchServer) error
+	EnableChangelog(context.Context, *ChangelogRequest) (*Empty, error)
+	DisableChangelog(context.Context, *ChangelogRequest) (*Empty, error)
+	Changes(*ChangesRequest, SqliteOG_ChangesServer) error
 	mustEmbedUnimplementedSqliteOGServer()
 }
 
@@ -167,6 +391,9 @@ type UnimplementedSqliteOGServer struct {
 func (UnimplementedSqliteOGServer) Query(context.Context, *Statement) (*QueryResult, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Query not implemented")
 }
+func (UnimplementedSqliteOGServer) QueryStream(*Statement, SqliteOG_QueryStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method QueryStream not implemented")
+}
 func (UnimplementedSqliteOGServer) Execute(context.Context, *Statement) (*ExecuteResult, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Execute not implemented")
 }
@@ -191,6 +418,39 @@ func (UnimplementedSqliteOGServer) Ping(context.Context, *Empty) (*Empty, error)
 func (UnimplementedSqliteOGServer) ResetSession(context.Context, *ConnectionId) (*ConnectionId, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ResetSession not implemented")
 }
+func (UnimplementedSqliteOGServer) Begin(context.Context, *BeginRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Begin not implemented")
+}
+func (UnimplementedSqliteOGServer) Commit(context.Context, *ConnectionId) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Commit not implemented")
+}
+func (UnimplementedSqliteOGServer) Rollback(context.Context, *ConnectionId) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Rollback not implemented")
+}
+func (UnimplementedSqliteOGServer) Prepare(context.Context, *Statement) (*PreparedStatement, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Prepare not implemented")
+}
+func (UnimplementedSqliteOGServer) ExecPrepared(context.Context, *Statement) (*ExecuteResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExecPrepared not implemented")
+}
+func (UnimplementedSqliteOGServer) QueryPrepared(*Statement, SqliteOG_QueryPreparedServer) error {
+	return status.Errorf(codes.Unimplemented, "method QueryPrepared not implemented")
+}
+func (UnimplementedSqliteOGServer) ClosePrepared(context.Context, *Statement) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClosePrepared not implemented")
+}
+func (UnimplementedSqliteOGServer) Watch(*WatchRequest, SqliteOG_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedSqliteOGServer) EnableChangelog(context.Context, *ChangelogRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnableChangelog not implemented")
+}
+func (UnimplementedSqliteOGServer) DisableChangelog(context.Context, *ChangelogRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DisableChangelog not implemented")
+}
+func (UnimplementedSqliteOGServer) Changes(*ChangesRequest, SqliteOG_ChangesServer) error {
+	return status.Errorf(codes.Unimplemented, "method Changes not implemented")
+}
 func (UnimplementedSqliteOGServer) mustEmbedUnimplementedSqliteOGServer() {}
 
 // UnsafeSqliteOGServer may be embedded to opt out of forward compatibility for this service.
@@ -222,6 +482,27 @@ func _SqliteOG_Query_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SqliteOG_QueryStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Statement)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SqliteOGServer).QueryStream(m, &sqliteOGQueryStreamServer{stream})
+}
+
+type SqliteOG_QueryStreamServer interface {
+	Send(*QueryResult) error
+	grpc.ServerStream
+}
+
+type sqliteOGQueryStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *sqliteOGQueryStreamServer) Send(m *QueryResult) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _SqliteOG_Execute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Statement)
 	if err := dec(in); err != nil {
@@ -374,51 +655,642 @@ func _SqliteOG_ResetSession_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
-// SqliteOG_ServiceDesc is the grpc.ServiceDesc for SqliteOG service.
-// It's only intended for direct use with grpc.RegisterService,
-// and not to be introspected or modified (even as a copy)
-var SqliteOG_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "SqliteOG",
-	HandlerType: (*SqliteOGServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "Query",
-			Handler:    _SqliteOG_Query_Handler,
-		},
-		{
-			MethodName: "Execute",
-			Handler:    _SqliteOG_Execute_Handler,
-		},
-		{
-			MethodName: "ExecuteOrQuery",
-			Handler:    _SqliteOG_ExecuteOrQuery_Handler,
-		},
-		{
-			MethodName: "Connection",
-			Handler:    _SqliteOG_Connection_Handler,
-		},
-		{
-			MethodName: "Close",
-			Handler:    _SqliteOG_Close_Handler,
-		},
-		{
-			MethodName: "IsValid",
-			Handler:    _SqliteOG_IsValid_Handler,
-		},
-		{
-			MethodName: "Ping",
-			Handler:    _SqliteOG_Ping_Handler,
-		},
-		{
-			MethodName: "ResetSession",
-			Handler:    _SqliteOG_ResetSession_Handler,
-		},
-	},
-	Streams: []grpc.StreamDesc{
-		{
-			StreamName:    "Callback",
-			Handler:       _SqliteOG_Callback_Handler,
-			ServerStreams: true,
+func _SqliteOG_Begin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BeginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SqliteOGServer).Begin(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/SqliteOG/Begin",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SqliteOGServer).Begin(ctx, req.(*BeginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SqliteOG_Commit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConnectionId)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SqliteOGServer).Commit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/SqliteOG/Commit",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SqliteOGServer).Commit(ctx, req.(*ConnectionId))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SqliteOG_Rollback_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConnectionId)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SqliteOGServer).Rollback(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/SqliteOG/Rollback",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SqliteOGServer).Rollback(ctx, req.(*ConnectionId))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SqliteOG_Prepare_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Statement)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SqliteOGServer).Prepare(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/SqliteOG/Prepare",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SqliteOGServer).Prepare(ctx, req.(*Statement))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SqliteOG_ExecPrepared_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Statement)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SqliteOGServer).ExecPrepared(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/SqliteOG/ExecPrepared",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SqliteOGServer).ExecPrepared(ctx, req.(*Statement))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SqliteOG_QueryPrepared_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Statement)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SqliteOGServer).QueryPrepared(m, &sqliteOGQueryPreparedServer{stream})
+}
+
+type SqliteOG_QueryPreparedServer interface {
+	Send(*QueryResult) error
+	grpc.ServerStream
+}
+
+type sqliteOGQueryPreparedServer struct {
+	grpc.ServerStream
+}
+
+func (x *sqliteOGQueryPreparedServer) Send(m *QueryResult) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _SqliteOG_ClosePrepared_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Statement)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SqliteOGServer).ClosePrepared(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/SqliteOG/ClosePrepared",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SqliteOGServer).ClosePrepared(ctx, req.(*Statement))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SqliteOG_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SqliteOGServer).Watch(m, &sqliteOGWatchServer{stream})
+}
+
+type SqliteOG_WatchServer interface {
+	Send(*ChangeEvent) error
+	grpc.ServerStream
+}
+
+type sqliteOGWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *sqliteOGWatchServer) Send(m *ChangeEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _SqliteOG_EnableChangelog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangelogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SqliteOGServer).EnableChangelog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/SqliteOG/EnableChangelog",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SqliteOGServer).EnableChangelog(ctx, req.(*ChangelogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SqliteOG_DisableChangelog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangelogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SqliteOGServer).DisableChangelog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/SqliteOG/DisableChangelog",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SqliteOGServer).DisableChangelog(ctx, req.(*ChangelogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SqliteOG_Changes_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ChangesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SqliteOGServer).Changes(m, &sqliteOGChangesServer{stream})
+}
+
+type SqliteOG_ChangesServer interface {
+	Send(*ChangeEvent) error
+	grpc.ServerStream
+}
+
+type sqliteOGChangesServer struct {
+	grpc.ServerStream
+}
+
+func (x *sqliteOGChangesServer) Send(m *ChangeEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// SqliteOG_ServiceDesc is the grpc.ServiceDesc for SqliteOG service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SqliteOG_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "SqliteOG",
+	HandlerType: (*SqliteOGServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Query",
+			Handler:    _SqliteOG_Query_Handler,
+		},
+		{
+			MethodName: "Execute",
+			Handler:    _SqliteOG_Execute_Handler,
+		},
+		{
+			MethodName: "ExecuteOrQuery",
+			Handler:    _SqliteOG_ExecuteOrQuery_Handler,
+		},
+		{
+			MethodName: "Connection",
+			Handler:    _SqliteOG_Connection_Handler,
+		},
+		{
+			MethodName: "Close",
+			Handler:    _SqliteOG_Close_Handler,
+		},
+		{
+			MethodName: "IsValid",
+			Handler:    _SqliteOG_IsValid_Handler,
+		},
+		{
+			MethodName: "Ping",
+			Handler:    _SqliteOG_Ping_Handler,
+		},
+		{
+			MethodName: "ResetSession",
+			Handler:    _SqliteOG_ResetSession_Handler,
+		},
+		{
+			MethodName: "Begin",
+			Handler:    _SqliteOG_Begin_Handler,
+		},
+		{
+			MethodName: "Commit",
+			Handler:    _SqliteOG_Commit_Handler,
+		},
+		{
+			MethodName: "Rollback",
+			Handler:    _SqliteOG_Rollback_Handler,
+		},
+		{
+			MethodName: "Prepare",
+			Handler:    _SqliteOG_Prepare_Handler,
+		},
+		{
+			MethodName: "ExecPrepared",
+			Handler:    _SqliteOG_ExecPrepared_Handler,
+		},
+		{
+			MethodName: "ClosePrepared",
+			Handler:    _SqliteOG_ClosePrepared_Handler,
+		},
+		{
+			MethodName: "EnableChangelog",
+			Handler:    _SqliteOG_EnableChangelog_Handler,
+		},
+		{
+			MethodName: "DisableChangelog",
+			Handler:    _SqliteOG_DisableChangelog_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "QueryStream",
+			Handler:       _SqliteOG_QueryStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Callback",
+			Handler:       _SqliteOG_Callback_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "QueryPrepared",
+			Handler:       _SqliteOG_QueryPrepared_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Watch",
+			Handler:       _SqliteOG_Watch_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Changes",
+			Handler:       _SqliteOG_Changes_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/sqliteog.proto",
+}
+
+// AdminClient is the client API for Admin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AdminClient interface {
+	ListDatabases(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*DatabaseList, error)
+	CreateDatabase(ctx context.Context, in *CreateDatabaseRequest, opts ...grpc.CallOption) (*DatabaseInfo, error)
+	DropDatabase(ctx context.Context, in *DropDatabaseRequest, opts ...grpc.CallOption) (*Empty, error)
+	RenameDatabase(ctx context.Context, in *RenameDatabaseRequest, opts ...grpc.CallOption) (*DatabaseInfo, error)
+	// Backup streams a consistent copy of a database taken while it's in use
+	Backup(ctx context.Context, in *BackupRequest, opts ...grpc.CallOption) (Admin_BackupClient, error)
+	// Restore replaces a database, or creates it, with the file uploaded
+	Restore(ctx context.Context, opts ...grpc.CallOption) (Admin_RestoreClient, error)
+}
+
+type adminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAdminClient(cc grpc.ClientConnInterface) AdminClient {
+	return &adminClient{cc}
+}
+
+func (c *adminClient) ListDatabases(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*DatabaseList, error) {
+	out := new(DatabaseList)
+	err := c.cc.Invoke(ctx, "/Admin/ListDatabases", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) CreateDatabase(ctx context.Context, in *CreateDatabaseRequest, opts ...grpc.CallOption) (*DatabaseInfo, error) {
+	out := new(DatabaseInfo)
+	err := c.cc.Invoke(ctx, "/Admin/CreateDatabase", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) DropDatabase(ctx context.Context, in *DropDatabaseRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/Admin/DropDatabase", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) RenameDatabase(ctx context.Context, in *RenameDatabaseRequest, opts ...grpc.CallOption) (*DatabaseInfo, error) {
+	out := new(DatabaseInfo)
+	err := c.cc.Invoke(ctx, "/Admin/RenameDatabase", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) Backup(ctx context.Context, in *BackupRequest, opts ...grpc.CallOption) (Admin_BackupClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Admin_ServiceDesc.Streams[0], "/Admin/Backup", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &adminBackupClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Admin_BackupClient interface {
+	Recv() (*FileChunk, error)
+	grpc.ClientStream
+}
+
+type adminBackupClient struct {
+	grpc.ClientStream
+}
+
+func (x *adminBackupClient) Recv() (*FileChunk, error) {
+	m := new(FileChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *adminClient) Restore(ctx context.Context, opts ...grpc.CallOption) (Admin_RestoreClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Admin_ServiceDesc.Streams[1], "/Admin/Restore", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &adminRestoreClient{stream}
+	return x, nil
+}
+
+type Admin_RestoreClient interface {
+	Send(*RestoreRequest) error
+	CloseAndRecv() (*DatabaseInfo, error)
+	grpc.ClientStream
+}
+
+type adminRestoreClient struct {
+	grpc.ClientStream
+}
+
+func (x *adminRestoreClient) Send(m *RestoreRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *adminRestoreClient) CloseAndRecv() (*DatabaseInfo, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(DatabaseInfo)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// AdminServer is the server API for Admin service.
+// All implementations must embed UnimplementedAdminServer
+// for forward compatibility
+type AdminServer interface {
+	ListDatabases(context.Context, *Empty) (*DatabaseList, error)
+	CreateDatabase(context.Context, *CreateDatabaseRequest) (*DatabaseInfo, error)
+	DropDatabase(context.Context, *DropDatabaseRequest) (*Empty, error)
+	RenameDatabase(context.Context, *RenameDatabaseRequest) (*DatabaseInfo, error)
+	// Backup streams a consistent copy of a database taken while it's in use
+	Backup(*BackupRequest, Admin_BackupServer) error
+	// Restore replaces a database, or creates it, with the file uploaded
+	Restore(Admin_RestoreServer) error
+	mustEmbedUnimplementedAdminServer()
+}
+
+// UnimplementedAdminServer must be embedded to have forward compatible implementations.
+type UnimplementedAdminServer struct {
+}
+
+func (UnimplementedAdminServer) ListDatabases(context.Context, *Empty) (*DatabaseList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDatabases not implemented")
+}
+func (UnimplementedAdminServer) CreateDatabase(context.Context, *CreateDatabaseRequest) (*DatabaseInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateDatabase not implemented")
+}
+func (UnimplementedAdminServer) DropDatabase(context.Context, *DropDatabaseRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DropDatabase not implemented")
+}
+func (UnimplementedAdminServer) RenameDatabase(context.Context, *RenameDatabaseRequest) (*DatabaseInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RenameDatabase not implemented")
+}
+func (UnimplementedAdminServer) Backup(*BackupRequest, Admin_BackupServer) error {
+	return status.Errorf(codes.Unimplemented, "method Backup not implemented")
+}
+func (UnimplementedAdminServer) Restore(Admin_RestoreServer) error {
+	return status.Errorf(codes.Unimplemented, "method Restore not implemented")
+}
+func (UnimplementedAdminServer) mustEmbedUnimplementedAdminServer() {}
+
+// UnsafeAdminServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AdminServer will
+// result in compilation errors.
+type UnsafeAdminServer interface {
+	mustEmbedUnimplementedAdminServer()
+}
+
+func RegisterAdminServer(s grpc.ServiceRegistrar, srv AdminServer) {
+	s.RegisterService(&Admin_ServiceDesc, srv)
+}
+
+func _Admin_ListDatabases_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ListDatabases(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Admin/ListDatabases",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ListDatabases(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_CreateDatabase_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateDatabaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).CreateDatabase(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Admin/CreateDatabase",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).CreateDatabase(ctx, req.(*CreateDatabaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_DropDatabase_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DropDatabaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).DropDatabase(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Admin/DropDatabase",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).DropDatabase(ctx, req.(*DropDatabaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_RenameDatabase_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenameDatabaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).RenameDatabase(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Admin/RenameDatabase",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).RenameDatabase(ctx, req.(*RenameDatabaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_Backup_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(BackupRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AdminServer).Backup(m, &adminBackupServer{stream})
+}
+
+type Admin_BackupServer interface {
+	Send(*FileChunk) error
+	grpc.ServerStream
+}
+
+type adminBackupServer struct {
+	grpc.ServerStream
+}
+
+func (x *adminBackupServer) Send(m *FileChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Admin_Restore_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AdminServer).Restore(&adminRestoreServer{stream})
+}
+
+type Admin_RestoreServer interface {
+	SendAndClose(*DatabaseInfo) error
+	Recv() (*RestoreRequest, error)
+	grpc.ServerStream
+}
+
+type adminRestoreServer struct {
+	grpc.ServerStream
+}
+
+func (x *adminRestoreServer) SendAndClose(m *DatabaseInfo) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *adminRestoreServer) Recv() (*RestoreRequest, error) {
+	m := new(RestoreRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Admin_ServiceDesc is the grpc.ServiceDesc for Admin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Admin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "Admin",
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListDatabases",
+			Handler:    _Admin_ListDatabases_Handler,
+		},
+		{
+			MethodName: "CreateDatabase",
+			Handler:    _Admin_CreateDatabase_Handler,
+		},
+		{
+			MethodName: "DropDatabase",
+			Handler:    _Admin_DropDatabase_Handler,
+		},
+		{
+			MethodName: "RenameDatabase",
+			Handler:    _Admin_RenameDatabase_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Backup",
+			Handler:       _Admin_Backup_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Restore",
+			Handler:       _Admin_Restore_Handler,
 			ClientStreams: true,
 		},
 	},
@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/exp/slog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Authentication methods
+const (
+	MethodToken = "token"
+	MethodMTLS  = "mtls"
+)
+
+// ErrNoCredentials is returned by authenticators when the request does not
+// carry their credentials
+var ErrNoCredentials = errors.New("no credentials")
+
+// Identity is the authenticated principal of a request
+type Identity struct {
+	Name string
+	// Method is how the identity was authenticated, MethodToken or MethodMTLS
+	Method string
+}
+
+type Authenticator interface {
+	// Authenticate returns the identity of the request or ErrNoCredentials
+	Authenticate(ctx context.Context) (*Identity, error)
+}
+
+type identityKey struct{}
+
+func NewContext(ctx context.Context, id *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// FromContext returns the identity of the request, nil when authentication
+// is disabled
+func FromContext(ctx context.Context) *Identity {
+	id, _ := ctx.Value(identityKey{}).(*Identity)
+	return id
+}
+
+// Tokens authenticates the requests carrying a bearer token in their
+// authorization metadata, it maps the tokens to their identity.
+type Tokens map[string]string
+
+// LoadTokens reads a file of `<identity> <token>` lines, empty lines & lines
+// starting with # are ignored
+func LoadTokens(file string) (Tokens, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	tokens := Tokens{}
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected `<identity> <token>`", file, n)
+		}
+		if _, ok := tokens[fields[1]]; ok {
+			return nil, fmt.Errorf("%s:%d: duplicate token", file, n)
+		}
+		tokens[fields[1]] = fields[0]
+	}
+	return tokens, scanner.Err()
+}
+
+func (t Tokens) Authenticate(ctx context.Context) (*Identity, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, ErrNoCredentials
+	}
+	token, ok := strings.CutPrefix(values[0], "Bearer ")
+	if !ok {
+		return nil, fmt.Errorf("authorization is not a bearer token")
+	}
+	// every token is compared so that the time taken does not reveal them
+	var name string
+	for candidate, identity := range t {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(token)) == 1 {
+			name = identity
+		}
+	}
+	if name == "" {
+		return nil, fmt.Errorf("invalid token")
+	}
+	return &Identity{Name: name, Method: MethodToken}, nil
+}
+
+// MTLS authenticates the clients presenting a certificate verified by the
+// server's client CAs, their identity is the certificate's common name.
+type MTLS struct{}
+
+func (MTLS) Authenticate(ctx context.Context) (*Identity, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return nil, ErrNoCredentials
+	}
+	name := info.State.VerifiedChains[0][0].Subject.CommonName
+	if name == "" {
+		return nil, fmt.Errorf("client certificate has no common name")
+	}
+	return &Identity{Name: name, Method: MethodMTLS}, nil
+}
+
+// Authenticators authenticate a request with the first of them finding
+// credentials in it
+type Authenticators []Authenticator
+
+func (a Authenticators) authenticate(ctx context.Context) (*Identity, error) {
+	for _, authenticator := range a {
+		id, err := authenticator.Authenticate(ctx)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		if err != nil {
+			slog.WarnContext(ctx, "authentication failed", "error", err)
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		return id, nil
+	}
+	return nil, status.Error(codes.Unauthenticated, "missing credentials")
+}
+
+// UnaryServerInterceptor rejects the unauthenticated requests & adds the
+// identity of the others to their context
+func UnaryServerInterceptor(a Authenticators) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		id, err := a.authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(NewContext(ctx, id), req)
+	}
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor for streams
+func StreamServerInterceptor(a Authenticators) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		id, err := a.authenticate(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &identityStream{ServerStream: ss, ctx: NewContext(ss.Context(), id)})
+	}
+}
+
+type identityStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *identityStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,174 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
+)
+
+// Policy maps the identities to the databases they may open & to their
+// access, the first rule matching both the identity & the database applies.
+// A file of rules looks like:
+//
+//	{"rules": [
+//	  {"identities": ["admin"], "databases": ["*"], "admin": true},
+//	  {"identities": ["reporting"], "databases": ["reports/*.db"], "access": {"mode": "READ_ONLY"}},
+//	  {"identities": ["*"], "databases": ["public.db"], "access": {"mode": "READ_ONLY", "allowedTables": ["posts"]}}
+//	]}
+//
+// Identities & databases are path.Match patterns, a lone "*" matches any
+// name including the databases in directories. Only the rules with admin
+// set allow the Admin service to manage their databases.
+type Policy struct {
+	Rules []Rule
+}
+
+type Rule struct {
+	Identities []string
+	Databases  []string
+	// Access restricts the sessions opened under the rule, the access a
+	// client requests is applied on top of it. Full access when nil.
+	Access *pb.Access
+	// Admin lets the identities create, drop & rename the databases
+	Admin bool
+}
+
+type rule struct {
+	Identities []string        `json:"identities"`
+	Databases  []string        `json:"databases"`
+	Access     json.RawMessage `json:"access"`
+	Admin      bool            `json:"admin"`
+}
+
+// LoadPolicy reads a JSON policy file
+func LoadPolicy(file string) (*Policy, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var doc struct {
+		Rules []rule `json:"rules"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %w", file, err)
+	}
+	policy := &Policy{}
+	for i, r := range doc.Rules {
+		if len(r.Identities) == 0 || len(r.Databases) == 0 {
+			return nil, fmt.Errorf("invalid policy %s: rule %d needs identities & databases", file, i)
+		}
+		for _, pattern := range append(r.Identities, r.Databases...) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid policy %s: rule %d: pattern `%s`: %w", file, i, pattern, err)
+			}
+		}
+		var access *pb.Access
+		if len(r.Access) > 0 {
+			access = &pb.Access{}
+			if err := protojson.Unmarshal(r.Access, access); err != nil {
+				return nil, fmt.Errorf("invalid policy %s: rule %d access: %w", file, i, err)
+			}
+		}
+		policy.Rules = append(policy.Rules, Rule{Identities: r.Identities, Databases: r.Databases, Access: access, Admin: r.Admin})
+	}
+	return policy, nil
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if pattern == "*" {
+			return true
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorize returns the access of the identity to the database, it fails
+// with PermissionDenied when no rule allows it. A nil policy allows every
+// database with full access.
+func (p *Policy) Authorize(id *Identity, dbname string) (*pb.Access, error) {
+	r, err := p.rule(id, dbname)
+	if err != nil || r == nil {
+		return nil, err
+	}
+	return r.Access, nil
+}
+
+// AuthorizeAdmin checks that the identity may manage the database with the
+// Admin service. A nil policy allows every database.
+func (p *Policy) AuthorizeAdmin(id *Identity, dbname string) error {
+	r, err := p.rule(id, dbname)
+	if err != nil || r == nil {
+		return err
+	}
+	if !r.Admin {
+		return status.Errorf(codes.PermissionDenied, "%s is not allowed to manage %s", id.Name, dbname)
+	}
+	return nil
+}
+
+// rule returns the rule of the identity on the database, nil when there is
+// no policy
+func (p *Policy) rule(id *Identity, dbname string) (*Rule, error) {
+	if p == nil {
+		return nil, nil
+	}
+	if id == nil {
+		return nil, status.Error(codes.Unauthenticated, "missing credentials")
+	}
+	for i, r := range p.Rules {
+		if matchAny(r.Identities, id.Name) && matchAny(r.Databases, dbname) {
+			return &p.Rules[i], nil
+		}
+	}
+	return nil, status.Errorf(codes.PermissionDenied, "%s is not allowed to open %s", id.Name, dbname)
+}
+
+// AllowsTables tells whether a subscription to the tables, with or without
+// the values of their rows, reveals only what the access allows. A
+// subscription without tables watches every table, it is only allowed when
+// the access does not restrict tables.
+func AllowsTables(access *pb.Access, tables []string, values bool) bool {
+	if access == nil {
+		return true
+	}
+	if values && (len(access.GetAllowedColumns()) > 0 || len(access.GetDeniedColumns()) > 0) {
+		return false
+	}
+	if len(access.GetAllowedTables()) == 0 && len(access.GetDeniedTables()) == 0 {
+		return true
+	}
+	if len(tables) == 0 {
+		return false
+	}
+	for _, table := range tables {
+		if contains(access.GetDeniedTables(), table) {
+			return false
+		}
+		if len(access.GetAllowedTables()) > 0 && !contains(access.GetAllowedTables(), table) {
+			return false
+		}
+	}
+	return true
+}
+
+// contains compares the names as sqlite does, ignoring their case
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
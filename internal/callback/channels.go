@@ -1,15 +1,111 @@
 package callback
 
-import pb "github.com/aousomran/sqlite-og/gen/proto"
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"golang.org/x/exp/slog"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
+)
+
+// DefaultTimeout is the time a callback is given to return its result
+const DefaultTimeout = 30 * time.Second
+
+var ErrDisconnected = errors.New("callback stream is disconnected")
+var ErrTimeout = errors.New("callback timed out")
+
+// CallbackChannels carries invocations to the client's Callback stream,
+// results are routed back to the waiting caller by invocation id, so
+// several statements can invoke callbacks concurrently.
 type CallbackChannels struct {
-	ChanSend    chan *pb.Invoke
-	ChanReceive chan *pb.InvocationResult
+	ChanSend chan *pb.Invoke
+	// Timeout bounds every invocation, zero waits forever
+	Timeout time.Duration
+
+	nextId  atomic.Uint64
+	mutex   sync.Mutex
+	pending map[uint64]chan *pb.InvocationResult
+	// done is closed once the client's Callback stream is gone
+	done     chan struct{}
+	doneOnce sync.Once
 }
 
-func New() *CallbackChannels {
+func New(timeout time.Duration) *CallbackChannels {
 	return &CallbackChannels{
-		ChanSend:    make(chan *pb.Invoke),
-		ChanReceive: make(chan *pb.InvocationResult),
+		ChanSend: make(chan *pb.Invoke),
+		Timeout:  timeout,
+		pending:  map[uint64]chan *pb.InvocationResult{},
+		done:     make(chan struct{}),
+	}
+}
+
+// Invoke assigns an id to the invocation, sends it & waits for its result.
+// An error is returned if the callback failed on the client, if it did not
+// answer within Timeout or if the Callback stream is disconnected.
+func (c *CallbackChannels) Invoke(in *pb.Invoke) (*pb.InvocationResult, error) {
+	in.Id = c.nextId.Add(1)
+	ch := make(chan *pb.InvocationResult, 1)
+	c.mutex.Lock()
+	c.pending[in.Id] = ch
+	c.mutex.Unlock()
+	defer c.forget(in.Id)
+
+	var timeout <-chan time.Time
+	if c.Timeout > 0 {
+		timer := time.NewTimer(c.Timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case c.ChanSend <- in:
+	case <-c.done:
+		return nil, fmt.Errorf("%s: %w", in.GetFunctionName(), ErrDisconnected)
+	case <-timeout:
+		return nil, fmt.Errorf("%s: %w after %s", in.GetFunctionName(), ErrTimeout, c.Timeout)
+	}
+
+	select {
+	case result := <-ch:
+		if result.GetError() != "" {
+			return nil, fmt.Errorf("%s: %s", in.GetFunctionName(), result.GetError())
+		}
+		return result, nil
+	case <-c.done:
+		return nil, fmt.Errorf("%s: %w", in.GetFunctionName(), ErrDisconnected)
+	case <-timeout:
+		return nil, fmt.Errorf("%s: %w after %s", in.GetFunctionName(), ErrTimeout, c.Timeout)
+	}
+}
+
+// forget drops the invocation, a result arriving later is discarded
+func (c *CallbackChannels) forget(id uint64) {
+	c.mutex.Lock()
+	delete(c.pending, id)
+	c.mutex.Unlock()
+}
+
+// Deliver hands the result to the caller waiting for it
+func (c *CallbackChannels) Deliver(result *pb.InvocationResult) {
+	c.mutex.Lock()
+	ch, ok := c.pending[result.GetId()]
+	delete(c.pending, result.GetId())
+	c.mutex.Unlock()
+	if !ok {
+		slog.Warn("received result of unknown or expired invocation", "id", result.GetId())
+		return
 	}
+	ch <- result
+}
+
+// Disconnect fails the pending & future invocations, it's called when the
+// client's Callback stream ends.
+func (c *CallbackChannels) Disconnect() {
+	c.doneOnce.Do(func() {
+		close(c.done)
+	})
 }
@@ -0,0 +1,311 @@
+package changelog
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
+)
+
+// Table is the system table the triggers log the changes to, seq is
+// AUTOINCREMENT so sequences are never reused, even after retention
+// deleted the entries.
+const Table = "_sqliteog_changelog"
+const stateTable = "_sqliteog_changelog_state"
+const triggerPrefix = "_sqliteog_changelog_"
+
+// truncatedKey is the state holding the last sequence deleted by retention,
+// the tokens before it have expired.
+const truncatedKey = "truncated"
+
+var ErrNotEnabled = errors.New("changelog is not enabled")
+var ErrTokenExpired = errors.New("token has expired, changes after it were deleted by retention")
+
+// querier is implemented by *sql.DB, *sql.Conn & *sql.Tx
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Retention bounds the changelog, zero values disable a limit
+type Retention struct {
+	// MaxAge deletes the entries older than it
+	MaxAge time.Duration
+	// MaxEntries deletes the oldest entries beyond it
+	MaxEntries int64
+	// Compact deletes the entries superseded by a later change of the same
+	// row, a consumer resuming from an older token still sees the last
+	// change of every row.
+	Compact bool
+}
+
+func quote(identifier string) string {
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
+
+func literal(s string) string {
+	return `'` + strings.ReplaceAll(s, `'`, `''`) + `'`
+}
+
+// Enabled reports whether the database has a changelog
+func Enabled(ctx context.Context, q querier) (bool, error) {
+	var n int
+	err := q.QueryRowContext(ctx, `SELECT count(*) FROM sqlite_schema WHERE type = 'table' AND name = ?`, Table).Scan(&n)
+	return n > 0, err
+}
+
+// Enable creates the changelog & the triggers logging the changes of the
+// tables, or of every table when none is given. Tables created afterwards
+// are logged once Enable is called again. WITHOUT ROWID tables cannot be
+// logged.
+func Enable(ctx context.Context, db *sql.DB, tables []string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		seq INTEGER PRIMARY KEY AUTOINCREMENT,
+		tbl TEXT NOT NULL,
+		op INTEGER NOT NULL,
+		row_id INTEGER NOT NULL,
+		ts INTEGER NOT NULL
+	)`, Table))
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s ON %s (tbl, row_id)`, quote(Table+"_row"), Table))
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (key TEXT PRIMARY KEY, value INTEGER NOT NULL)`, stateTable))
+	if err != nil {
+		return err
+	}
+
+	schemas, err := tableSchemas(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if len(tables) == 0 {
+		for name := range schemas {
+			tables = append(tables, name)
+		}
+	}
+	for _, table := range tables {
+		schema, ok := schemas[strings.ToLower(table)]
+		if !ok {
+			return fmt.Errorf("no such table: %s", table)
+		}
+		if strings.Contains(strings.ToUpper(schema.sql), "WITHOUT ROWID") {
+			return fmt.Errorf("WITHOUT ROWID table %s cannot be logged", schema.name)
+		}
+		if err := createTriggers(ctx, tx, schema.name); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+type tableSchema struct {
+	name string
+	sql  string
+}
+
+// tableSchemas returns the user tables keyed by their lower case name
+func tableSchemas(ctx context.Context, q querier) (map[string]tableSchema, error) {
+	rows, err := q.QueryContext(ctx, `SELECT name, sql FROM sqlite_schema
+		WHERE type = 'table' AND name NOT GLOB 'sqlite_*' AND name NOT GLOB '_sqliteog_*'`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	schemas := map[string]tableSchema{}
+	for rows.Next() {
+		var s tableSchema
+		if err := rows.Scan(&s.name, &s.sql); err != nil {
+			return nil, err
+		}
+		schemas[strings.ToLower(s.name)] = s
+	}
+	return schemas, rows.Err()
+}
+
+func createTriggers(ctx context.Context, q querier, table string) error {
+	// milliseconds since the unix epoch
+	const now = `CAST((julianday('now') - 2440587.5) * 86400000 AS INTEGER)`
+	insert := fmt.Sprintf(`INSERT INTO %s (tbl, op, row_id, ts)`, Table)
+	statements := []string{
+		fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS %s AFTER INSERT ON %s BEGIN
+			%s VALUES (%s, %d, NEW.rowid, %s);
+		END`, quote(triggerPrefix+table+"_insert"), quote(table), insert, literal(table), pb.ChangeOperation_INSERT, now),
+		// an update changing the rowid deletes the old row
+		fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS %s AFTER UPDATE ON %s BEGIN
+			%s SELECT %s, %d, OLD.rowid, %s WHERE OLD.rowid <> NEW.rowid;
+			%s VALUES (%s, %d, NEW.rowid, %s);
+		END`, quote(triggerPrefix+table+"_update"), quote(table),
+			insert, literal(table), pb.ChangeOperation_DELETE, now,
+			insert, literal(table), pb.ChangeOperation_UPDATE, now),
+		fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS %s AFTER DELETE ON %s BEGIN
+			%s VALUES (%s, %d, OLD.rowid, %s);
+		END`, quote(triggerPrefix+table+"_delete"), quote(table), insert, literal(table), pb.ChangeOperation_DELETE, now),
+	}
+	for _, statement := range statements {
+		if _, err := q.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("unable to create changelog trigger on %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// Disable drops the triggers & the changelog, the tokens of its consumers
+// are lost.
+func Disable(ctx context.Context, db *sql.DB) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT name FROM sqlite_schema WHERE type = 'trigger' AND name GLOB ?`, triggerPrefix+"*")
+	if err != nil {
+		return err
+	}
+	var triggers []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		triggers = append(triggers, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, name := range triggers {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DROP TRIGGER %s`, quote(name))); err != nil {
+			return err
+		}
+	}
+	for _, table := range []string{Table, stateTable} {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DROP TABLE IF EXISTS %s`, table)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Read returns at most limit changes logged after the token, in order. The
+// token of every change is its sequence.
+func Read(ctx context.Context, q querier, after uint64, tables []string, limit int) ([]*pb.ChangeEvent, error) {
+	enabled, err := Enabled(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return nil, ErrNotEnabled
+	}
+
+	if after > 0 {
+		var truncated uint64
+		err := q.QueryRowContext(ctx, fmt.Sprintf(`SELECT value FROM %s WHERE key = ?`, stateTable), truncatedKey).Scan(&truncated)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+		if after < truncated {
+			return nil, ErrTokenExpired
+		}
+	}
+
+	query := fmt.Sprintf(`SELECT seq, tbl, op, row_id FROM %s WHERE seq > ?`, Table)
+	args := []interface{}{after}
+	if len(tables) > 0 {
+		query += ` AND lower(tbl) IN (` + strings.TrimSuffix(strings.Repeat("lower(?), ", len(tables)), ", ") + `)`
+		for _, t := range tables {
+			args = append(args, t)
+		}
+	}
+	query += ` ORDER BY seq LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var events []*pb.ChangeEvent
+	for rows.Next() {
+		ev := &pb.ChangeEvent{}
+		var op int32
+		if err := rows.Scan(&ev.Token, &ev.Table, &op, &ev.Rowid); err != nil {
+			return nil, err
+		}
+		ev.Operation = pb.ChangeOperation(op)
+		events = append(events, ev)
+	}
+	return events, rows.Err()
+}
+
+// Compact applies the retention to the changelog, the tokens of the entries
+// deleted because of their age or count expire.
+func Compact(ctx context.Context, db *sql.DB, retention Retention) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	enabled, err := Enabled(ctx, tx)
+	if err != nil || !enabled {
+		return err
+	}
+
+	var truncated int64
+	if retention.MaxAge > 0 {
+		cutoff := time.Now().Add(-retention.MaxAge).UnixMilli()
+		var seq sql.NullInt64
+		err := tx.QueryRowContext(ctx, fmt.Sprintf(`SELECT max(seq) FROM %s WHERE ts < ?`, Table), cutoff).Scan(&seq)
+		if err != nil {
+			return err
+		}
+		truncated = max(truncated, seq.Int64)
+	}
+	if retention.MaxEntries > 0 {
+		var seq sql.NullInt64
+		err := tx.QueryRowContext(ctx, fmt.Sprintf(`SELECT seq FROM %s ORDER BY seq DESC LIMIT 1 OFFSET ?`, Table), retention.MaxEntries).Scan(&seq)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		truncated = max(truncated, seq.Int64)
+	}
+	if truncated > 0 {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE seq <= ?`, Table), truncated); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %s (key, value) VALUES (?, ?)
+			ON CONFLICT (key) DO UPDATE SET value = max(value, excluded.value)`, stateTable), truncatedKey, truncated)
+		if err != nil {
+			return err
+		}
+	}
+
+	if retention.Compact {
+		_, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %[1]s WHERE EXISTS (
+			SELECT 1 FROM %[1]s AS later
+			WHERE later.tbl = %[1]s.tbl AND later.row_id = %[1]s.row_id AND later.seq > %[1]s.seq
+		)`, Table))
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,154 @@
+package changes
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
+)
+
+// BufferSize is the number of events a subscription can fall behind by
+// before it's closed
+const BufferSize = 1024
+
+const internalPrefix = "_sqliteog_"
+
+var ErrOverflow = errors.New("watcher is too slow, changes were dropped")
+
+// Hub dispatches the committed changes of every database to its watchers
+type Hub struct {
+	mutex sync.RWMutex
+	subs  map[string]map[*Subscription]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		subs: map[string]map[*Subscription]struct{}{},
+	}
+}
+
+// Subscription receives the changes of a database on Events, which is
+// closed once the subscription is closed or overflows, see Err.
+type Subscription struct {
+	Events chan *pb.ChangeEvent
+
+	hub    *Hub
+	dbname string
+	// tables are lower case, every table is watched when empty
+	tables map[string]bool
+	values bool
+
+	mutex  sync.Mutex
+	closed bool
+	err    error
+}
+
+func (h *Hub) Subscribe(dbname string, tables []string, values bool) *Subscription {
+	sub := &Subscription{
+		Events: make(chan *pb.ChangeEvent, BufferSize),
+		hub:    h,
+		dbname: dbname,
+		tables: map[string]bool{},
+		values: values,
+	}
+	for _, t := range tables {
+		sub.tables[strings.ToLower(t)] = true
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if h.subs[dbname] == nil {
+		h.subs[dbname] = map[*Subscription]struct{}{}
+	}
+	h.subs[dbname][sub] = struct{}{}
+	return sub
+}
+
+// watches reports whether the subscription wants the changes of the table,
+// the internal _sqliteog_ tables are only watched when named.
+func (s *Subscription) watches(table string) bool {
+	table = strings.ToLower(table)
+	if len(s.tables) == 0 {
+		return !strings.HasPrefix(table, internalPrefix)
+	}
+	return s.tables[table]
+}
+
+// Watched reports whether the changes of the table have a watcher and
+// whether one of them wants the values of the rows.
+func (h *Hub) Watched(dbname, table string) (watched bool, values bool) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	for sub := range h.subs[dbname] {
+		if sub.watches(table) {
+			watched = true
+			values = values || sub.values
+		}
+	}
+	return
+}
+
+// Publish sends the events to the watchers of their table, a watcher whose
+// buffer is full is closed with ErrOverflow rather than blocking the others.
+func (h *Hub) Publish(dbname string, events []*pb.ChangeEvent) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	for sub := range h.subs[dbname] {
+		for _, ev := range events {
+			if !sub.watches(ev.GetTable()) {
+				continue
+			}
+			if !sub.values && ev.GetValues() != nil {
+				ev = &pb.ChangeEvent{
+					Table:     ev.GetTable(),
+					Operation: ev.GetOperation(),
+					Rowid:     ev.GetRowid(),
+				}
+			}
+			if !sub.send(ev) {
+				break
+			}
+		}
+	}
+}
+
+func (s *Subscription) send(ev *pb.ChangeEvent) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return false
+	}
+	select {
+	case s.Events <- ev:
+		return true
+	default:
+		s.err = ErrOverflow
+		s.closed = true
+		close(s.Events)
+		return false
+	}
+}
+
+// Err returns the reason the subscription was closed by the hub
+func (s *Subscription) Err() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.err
+}
+
+func (s *Subscription) Close() {
+	s.hub.mutex.Lock()
+	delete(s.hub.subs[s.dbname], s)
+	if len(s.hub.subs[s.dbname]) == 0 {
+		delete(s.hub.subs, s.dbname)
+	}
+	s.hub.mutex.Unlock()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.Events)
+	}
+}
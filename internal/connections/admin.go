@@ -0,0 +1,253 @@
+package connections
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"golang.org/x/exp/slog"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
+	"github.com/aousomran/sqlite-og/internal/dbwrapper"
+)
+
+var (
+	ErrDBExists     = errors.New("database already exists")
+	ErrSessionsOpen = errors.New("database has open sessions")
+)
+
+// journalSuffixes are the files sqlite keeps next to a database
+var journalSuffixes = []string{"-journal", "-wal", "-shm"}
+
+// adminDataDir resolves the databases managed by the admin, which may
+// create them whatever the clients may do
+func (m *Manager) adminDataDir() dbwrapper.DataDir {
+	return dbwrapper.DataDir{Root: m.DataDir.Root, Create: true}
+}
+
+// resolveFile returns the file of a database, in memory databases are not
+// files
+func (m *Manager) resolveFile(dbname string) (string, error) {
+	file, err := m.adminDataDir().Resolve(dbname)
+	if err != nil {
+		return "", err
+	}
+	if file == ":memory:" {
+		return "", fmt.Errorf("%w: in memory databases are not files", dbwrapper.ErrInvalidDBName)
+	}
+	return file, nil
+}
+
+// sessionCount returns the number of sessions open on the database file
+func (m *Manager) sessionCount(file string) int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	count := 0
+	for _, cnx := range m.CnxMap {
+		if cnx.Name == file {
+			count++
+		}
+	}
+	return count
+}
+
+// closeChangelog closes the changelog connection of the database file, it's
+// reopened on next use
+func (m *Manager) closeChangelog(file string) {
+	m.changelogMutex.Lock()
+	defer m.changelogMutex.Unlock()
+	if db, ok := m.changelogs[file]; ok {
+		if err := db.Close(); err != nil {
+			slog.Error("cannot close changelog", "error", err, "dbname", file)
+		}
+		delete(m.changelogs, file)
+	}
+}
+
+// DatabaseInfo describes a database file, it's read without locking it
+func (m *Manager) DatabaseInfo(ctx context.Context, file string) (*pb.DatabaseInfo, error) {
+	stat, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+	info := &pb.DatabaseInfo{
+		DbName:   m.DataDir.Name(file),
+		Size:     stat.Size(),
+		Sessions: int32(m.sessionCount(file)),
+	}
+	db, err := sql.Open("sqlite3", dbwrapper.ReadOnlyURI(file))
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	for pragma, dest := range map[string]interface{}{
+		"page_count":   &info.PageCount,
+		"page_size":    &info.PageSize,
+		"journal_mode": &info.JournalMode,
+	} {
+		if err := conn.QueryRowContext(ctx, "PRAGMA "+pragma).Scan(dest); err != nil {
+			return nil, fmt.Errorf("cannot read %s of %s: %w", pragma, info.DbName, err)
+		}
+	}
+	return info, nil
+}
+
+// ListDatabases describes the databases of the data directory, the files
+// that are not databases are skipped.
+func (m *Manager) ListDatabases(ctx context.Context) ([]*pb.DatabaseInfo, error) {
+	files, err := m.DataDir.List()
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]*pb.DatabaseInfo, 0, len(files))
+	for _, file := range files {
+		info, err := m.DatabaseInfo(ctx, file)
+		if err != nil {
+			slog.WarnContext(ctx, "skipping database", "error", err, "dbname", file)
+			continue
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// CreateDatabase creates an empty database, or a copy of the template
+// database when it's set. The template is copied with VACUUM INTO, which
+// reads a consistent snapshot while it's being written.
+func (m *Manager) CreateDatabase(ctx context.Context, dbname, template string) (*pb.DatabaseInfo, error) {
+	file, err := m.resolveFile(dbname)
+	if err != nil {
+		return nil, err
+	}
+	if template == "" {
+		f, err := os.OpenFile(file, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("%w: %s", ErrDBExists, dbname)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := f.Close(); err != nil {
+			return nil, err
+		}
+	} else {
+		source, err := m.resolveFile(template)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(source); errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: template %s", dbwrapper.ErrDBNotFound, template)
+		}
+		if _, err := os.Lstat(file); err == nil {
+			return nil, fmt.Errorf("%w: %s", ErrDBExists, dbname)
+		}
+		db, err := sql.Open("sqlite3", dbwrapper.ReadOnlyURI(source))
+		if err != nil {
+			return nil, err
+		}
+		defer db.Close()
+		if _, err := db.ExecContext(ctx, "VACUUM INTO ?", file); err != nil {
+			return nil, fmt.Errorf("cannot copy template %s: %w", template, err)
+		}
+	}
+	slog.InfoContext(ctx, "database created", "dbname", dbname, "template", template)
+	return m.DatabaseInfo(ctx, file)
+}
+
+// DropDatabase deletes a database & its journal, the sessions open on it
+// are closed when force is set, it's refused otherwise.
+func (m *Manager) DropDatabase(ctx context.Context, dbname string, force bool) error {
+	file, err := m.resolveFile(dbname)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(file); errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %s", dbwrapper.ErrDBNotFound, dbname)
+	}
+	m.filesMutex.Lock()
+	defer m.filesMutex.Unlock()
+	if err := m.closeSessions(ctx, file, force); err != nil {
+		return err
+	}
+	m.closeChangelog(file)
+	if err := os.Remove(file); err != nil {
+		return err
+	}
+	for _, suffix := range journalSuffixes {
+		if err := os.Remove(file + suffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	slog.InfoContext(ctx, "database dropped", "dbname", dbname, "force", force)
+	return nil
+}
+
+// RenameDatabase renames a database & its journal, it's refused while
+// sessions are open on it.
+func (m *Manager) RenameDatabase(ctx context.Context, dbname, newName string) (*pb.DatabaseInfo, error) {
+	file, err := m.resolveFile(dbname)
+	if err != nil {
+		return nil, err
+	}
+	newFile, err := m.resolveFile(newName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(file); errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", dbwrapper.ErrDBNotFound, dbname)
+	}
+	if _, err := os.Lstat(newFile); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrDBExists, newName)
+	}
+	m.filesMutex.Lock()
+	defer m.filesMutex.Unlock()
+	if err := m.closeSessions(ctx, file, false); err != nil {
+		return nil, err
+	}
+	m.closeChangelog(file)
+	if err := os.Rename(file, newFile); err != nil {
+		return nil, err
+	}
+	for _, suffix := range journalSuffixes {
+		err := os.Rename(file+suffix, newFile+suffix)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	slog.InfoContext(ctx, "database renamed", "dbname", dbname, "new_dbname", newName)
+	return m.DatabaseInfo(ctx, newFile)
+}
+
+// closeSessions closes the sessions open on the database file if force is
+// set, it fails with ErrSessionsOpen otherwise
+func (m *Manager) closeSessions(ctx context.Context, file string, force bool) error {
+	m.mutex.Lock()
+	var open []*dbwrapper.DBWrapper
+	for id, cnx := range m.CnxMap {
+		if cnx.Name != file {
+			continue
+		}
+		if !force {
+			m.mutex.Unlock()
+			return fmt.Errorf("%w: %s", ErrSessionsOpen, m.DataDir.Name(file))
+		}
+		open = append(open, cnx)
+		delete(m.CnxMap, id)
+	}
+	m.mutex.Unlock()
+
+	for _, cnx := range open {
+		if err := cnx.Close(); err != nil {
+			slog.WarnContext(ctx, "cannot close session", "error", err, "dbname", cnx.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package connections
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"golang.org/x/exp/slog"
+
+	"github.com/aousomran/sqlite-og/internal/dbwrapper"
+)
+
+// The backup copies backupStepPages pages at a time & pauses between the
+// steps, the database is only locked during a step so the sessions keep
+// writing. sqlite restarts the backup when another connection writes to the
+// database, after backupMaxRestarts the rest is copied in one step.
+const (
+	backupStepPages   = 256
+	backupMaxRestarts = 5
+)
+
+var backupStepPause = 10 * time.Millisecond
+
+// Backup copies a consistent snapshot of the database to the dest file with
+// sqlite's online backup API, dest must not be in use.
+func (m *Manager) Backup(ctx context.Context, dbname, dest string) error {
+	file, err := m.resolveFile(dbname)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(file); errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %s", dbwrapper.ErrDBNotFound, dbname)
+	}
+
+	src, err := sql.Open("sqlite3", dbwrapper.ReadOnlyURI(file))
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := sql.Open("sqlite3", dest)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+	dstConn, err := dst.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer dstConn.Close()
+
+	start := time.Now()
+	err = dstConn.Raw(func(dstDriverConn interface{}) error {
+		return srcConn.Raw(func(srcDriverConn interface{}) error {
+			return backup(ctx, dstDriverConn.(*sqlite3.SQLiteConn), srcDriverConn.(*sqlite3.SQLiteConn))
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("cannot back up %s: %w", dbname, err)
+	}
+	slog.InfoContext(ctx, "database backed up", "dbname", dbname, "duration", time.Since(start))
+	return nil
+}
+
+// backup copies the main database of src to dst step by step
+func backup(ctx context.Context, dst, src *sqlite3.SQLiteConn) error {
+	b, err := dst.Backup("main", src, "main")
+	if err != nil {
+		return err
+	}
+	restarts, remaining := 0, -1
+	for {
+		pages := backupStepPages
+		if restarts >= backupMaxRestarts {
+			pages = -1
+		}
+		done, err := b.Step(pages)
+		if err != nil || done {
+			if errFinish := b.Finish(); err == nil {
+				err = errFinish
+			}
+			return err
+		}
+		// the remaining pages grow when the backup restarted
+		if remaining >= 0 && b.Remaining() > remaining {
+			restarts++
+		}
+		remaining = b.Remaining()
+
+		select {
+		case <-ctx.Done():
+			_ = b.Finish()
+			return ctx.Err()
+		case <-time.After(backupStepPause):
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package connections
+
+import (
+	"context"
+	"database/sql"
+
+	"golang.org/x/exp/slog"
+
+	"github.com/aousomran/sqlite-og/internal/changelog"
+	"github.com/aousomran/sqlite-og/internal/changes"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
+)
+
+// changelogBusyTimeout lets the changelog connections wait for the writing
+// sessions rather than failing with SQLITE_BUSY
+const changelogBusyTimeout = "5000"
+
+// changelogDB returns the connection managing the changelog of a database,
+// it's opened on first use & kept until the manager is closed.
+func (m *Manager) changelogDB(dbname string) (*sql.DB, string, error) {
+	dbname, err := m.DataDir.Resolve(dbname)
+	if err != nil {
+		return nil, dbname, err
+	}
+	if dbname == ":memory:" {
+		return nil, dbname, ErrInMemory
+	}
+	db, err := m.changelogFile(dbname)
+	return db, dbname, err
+}
+
+// changelogFile returns the changelog connection of a resolved database file
+func (m *Manager) changelogFile(file string) (*sql.DB, error) {
+	m.changelogMutex.Lock()
+	defer m.changelogMutex.Unlock()
+	if db, ok := m.changelogs[file]; ok {
+		return db, nil
+	}
+	db, err := sql.Open("sqlite3", file+"?_busy_timeout="+changelogBusyTimeout)
+	if err != nil {
+		return nil, err
+	}
+	m.changelogs[file] = db
+	return db, nil
+}
+
+func (m *Manager) closeChangelogs() {
+	m.changelogMutex.Lock()
+	defer m.changelogMutex.Unlock()
+	for dbname, db := range m.changelogs {
+		if err := db.Close(); err != nil {
+			slog.Error("cannot close changelog", "error", err, "dbname", dbname)
+		}
+		delete(m.changelogs, dbname)
+	}
+}
+
+// EnableChangelog logs the changes of the tables, or of every table when
+// none is given, to the database's changelog. Changes are logged in the
+// transaction that makes them, whichever process makes them.
+func (m *Manager) EnableChangelog(ctx context.Context, dbname string, tables []string) error {
+	db, dbname, err := m.changelogDB(dbname)
+	if err != nil {
+		return err
+	}
+	if err := changelog.Enable(ctx, db, tables); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "changelog enabled", "dbname", dbname, "tables", tables)
+	return nil
+}
+
+// DisableChangelog drops the changelog of the database
+func (m *Manager) DisableChangelog(ctx context.Context, dbname string) error {
+	db, dbname, err := m.changelogDB(dbname)
+	if err != nil {
+		return err
+	}
+	if err := changelog.Disable(ctx, db); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "changelog disabled", "dbname", dbname)
+	return nil
+}
+
+// ReadChangelog returns at most limit changes logged after the token
+func (m *Manager) ReadChangelog(ctx context.Context, dbname string, after uint64, tables []string, limit int) ([]*pb.ChangeEvent, error) {
+	db, _, err := m.changelogDB(dbname)
+	if err != nil {
+		return nil, err
+	}
+	return changelog.Read(ctx, db, after, tables, limit)
+}
+
+// WatchChangelog subscribes to the entries the sessions log to the
+// changelog, it only signals that new entries can be read.
+func (m *Manager) WatchChangelog(dbname string) (*changes.Subscription, error) {
+	return m.Watch(dbname, []string{changelog.Table}, false)
+}
+
+// CompactChangelogs applies ChangelogRetention to the changelogs of the
+// databases that have sessions or changelog consumers.
+func (m *Manager) CompactChangelogs(ctx context.Context) {
+	names := map[string]bool{}
+	for _, dbname := range m.Names() {
+		names[dbname] = true
+	}
+	m.changelogMutex.Lock()
+	for dbname := range m.changelogs {
+		names[dbname] = true
+	}
+	m.changelogMutex.Unlock()
+
+	for dbname := range names {
+		if dbname == ":memory:" {
+			continue
+		}
+		db, err := m.changelogFile(dbname)
+		if err == nil {
+			err = changelog.Compact(ctx, db, m.ChangelogRetention)
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "cannot compact changelog", "error", err, "dbname", dbname)
+		}
+	}
+}
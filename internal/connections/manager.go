@@ -1,47 +1,95 @@
 package connections
 
 import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"github.com/aousomran/sqlite-og/internal/callback"
+	"github.com/aousomran/sqlite-og/internal/changelog"
+	"github.com/aousomran/sqlite-og/internal/changes"
 	"github.com/aousomran/sqlite-og/internal/dbwrapper"
-	"github.com/google/uuid"
 	"golang.org/x/exp/slog"
-	"strings"
+	"sort"
 	"sync"
+	"time"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
 )
 
 type Manager struct {
 	mutex  sync.RWMutex
 	CnxMap map[string]*dbwrapper.DBWrapper
+	// CallbackTimeout bounds every client callback invoked by a statement
+	CallbackTimeout time.Duration
+	// Changes dispatches the changes committed by the sessions to watchers
+	Changes *changes.Hub
+	// ChangelogRetention bounds the changelogs, see CompactChangelogs
+	ChangelogRetention changelog.Retention
+	// DataDir resolves the names of the databases to their file
+	DataDir dbwrapper.DataDir
+	// SessionIdleTimeout is how long a session may stay unused before
+	// ReapSessions closes it, it's never closed when it's 0
+	SessionIdleTimeout time.Duration
+
+	// filesMutex guards the database files, the sessions are opened under
+	// the read lock & the files are dropped, renamed or replaced under the
+	// write lock so that no session opens a file that is going away
+	filesMutex sync.RWMutex
+
+	// changelogMutex guards changelogs, the connections reading & managing
+	// the changelog of every database, keyed by file name
+	changelogMutex sync.Mutex
+	changelogs     map[string]*sql.DB
 }
 
+var (
+	ErrInMemory = errors.New("in memory databases cannot be watched")
+	// ErrNotOwner is returned when a client uses a session it did not open
+	ErrNotOwner = errors.New("the session belongs to another client")
+	// ErrNoSession is returned for the sessions that were closed or never
+	// opened
+	ErrNoSession = errors.New("connection does not exist")
+)
+
+// sessionIDBytes is the entropy of the session ids, they are the only
+// secret of unauthenticated sessions so they must not be guessable
+const sessionIDBytes = 32
+
 func NewManager() *Manager {
 	return &Manager{
-		mutex:  sync.RWMutex{},
-		CnxMap: map[string]*dbwrapper.DBWrapper{},
+		mutex:           sync.RWMutex{},
+		CnxMap:          map[string]*dbwrapper.DBWrapper{},
+		CallbackTimeout: callback.DefaultTimeout,
+		Changes:         changes.NewHub(),
+		changelogs:      map[string]*sql.DB{},
+		DataDir:         dbwrapper.DataDir{Create: true},
 	}
 }
 
 func (m *Manager) addConnection(id string, cnx *dbwrapper.DBWrapper) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
-	_, ok := m.CnxMap[id]
-	if ok {
-		slog.Warn("connection already exists, replacing", "id", id)
-	}
 	m.CnxMap[id] = cnx
 }
 
-func (m *Manager) GetConnection(id string) (*dbwrapper.DBWrapper, error) {
+// GetConnection returns the session, if the owner opened it
+func (m *Manager) GetConnection(id, owner string) (*dbwrapper.DBWrapper, error) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 	cnx, ok := m.CnxMap[id]
 	if !ok {
-		return nil, fmt.Errorf("connection with id `%s` does not exist", id)
+		return nil, ErrNoSession
 	}
 	if cnx == nil {
 		return nil, fmt.Errorf("dbwrapper in map is nil")
 	}
+	if cnx.Owner != owner {
+		return nil, ErrNotOwner
+	}
+	cnx.Touch()
 	return cnx, nil
 }
 
@@ -51,11 +99,37 @@ func (m *Manager) DeleteConnection(id string) {
 	delete(m.CnxMap, id)
 }
 
-func (m *Manager) Connect(dbname string, functions []string, aggregators []string) (string, error) {
-	id := strings.Split(uuid.New().String(), "-")[0]
-	channels := callback.New()
-	cnx := dbwrapper.New(dbname, id, functions, channels)
-	err := cnx.Open(id)
+// newSessionID returns a random session id, safe to use in URLs & metadata
+func newSessionID() (string, error) {
+	b := make([]byte, sessionIDBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Connect opens a session on the database for the owner, the client using
+// it must be the same owner. Every access applies to the session. The
+// databases it attaches must be in the data directory & be fully granted by
+// authorize unless it's nil, see attachFunc.
+func (m *Manager) Connect(owner, dbname string, callbacks dbwrapper.Callbacks, access []*pb.Access, authorize func(dbname string) (*pb.Access, error), options map[string]string) (string, error) {
+	m.filesMutex.RLock()
+	defer m.filesMutex.RUnlock()
+	dbname, err := m.DataDir.Resolve(dbname)
+	if err != nil {
+		return "", err
+	}
+	id, err := newSessionID()
+	if err != nil {
+		return "", err
+	}
+	channels := callback.New(m.CallbackTimeout)
+	cnx, err := dbwrapper.New(dbname, callbacks, access, m.attachFunc(authorize), options, channels, m.Changes)
+	if err != nil {
+		return "", err
+	}
+	cnx.Owner = owner
+	err = cnx.Open()
 	if err != nil {
 		return "", err
 	}
@@ -63,15 +137,84 @@ func (m *Manager) Connect(dbname string, functions []string, aggregators []strin
 	return id, nil
 }
 
+// attachFunc checks the databases attached by a session, they are not
+// restricted when there is neither a root nor a policy
+func (m *Manager) attachFunc(authorize func(dbname string) (*pb.Access, error)) dbwrapper.AttachFunc {
+	if m.DataDir.Root == "" && authorize == nil {
+		return nil
+	}
+	return func(file string) (*pb.Access, error) {
+		file, err := m.DataDir.ResolveFile(file)
+		// in memory databases are private to the session
+		if err != nil || authorize == nil || file == ":memory:" {
+			return nil, err
+		}
+		return authorize(m.DataDir.Name(file))
+	}
+}
+
+// Watch subscribes to the changes committed to the database by every
+// session, in memory databases are private to their session.
+func (m *Manager) Watch(dbname string, tables []string, values bool) (*changes.Subscription, error) {
+	dbname, err := m.DataDir.Resolve(dbname)
+	if err != nil {
+		return nil, err
+	}
+	if dbname == ":memory:" {
+		return nil, ErrInMemory
+	}
+	return m.Changes.Subscribe(dbname, tables, values), nil
+}
+
+// ReapSessions closes the sessions unused for longer than
+// SessionIdleTimeout, e.g. the sessions of the clients that went away
+// without closing them, their transaction is rolled back. Sessions running
+// a statement are never idle.
+func (m *Manager) ReapSessions(ctx context.Context) {
+	if m.SessionIdleTimeout <= 0 {
+		return
+	}
+	m.mutex.Lock()
+	var idle []*dbwrapper.DBWrapper
+	for id, cnx := range m.CnxMap {
+		if cnx.Idle() > m.SessionIdleTimeout {
+			idle = append(idle, cnx)
+			delete(m.CnxMap, id)
+		}
+	}
+	m.mutex.Unlock()
+
+	for _, cnx := range idle {
+		slog.InfoContext(ctx, "closing idle session", "dbname", cnx.Name, "idle", cnx.Idle())
+		if err := cnx.Close(); err != nil {
+			slog.WarnContext(ctx, "cannot close session", "error", err, "dbname", cnx.Name)
+		}
+	}
+}
+
+// Names returns the databases of the open sessions, once per session &
+// sorted. The session ids are secrets, they're never listed.
+func (m *Manager) Names() []string {
+	m.mutex.RLock()
+	names := make([]string, 0, len(m.CnxMap))
+	for _, cnx := range m.CnxMap {
+		names = append(names, cnx.Name)
+	}
+	m.mutex.RUnlock()
+	sort.Strings(names)
+	return names
+}
+
 func (m *Manager) Close() error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 	var err error
-	for id, cnx := range m.CnxMap {
+	for _, cnx := range m.CnxMap {
 		err = cnx.Close()
 		if err != nil {
-			slog.Error("cannot close connection", "error", err, "cnx_id", id, "dbname", cnx.Name)
+			slog.Error("cannot close connection", "error", err, "dbname", cnx.Name)
 		}
 	}
+	m.closeChangelogs()
 	return err
 }
@@ -0,0 +1,104 @@
+package connections
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/exp/slog"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
+)
+
+var ErrIntegrity = errors.New("database integrity check failed")
+
+// Restore replaces the database, or creates it, with the file read from r.
+// The file is written next to the database & checked with PRAGMA
+// integrity_check, then renamed over the database so that its sessions
+// never see a partial file. It's refused while sessions are open on the
+// database unless force is set, they are then closed once their running
+// statements are done & their transaction is rolled back.
+func (m *Manager) Restore(ctx context.Context, dbname string, force bool, r io.Reader) (*pb.DatabaseInfo, error) {
+	file, err := m.resolveFile(dbname)
+	if err != nil {
+		return nil, err
+	}
+	// the name is hidden & has no .db suffix so that it's never listed
+	tmp, err := os.CreateTemp(filepath.Dir(file), "."+filepath.Base(file)+".restore-*")
+	if err != nil {
+		return nil, err
+	}
+	defer removeDatabase(tmp.Name())
+	_, err = io.Copy(tmp, r)
+	if errClose := tmp.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := integrityCheck(ctx, tmp.Name()); err != nil {
+		return nil, err
+	}
+
+	m.filesMutex.Lock()
+	defer m.filesMutex.Unlock()
+	if err := m.closeSessions(ctx, file, force); err != nil {
+		return nil, err
+	}
+	m.closeChangelog(file)
+	// the journal of the old file must not be applied to the new one
+	for _, suffix := range journalSuffixes {
+		if err := os.Remove(file + suffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "database restored", "dbname", dbname, "force", force)
+	return m.DatabaseInfo(ctx, file)
+}
+
+// integrityCheck checks that the file is a sound database
+func integrityCheck(ctx context.Context, file string) error {
+	db, err := sql.Open("sqlite3", file)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	rows, err := db.QueryContext(ctx, "PRAGMA integrity_check")
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrIntegrity, err)
+	}
+	defer rows.Close()
+	var problems []string
+	for rows.Next() {
+		var problem string
+		if err := rows.Scan(&problem); err != nil {
+			return err
+		}
+		problems = append(problems, problem)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrIntegrity, err)
+	}
+	if len(problems) != 1 || problems[0] != "ok" {
+		return fmt.Errorf("%w: %s", ErrIntegrity, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// removeDatabase removes a database file & its journal if they exist
+func removeDatabase(file string) {
+	for _, suffix := range append([]string{""}, journalSuffixes...) {
+		if err := os.Remove(file + suffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			slog.Warn("cannot remove file", "error", err, "file", file+suffix)
+		}
+	}
+}
@@ -0,0 +1,458 @@
+package dbwrapper
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mattn/go-sqlite3"
+	"golang.org/x/exp/slog"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
+	"github.com/aousomran/sqlite-og/internal/changelog"
+)
+
+// authorizer enforces the access of a session, sqlite calls it while
+// preparing every statement, including the statements of triggers & views.
+// Names are lower case.
+type authorizer struct {
+	mode           pb.AccessMode
+	allowedTables  map[string]bool
+	deniedTables   map[string]bool
+	allowedColumns map[string]map[string]bool
+	deniedColumns  map[string]bool
+}
+
+// readPragmas take a table or an index as argument without changing anything
+var readPragmas = map[string]bool{
+	"table_info":        true,
+	"table_xinfo":       true,
+	"index_info":        true,
+	"index_xinfo":       true,
+	"index_list":        true,
+	"foreign_key_list":  true,
+	"foreign_key_check": true,
+	"integrity_check":   true,
+	"quick_check":       true,
+}
+
+// sessionPragmas only change the behaviour of the session's connection,
+// query_only is set by the read only transactions
+var sessionPragmas = map[string]bool{
+	"busy_timeout":        true,
+	"cache_size":          true,
+	"case_sensitive_like": true,
+	"foreign_keys":        true,
+	"query_only":          true,
+	"recursive_triggers":  true,
+}
+
+// ddlOperations change the schema of a database
+var ddlOperations = map[int]bool{
+	sqlite3.SQLITE_CREATE_INDEX:   true,
+	sqlite3.SQLITE_CREATE_TABLE:   true,
+	sqlite3.SQLITE_CREATE_TRIGGER: true,
+	sqlite3.SQLITE_CREATE_VIEW:    true,
+	sqlite3.SQLITE_CREATE_VTABLE:  true,
+	sqlite3.SQLITE_DROP_INDEX:     true,
+	sqlite3.SQLITE_DROP_TABLE:     true,
+	sqlite3.SQLITE_DROP_TRIGGER:   true,
+	sqlite3.SQLITE_DROP_VIEW:      true,
+	sqlite3.SQLITE_DROP_VTABLE:    true,
+	sqlite3.SQLITE_ALTER_TABLE:    true,
+	sqlite3.SQLITE_REINDEX:        true,
+	sqlite3.SQLITE_ANALYZE:        true,
+}
+
+// newAuthorizer returns nil when the access is not restricted
+func newAuthorizer(access *pb.Access) *authorizer {
+	if access == nil {
+		return nil
+	}
+	a := &authorizer{
+		mode:           access.GetMode(),
+		allowedTables:  names(access.GetAllowedTables()),
+		deniedTables:   names(access.GetDeniedTables()),
+		allowedColumns: map[string]map[string]bool{},
+		deniedColumns:  names(access.GetDeniedColumns()),
+	}
+	for _, column := range access.GetAllowedColumns() {
+		table, col, _ := strings.Cut(strings.ToLower(column), ".")
+		if a.allowedColumns[table] == nil {
+			a.allowedColumns[table] = map[string]bool{}
+		}
+		a.allowedColumns[table][col] = true
+	}
+	if a.mode == pb.AccessMode_READ_WRITE && !a.restrictsTables() {
+		return nil
+	}
+	return a
+}
+
+// authorizers enforce several accesses, e.g. the one requested by the
+// client & the one granted by the server's policy, an operation must be
+// allowed by all of them.
+type authorizers []*authorizer
+
+// newAuthorizers returns nil when none of the accesses is restricted
+func newAuthorizers(access []*pb.Access) authorizers {
+	var auths authorizers
+	for _, a := range access {
+		if auth := newAuthorizer(a); auth != nil {
+			auths = append(auths, auth)
+		}
+	}
+	return auths
+}
+
+func (auths authorizers) authorize(op int, arg1, arg2, database string) int {
+	for _, a := range auths {
+		if result := a.authorize(op, arg1, arg2, database); result != sqlite3.SQLITE_OK {
+			return result
+		}
+	}
+	return sqlite3.SQLITE_OK
+}
+
+func (auths authorizers) pragmaAllowed(pragma, arg string) bool {
+	for _, a := range auths {
+		if !a.pragmaAllowed(pragma, arg) {
+			return false
+		}
+	}
+	return true
+}
+
+// readOnly reports whether the database has to be opened read only
+func (auths authorizers) readOnly() bool {
+	for _, a := range auths {
+		if a.mode == pb.AccessMode_READ_ONLY {
+			return true
+		}
+	}
+	return false
+}
+
+func names(list []string) map[string]bool {
+	m := make(map[string]bool, len(list))
+	for _, name := range list {
+		m[strings.ToLower(name)] = true
+	}
+	return m
+}
+
+func allow(ok bool) int {
+	if ok {
+		return sqlite3.SQLITE_OK
+	}
+	return sqlite3.SQLITE_DENY
+}
+
+// restrictsTables reports whether the access lists tables or columns
+func (a *authorizer) restrictsTables() bool {
+	return len(a.allowedTables) > 0 || len(a.deniedTables) > 0 ||
+		len(a.allowedColumns) > 0 || len(a.deniedColumns) > 0
+}
+
+// readAllowed reports whether the table may be read, sqlite's own tables are
+// always readable as they are read to prepare statements. The changelog logs
+// the rows of every table, the sessions restricted to some tables read their
+// changes from the Changes RPC, which applies their policy.
+func (a *authorizer) readAllowed(table, column string) bool {
+	if internalTable(table) {
+		return a.tableAllowed(table)
+	}
+	return a.tableAllowed(table) && a.columnAllowed(table, column)
+}
+
+// writeAllowed reports whether the table may be written by op. sqlite writes
+// its schema tables while running DDL, which is authorized on its own, the
+// statements of restricted sessions cannot write them as writable_schema is
+// denied. The changelog triggers append to the changelog, the authorizer
+// cannot tell them from the session's own statements, see changelogGuard.
+func (a *authorizer) writeAllowed(op int, table string) bool {
+	table = strings.ToLower(table)
+	switch {
+	case table == "sqlite_master" || table == "sqlite_temp_master":
+		return true
+	case table == changelog.Table && op == sqlite3.SQLITE_INSERT:
+		return true
+	case internalTable(table):
+		return !a.restrictsTables()
+	}
+	return a.tableAllowed(table)
+}
+
+// internalTable reports whether the table belongs to sqlite or to sqliteog
+func internalTable(table string) bool {
+	table = strings.ToLower(table)
+	return strings.HasPrefix(table, "sqlite_") || strings.HasPrefix(table, "_sqliteog_")
+}
+
+// tableAllowed reports whether the table may be accessed. sqlite refuses
+// the DDL of its own tables, it creates sqlite_sequence & sqlite_stat1
+// itself, the internal _sqliteog_ tables are only managed by the sessions
+// with access to every table.
+func (a *authorizer) tableAllowed(table string) bool {
+	table = strings.ToLower(table)
+	if strings.HasPrefix(table, "sqlite_") {
+		return true
+	}
+	if strings.HasPrefix(table, "_sqliteog_") {
+		return !a.restrictsTables()
+	}
+	if a.deniedTables[table] {
+		return false
+	}
+	return len(a.allowedTables) == 0 || a.allowedTables[table]
+}
+
+// columnAllowed reports whether a column of an allowed table may be read or
+// updated, an empty column is the table itself (e.g. SELECT count(*))
+func (a *authorizer) columnAllowed(table, column string) bool {
+	if column == "" {
+		return true
+	}
+	table, column = strings.ToLower(table), strings.ToLower(column)
+	if a.deniedColumns[table+"."+column] {
+		return false
+	}
+	allowed, ok := a.allowedColumns[table]
+	return !ok || allowed[column]
+}
+
+// triggerAllowed reports whether the triggers of the table may be created or
+// dropped. The sessions restricted to some tables cannot manage triggers, a
+// trigger could write the changelog in their name & dropping the changelog
+// triggers would hide their changes.
+func (a *authorizer) triggerAllowed(table string) bool {
+	return !a.restrictsTables() && a.tableAllowed(table)
+}
+
+// writable reports whether the mode allows writing to the database, TEMP
+// objects are private to the session so they can always be written.
+func (a *authorizer) writable(database string) bool {
+	return a.mode != pb.AccessMode_READ_ONLY || database == "temp"
+}
+
+// authorize is sqlite's authorizer callback, the meaning of arg1 & arg2
+// depends on the operation, database is the name of the database it applies
+// to (main, temp or an attached database).
+func (a *authorizer) authorize(op int, arg1, arg2, database string) int {
+	switch op {
+	case sqlite3.SQLITE_READ:
+		return allow(a.readAllowed(arg1, arg2))
+	case sqlite3.SQLITE_UPDATE:
+		return allow(a.writable(database) && a.writeAllowed(op, arg1) && a.columnAllowed(arg1, arg2))
+	case sqlite3.SQLITE_INSERT, sqlite3.SQLITE_DELETE:
+		return allow(a.writable(database) && a.writeAllowed(op, arg1))
+	case sqlite3.SQLITE_CREATE_TEMP_INDEX, sqlite3.SQLITE_DROP_TEMP_INDEX:
+		return allow(a.tableAllowed(arg2))
+	case sqlite3.SQLITE_CREATE_TEMP_TRIGGER, sqlite3.SQLITE_DROP_TEMP_TRIGGER:
+		return allow(a.triggerAllowed(arg2))
+	case sqlite3.SQLITE_CREATE_TEMP_TABLE, sqlite3.SQLITE_CREATE_TEMP_VIEW,
+		sqlite3.SQLITE_DROP_TEMP_TABLE, sqlite3.SQLITE_DROP_TEMP_VIEW:
+		return allow(a.tableAllowed(arg1))
+	case sqlite3.SQLITE_ATTACH, sqlite3.SQLITE_DETACH:
+		// an attached database would escape the mode & the lists
+		return sqlite3.SQLITE_DENY
+	case sqlite3.SQLITE_PRAGMA:
+		return allow(a.pragmaAllowed(strings.ToLower(arg1), arg2))
+	}
+	if ddlOperations[op] {
+		if a.mode != pb.AccessMode_READ_WRITE {
+			return sqlite3.SQLITE_DENY
+		}
+		switch op {
+		case sqlite3.SQLITE_CREATE_INDEX, sqlite3.SQLITE_DROP_INDEX:
+			return allow(a.tableAllowed(arg2))
+		case sqlite3.SQLITE_CREATE_TRIGGER, sqlite3.SQLITE_DROP_TRIGGER:
+			return allow(a.triggerAllowed(arg2))
+		case sqlite3.SQLITE_ALTER_TABLE:
+			return allow(a.tableAllowed(arg2))
+		case sqlite3.SQLITE_REINDEX:
+			return sqlite3.SQLITE_OK
+		default:
+			return allow(a.tableAllowed(arg1))
+		}
+	}
+	return sqlite3.SQLITE_OK
+}
+
+// pragmaAllowed lets every session read pragmas, restricted sessions can only
+// set the pragmas of their own connection, e.g. writable_schema would let
+// them rewrite the schema of the tables they are denied.
+func (a *authorizer) pragmaAllowed(pragma, arg string) bool {
+	if readPragmas[pragma] {
+		return arg == "" || a.tableAllowed(arg)
+	}
+	return arg == "" || sessionPragmas[pragma]
+}
+
+// AttachFunc checks a database file a session attaches, the file is the
+// target of the ATTACH or VACUUM INTO as sqlite opens it. It returns the
+// access granted on the database.
+type AttachFunc func(file string) (*pb.Access, error)
+
+// vacuumStatement is a plain VACUUM, which attaches a temporary database
+var vacuumStatement = regexp.MustCompile(`(?i)^\s*vacuum(\s+\w+)?\s*;?\s*$`)
+
+// sandbox keeps the databases a session attaches, VACUUM INTO attaches its
+// target too, in the databases the session may open. The target must be
+// known when the statement is prepared, the targets bound to parameters or
+// built by expressions are empty & denied, except the temporary database
+// of a plain VACUUM.
+type sandbox struct {
+	attach AttachFunc
+	// vacuum is set while the session runs a plain VACUUM
+	vacuum atomic.Bool
+}
+
+func newSandbox(attach AttachFunc) *sandbox {
+	if attach == nil {
+		return nil
+	}
+	return &sandbox{attach: attach}
+}
+
+func (s *sandbox) authorize(op int, file string) int {
+	if s == nil || op != sqlite3.SQLITE_ATTACH {
+		return sqlite3.SQLITE_OK
+	}
+	if file == "" {
+		return allow(s.vacuum.Load())
+	}
+	access, err := s.attach(file)
+	if err != nil {
+		slog.Warn("attach denied", "file", file, "error", err)
+		return sqlite3.SQLITE_DENY
+	}
+	// the mode & the lists of the access would not apply to the attached
+	// database
+	return allow(newAuthorizer(access) == nil)
+}
+
+// run lets the statement attach the temporary database of a plain VACUUM
+// until the returned func is called
+func (s *sandbox) run(query string) func() {
+	if s == nil || !vacuumStatement.MatchString(query) {
+		return func() {}
+	}
+	s.vacuum.Store(true)
+	return func() { s.vacuum.Store(false) }
+}
+
+// ErrChangelogWrite fails the statements that write changelog entries
+// themselves
+var ErrChangelogWrite = errors.New("changelog entries can only be written by the changelog triggers")
+
+// changelogGuard keeps the sessions restricted to some tables from writing
+// the changelog, which would forge the changes of the tables they are denied.
+// The authorizer cannot tell the inserts of the changelog triggers from the
+// session's own, so sqlite's update hook tracks the statements that log
+// entries without changing a table, they can only be the session's inserts
+// as it cannot create triggers. The commit of the transaction holding them
+// is turned into a rollback.
+type changelogGuard struct {
+	mutex sync.Mutex
+	// changed & logged are set by the running statement
+	changed bool
+	logged  bool
+	// forged is set until the transaction holding a forged entry ends
+	forged bool
+	// err is returned to the statement that forged an entry
+	err error
+}
+
+// newChangelogGuard returns nil when none of the accesses restricts tables
+func newChangelogGuard(auth authorizers) *changelogGuard {
+	for _, a := range auth {
+		if a.restrictsTables() {
+			return &changelogGuard{}
+		}
+	}
+	return nil
+}
+
+// prepare is called by the authorizer, a statement is prepared once the
+// previous one ran.
+func (g *changelogGuard) prepare() {
+	if g == nil {
+		return
+	}
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.end()
+}
+
+// end checks the statement that ran, the mutex is held
+func (g *changelogGuard) end() {
+	if g.logged && !g.changed {
+		g.forged, g.err = true, ErrChangelogWrite
+	}
+	g.changed, g.logged = false, false
+}
+
+func (g *changelogGuard) update(database, table string) {
+	if g == nil || database != "main" {
+		return
+	}
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	switch {
+	case strings.EqualFold(table, changelog.Table):
+		g.logged = true
+	case !internalTable(table):
+		g.changed = true
+	}
+}
+
+// commit reports whether the transaction holds forged entries
+func (g *changelogGuard) commit() bool {
+	if g == nil {
+		return false
+	}
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.end()
+	return g.forged
+}
+
+func (g *changelogGuard) rollback() {
+	if g == nil {
+		return
+	}
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.forged = false
+}
+
+// check is called with the error of a statement once it ran, it returns
+// ErrChangelogWrite when the statement forged entries.
+func (g *changelogGuard) check(err error) error {
+	if g == nil {
+		return err
+	}
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.end()
+	if g.err != nil {
+		err, g.err = g.err, nil
+	}
+	return err
+}
+
+// IsPermissionDenied reports whether a statement failed because the
+// session's access does not allow it
+func IsPermissionDenied(err error) bool {
+	if errors.Is(err, ErrChangelogWrite) {
+		return true
+	}
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	return sqliteErr.Code == sqlite3.ErrAuth || sqliteErr.Code == sqlite3.ErrReadonly
+}
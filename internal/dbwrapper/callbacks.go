@@ -0,0 +1,364 @@
+package dbwrapper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mattn/go-sqlite3"
+	"golang.org/x/exp/slog"
+
+	"github.com/aousomran/sqlite-og/internal/callback"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
+)
+
+// Callbacks are the functions, aggregators & collations of a connection,
+// they are evaluated by the client except for the builtin collations.
+type Callbacks struct {
+	Functions   []*pb.Function
+	Aggregators []*pb.Function
+	Collations  []string
+	// BuiltinCollations are evaluated by the server, see builtinCollation
+	BuiltinCollations []string
+}
+
+var interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// argsToValues converts the arguments given by go-sqlite3 to typed values,
+// go-sqlite3 passes NULL arguments as a nil []byte.
+func argsToValues(args []interface{}) ([]*pb.Value, error) {
+	values := make([]*pb.Value, len(args))
+	for i, arg := range args {
+		if b, ok := arg.([]byte); ok && b == nil {
+			arg = nil
+		}
+		v, err := toValue(arg)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d: %w", i, err)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// fromValue converts the result of a callback to the value returned to sqlite
+func fromValue(v *pb.Value) interface{} {
+	switch val := v.GetValue().(type) {
+	case *pb.Value_Integer:
+		return val.Integer
+	case *pb.Value_Real:
+		return val.Real
+	case *pb.Value_Text:
+		return val.Text
+	case *pb.Value_Blob:
+		return val.Blob
+	default:
+		return nil
+	}
+}
+
+// makeCallbackFunc builds a function taking fn.NumArgs arguments, or any
+// number of arguments when it's negative, so that sqlite checks the arity of
+// the calls. It returns an error when the client's callback fails, times out
+// or is disconnected, go-sqlite3 then fails the statement with it.
+func makeCallbackFunc(fn *pb.Function, channels *callback.CallbackChannels) interface{} {
+	functionName := fn.GetName()
+	variadic := fn.GetNumArgs() < 0
+	in := []reflect.Type{reflect.SliceOf(interfaceType)}
+	if !variadic {
+		in = make([]reflect.Type, fn.GetNumArgs())
+		for i := range in {
+			in[i] = interfaceType
+		}
+	}
+	fnType := reflect.FuncOf(in, []reflect.Type{interfaceType, errorType}, variadic)
+
+	call := func(args []interface{}) (interface{}, error) {
+		slog.Debug("got invocation from DB", "func_name", functionName, "args", args)
+		values, err := argsToValues(args)
+		if err != nil {
+			return nil, err
+		}
+		result, err := channels.Invoke(&pb.Invoke{
+			FunctionName: functionName,
+			Args:         values,
+		})
+		if err != nil {
+			slog.Warn("callback failed", "func_name", functionName, "error", err)
+			return nil, err
+		}
+		slog.Debug("received result sending back to DB", "result", result.GetResult())
+		return fromValue(result.GetResult()), nil
+	}
+
+	return reflect.MakeFunc(fnType, func(in []reflect.Value) []reflect.Value {
+		args := make([]interface{}, 0, len(in))
+		for i, v := range in {
+			if variadic && i == len(in)-1 {
+				for j := 0; j < v.Len(); j++ {
+					args = append(args, v.Index(j).Interface())
+				}
+				continue
+			}
+			args = append(args, v.Interface())
+		}
+		result, err := call(args)
+		out := []reflect.Value{reflect.Zero(interfaceType), reflect.Zero(errorType)}
+		if result != nil {
+			out[0] = reflect.ValueOf(&result).Elem()
+		}
+		if err != nil {
+			out[1] = reflect.ValueOf(&err).Elem()
+		}
+		return out
+	}).Interface()
+}
+
+// aggregator forwards the rows of one aggregate group to the client,
+// the client keeps the group's state under stateId.
+type aggregator struct {
+	name     string
+	stateId  int64
+	channels *callback.CallbackChannels
+}
+
+func (a *aggregator) Step(args ...interface{}) error {
+	slog.Debug("got aggregate step from DB", "func_name", a.name, "state_id", a.stateId, "args", args)
+	values, err := argsToValues(args)
+	if err != nil {
+		return err
+	}
+	_, err = a.channels.Invoke(&pb.Invoke{
+		FunctionName: a.name,
+		Args:         values,
+		Type:         pb.InvokeType_AGGREGATE_STEP,
+		StateId:      a.stateId,
+	})
+	if err != nil {
+		slog.Warn("aggregate step failed", "func_name", a.name, "state_id", a.stateId, "error", err)
+	}
+	return err
+}
+
+func (a *aggregator) Done() (interface{}, error) {
+	result, err := a.channels.Invoke(&pb.Invoke{
+		FunctionName: a.name,
+		Type:         pb.InvokeType_AGGREGATE_DONE,
+		StateId:      a.stateId,
+	})
+	if err != nil {
+		slog.Warn("aggregate done failed", "func_name", a.name, "state_id", a.stateId, "error", err)
+		return nil, err
+	}
+	slog.Debug("received aggregate result sending back to DB", "func_name", a.name, "state_id", a.stateId, "result", result.GetResult())
+	return fromValue(result.GetResult()), nil
+}
+
+// makeAggregatorFunc returns the constructor registered with sqlite,
+// it's called once per group and every group gets its own state id.
+func makeAggregatorFunc(name string, channels *callback.CallbackChannels, stateIds *atomic.Int64) func() *aggregator {
+	return func() *aggregator {
+		return &aggregator{
+			name:     name,
+			stateId:  stateIds.Add(1),
+			channels: channels,
+		}
+	}
+}
+
+// makeCollationFunc compares the strings on the client. sqlite collations
+// cannot fail, when the callback fails the error is recorded by errs, which
+// interrupts the statements & fails them with it, see collationErrors.
+func makeCollationFunc(name string, channels *callback.CallbackChannels, errs *collationErrors) func(a, b string) int {
+	return func(a, b string) int {
+		if errs.failed() {
+			return 0
+		}
+		result, err := channels.Invoke(&pb.Invoke{
+			FunctionName: name,
+			Args: []*pb.Value{
+				{Value: &pb.Value_Text{Text: a}},
+				{Value: &pb.Value_Text{Text: b}},
+			},
+			Type: pb.InvokeType_COLLATION,
+		})
+		if err != nil {
+			slog.Warn("collation failed", "name", name, "error", err)
+			errs.fail(fmt.Errorf("%w: %s: %v", errCollation, name, err))
+			return 0
+		}
+		return int(result.GetResult().GetInteger())
+	}
+}
+
+// errCollation is the cause of the statements interrupted by a failing
+// client collation
+var errCollation = errors.New("collation failed")
+
+// collationErrors fails the statements of a session whose client collation
+// failed. The comparison cannot report it to sqlite, so the statements
+// running on the session are interrupted, the comparisons left return 0
+// until they end & their commit is turned into a rollback.
+type collationErrors struct {
+	mutex   sync.Mutex
+	err     error
+	running map[uint64]context.CancelCauseFunc
+	nextId  uint64
+}
+
+func newCollationErrors(callbacks Callbacks) *collationErrors {
+	if len(callbacks.Collations) == 0 {
+		return nil
+	}
+	return &collationErrors{running: make(map[uint64]context.CancelCauseFunc)}
+}
+
+// run returns the context of a statement, which is cancelled when a
+// collation fails, & the func ending the statement, it returns the error
+// of the collation or err.
+func (e *collationErrors) run(ctx context.Context) (context.Context, func(err error) error) {
+	if e == nil {
+		return ctx, func(err error) error { return err }
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	e.mutex.Lock()
+	e.nextId++
+	id := e.nextId
+	e.running[id] = cancel
+	if e.err != nil {
+		cancel(e.err)
+	}
+	e.mutex.Unlock()
+	return ctx, func(err error) error {
+		e.mutex.Lock()
+		delete(e.running, id)
+		if len(e.running) == 0 {
+			e.err = nil
+		}
+		e.mutex.Unlock()
+		cause := context.Cause(ctx)
+		cancel(nil)
+		if errors.Is(cause, errCollation) {
+			return cause
+		}
+		return err
+	}
+}
+
+// fail interrupts the running statements with err
+func (e *collationErrors) fail(err error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if len(e.running) == 0 {
+		return
+	}
+	if e.err == nil {
+		e.err = err
+	}
+	for _, cancel := range e.running {
+		cancel(e.err)
+	}
+}
+
+func (e *collationErrors) failed() bool {
+	if e == nil {
+		return false
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.err != nil
+}
+
+// newDriver returns a sqlite driver applying the pragmas & registering the
+// callbacks, the change capture hooks & the authorizers, if any, on every
+// connection it opens.
+// registerHooks installs the update, commit & rollback hooks, sqlite calls a
+// single hook of each kind so they are shared by the change capture, the
+// changelog guard & the collation errors. The changes of a statement whose
+// collation failed are rolled back.
+func registerHooks(conn *sqlite3.SQLiteConn, capture *changeCapture, guard *changelogGuard, collationErrs *collationErrors) {
+	if capture == nil && guard == nil && collationErrs == nil {
+		return
+	}
+	conn.RegisterUpdateHook(func(op int, database, table string, rowid int64) {
+		capture.update(op, database, table, rowid)
+		guard.update(database, table)
+	})
+	conn.RegisterCommitHook(func() int {
+		if guard.commit() || collationErrs.failed() {
+			return 1
+		}
+		return capture.commit()
+	})
+	conn.RegisterRollbackHook(func() {
+		capture.rollback()
+		guard.rollback()
+	})
+}
+
+func newDriver(callbacks Callbacks, pragmas []string, auth authorizers, box *sandbox, channels *callback.CallbackChannels, capture *changeCapture, guard *changelogGuard, collationErrs *collationErrors) *sqlite3.SQLiteDriver {
+	stateIds := &atomic.Int64{}
+	return &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			for _, pragma := range pragmas {
+				slog.Debug("applying connection option", "pragma", pragma)
+				if _, err := conn.Exec(pragma, nil); err != nil {
+					slog.Error("unable to apply connection option", "pragma", pragma, "error", err.Error())
+					return fmt.Errorf("unable to apply %s: %w", pragma, err)
+				}
+			}
+			capture.register(conn)
+			registerHooks(conn, capture, guard, collationErrs)
+			for _, fn := range callbacks.Functions {
+				slog.Debug("registering function", "name", fn.GetName(), "num_args", fn.GetNumArgs(), "deterministic", fn.GetDeterministic())
+				err := conn.RegisterFunc(fn.GetName(), makeCallbackFunc(fn, channels), fn.GetDeterministic())
+				if err != nil {
+					slog.Error("unable to register function", "name", fn.GetName(), "error", err.Error())
+					return err
+				}
+			}
+			for _, agg := range callbacks.Aggregators {
+				slog.Debug("registering aggregator", "name", agg.GetName(), "deterministic", agg.GetDeterministic())
+				err := conn.RegisterAggregator(agg.GetName(), makeAggregatorFunc(agg.GetName(), channels, stateIds), agg.GetDeterministic())
+				if err != nil {
+					slog.Error("unable to register aggregator", "name", agg.GetName(), "error", err.Error())
+					return err
+				}
+			}
+			for _, name := range callbacks.Collations {
+				slog.Debug("registering collation", "name", name)
+				err := conn.RegisterCollation(name, makeCollationFunc(name, channels, collationErrs))
+				if err != nil {
+					slog.Error("unable to register collation", "name", name, "error", err.Error())
+					return err
+				}
+			}
+			for _, name := range callbacks.BuiltinCollations {
+				slog.Debug("registering builtin collation", "name", name)
+				cmp, err := builtinCollation(name)
+				if err == nil {
+					err = conn.RegisterCollation(name, cmp)
+				}
+				if err != nil {
+					slog.Error("unable to register builtin collation", "name", name, "error", err.Error())
+					return err
+				}
+			}
+			if len(auth) > 0 || box != nil {
+				conn.RegisterAuthorizer(func(op int, arg1, arg2, database string) int {
+					guard.prepare()
+					if result := box.authorize(op, arg1); result != sqlite3.SQLITE_OK {
+						return result
+					}
+					return auth.authorize(op, arg1, arg2, database)
+				})
+			}
+			return nil
+		},
+	}
+}
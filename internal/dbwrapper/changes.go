@@ -0,0 +1,167 @@
+package dbwrapper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/mattn/go-sqlite3"
+	"golang.org/x/exp/slog"
+
+	"github.com/aousomran/sqlite-og/internal/changes"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
+)
+
+// changeCapture collects the row changes made by a session from sqlite's
+// update hook. The changes of a transaction are moved to committed by the
+// commit hook, they are published once the statement that committed them
+// returned successfully. Changes of TEMP & attached databases and of
+// WITHOUT ROWID tables are not captured.
+type changeCapture struct {
+	hub    *changes.Hub
+	dbname string
+	conn   *sqlite3.SQLiteConn
+
+	mutex     sync.Mutex
+	pending   []*pb.ChangeEvent
+	committed []*pb.ChangeEvent
+}
+
+var changeOperations = map[int]pb.ChangeOperation{
+	sqlite3.SQLITE_INSERT: pb.ChangeOperation_INSERT,
+	sqlite3.SQLITE_UPDATE: pb.ChangeOperation_UPDATE,
+	sqlite3.SQLITE_DELETE: pb.ChangeOperation_DELETE,
+}
+
+// register keeps the connection the hooks are installed on, see
+// registerHooks
+func (c *changeCapture) register(conn *sqlite3.SQLiteConn) {
+	if c == nil {
+		return
+	}
+	c.conn = conn
+}
+
+func (c *changeCapture) update(op int, database, table string, rowid int64) {
+	if c == nil || database != "main" {
+		return
+	}
+	if watched, _ := c.hub.Watched(c.dbname, table); !watched {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.pending = append(c.pending, &pb.ChangeEvent{
+		Table:     table,
+		Operation: changeOperations[op],
+		Rowid:     rowid,
+	})
+}
+
+func (c *changeCapture) commit() int {
+	if c == nil {
+		return 0
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.committed = append(c.committed, c.pending...)
+	c.pending = nil
+	return 0
+}
+
+func (c *changeCapture) rollback() {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.pending, c.committed = nil, nil
+}
+
+// mark returns the position of the next change
+func (c *changeCapture) mark() int {
+	if c == nil {
+		return 0
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.pending)
+}
+
+// done is called once a statement returned, the changes of a failed
+// statement are dropped as sqlite rolled it back. The committed changes are
+// returned when the statement succeeded outside of a transaction, a commit
+// that failed (e.g. with SQLITE_BUSY) keeps them until it's retried or
+// rolled back.
+func (c *changeCapture) done(mark int, err error) []*pb.ChangeEvent {
+	if c == nil {
+		return nil
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err != nil {
+		if mark < len(c.pending) {
+			c.pending = c.pending[:mark]
+		}
+		return nil
+	}
+	if c.conn != nil && !c.conn.AutoCommit() {
+		return nil
+	}
+	committed := c.committed
+	c.committed = nil
+	return committed
+}
+
+// trackChanges is called before a statement runs, the returned function is
+// called with the statement's error & publishes the changes it committed.
+func (w *DBWrapper) trackChanges(ctx context.Context) func(error) {
+	mark := w.capture.mark()
+	return func(err error) {
+		events := w.capture.done(mark, err)
+		if len(events) == 0 {
+			return
+		}
+		for _, ev := range events {
+			if ev.GetOperation() == pb.ChangeOperation_DELETE {
+				continue
+			}
+			if _, values := w.capture.hub.Watched(w.Name, ev.GetTable()); values {
+				if errValues := w.rowValues(ctx, ev); errValues != nil {
+					slog.WarnContext(ctx, "unable to read changed row", "table", ev.GetTable(), "rowid", ev.GetRowid(), "error", errValues)
+				}
+			}
+		}
+		w.capture.hub.Publish(w.Name, events)
+	}
+}
+
+// rowValues reads the changed row, it's read after the commit so it may
+// already reflect later changes.
+func (w *DBWrapper) rowValues(ctx context.Context, ev *pb.ChangeEvent) error {
+	table := `"` + strings.ReplaceAll(ev.GetTable(), `"`, `""`) + `"`
+	conn, err := w.connection()
+	if err != nil {
+		return err
+	}
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf(`SELECT * FROM main.%s WHERE rowid = ?`, table), ev.GetRowid())
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	ev.Columns = cols
+	if rows.Next() {
+		values, err := rowToValues(cols, rows)
+		if err != nil {
+			return err
+		}
+		ev.Values = &pb.Row{Fields: values}
+	}
+	return rows.Err()
+}
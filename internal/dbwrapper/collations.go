@@ -0,0 +1,125 @@
+package dbwrapper
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+const localeCollationPrefix = "locale_"
+const nocaseCollationSuffix = "_nocase"
+
+// builtinCollation returns the comparison of a collation evaluated on the
+// server, the built-in collations are:
+//
+//	natural, natural_nocase       digits are compared by their numeric value, file2 < file10
+//	unicode, unicode_nocase       the Unicode collation algorithm (DUCET)
+//	locale_<tag>, locale_<tag>_nocase  the ordering of a language, e.g. locale_de, locale_sv_nocase
+//
+// NATURAL is a keyword, it has to be quoted: ORDER BY name COLLATE "natural".
+func builtinCollation(name string) (func(a, b string) int, error) {
+	base := strings.ToLower(name)
+	nocase := strings.HasSuffix(base, nocaseCollationSuffix)
+	base = strings.TrimSuffix(base, nocaseCollationSuffix)
+
+	switch {
+	case base == "natural":
+		return func(a, b string) int {
+			return naturalCompare(a, b, nocase)
+		}, nil
+	case base == "unicode":
+		return newLocaleCollation(language.Und, nocase), nil
+	case strings.HasPrefix(base, localeCollationPrefix):
+		tag, err := language.Parse(strings.TrimPrefix(base, localeCollationPrefix))
+		if err != nil {
+			return nil, fmt.Errorf("unknown locale in collation %s: %w", name, err)
+		}
+		return newLocaleCollation(tag, nocase), nil
+	default:
+		return nil, fmt.Errorf("unknown builtin collation %s", name)
+	}
+}
+
+// newLocaleCollation returns a comparison using the collation of the
+// language, collators are not safe for concurrent use.
+func newLocaleCollation(tag language.Tag, nocase bool) func(a, b string) int {
+	var opts []collate.Option
+	if nocase {
+		opts = append(opts, collate.IgnoreCase)
+	}
+	collator := collate.New(tag, opts...)
+	var mutex sync.Mutex
+	return func(a, b string) int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return collator.CompareString(a, b)
+	}
+}
+
+// naturalCompare compares runs of digits by their numeric value & everything
+// else rune by rune, strings that only differ by leading zeros are ordered
+// by their bytes so that the order is total.
+func naturalCompare(a, b string, nocase bool) int {
+	x, y := a, b
+	for x != "" && y != "" {
+		rx, sx := utf8.DecodeRuneInString(x)
+		ry, sy := utf8.DecodeRuneInString(y)
+		if isDigit(rx) && isDigit(ry) {
+			nx, ny := digitsPrefix(x), digitsPrefix(y)
+			if c := compareNumbers(x[:nx], y[:ny]); c != 0 {
+				return c
+			}
+			x, y = x[nx:], y[ny:]
+			continue
+		}
+		if nocase {
+			rx, ry = unicode.ToLower(rx), unicode.ToLower(ry)
+		}
+		if rx != ry {
+			if rx < ry {
+				return -1
+			}
+			return 1
+		}
+		x, y = x[sx:], y[sy:]
+	}
+	switch {
+	case x == "" && y != "":
+		return -1
+	case x != "" && y == "":
+		return 1
+	case nocase:
+		return 0
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func digitsPrefix(s string) int {
+	i := 0
+	for i < len(s) && isDigit(rune(s[i])) {
+		i++
+	}
+	return i
+}
+
+// compareNumbers compares two runs of digits of any length
+func compareNumbers(x, y string) int {
+	x, y = strings.TrimLeft(x, "0"), strings.TrimLeft(y, "0")
+	if len(x) != len(y) {
+		if len(x) < len(y) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(x, y)
+}
@@ -0,0 +1,175 @@
+package dbwrapper
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrInvalidDBName = errors.New("invalid database name")
+	ErrDBNotFound    = errors.New("database does not exist")
+)
+
+// DataDir confines the databases opened by the clients to a directory
+type DataDir struct {
+	// Root is the directory of the databases, the names are relative to
+	// it & cannot leave it. The names are not confined when it's empty,
+	// they are then relative to the working directory.
+	Root string
+	// Create lets the clients create the databases that do not exist yet,
+	// their directory must exist
+	Create bool
+}
+
+// Resolve returns the file of the database named by a client. sqlite URI
+// filenames are rejected as their parameters (vfs, mode...) are the
+// server's business, so are the names escaping the root with .. or with
+// symbolic links.
+func (d DataDir) Resolve(name string) (string, error) {
+	name = NormalizeDBName(name)
+	if name == ":memory:" {
+		return name, nil
+	}
+	if strings.HasPrefix(strings.ToLower(name), "file:") || strings.ContainsAny(name, "?#\x00") {
+		return "", fmt.Errorf("%w: `%s`, URI filenames are not allowed", ErrInvalidDBName, name)
+	}
+	if d.Root == "" {
+		// the sessions, changelogs & watchers of a file share its name
+		name = filepath.Clean(name)
+		return name, d.exists(name, name)
+	}
+	return d.resolve(name)
+}
+
+// ResolveFile checks a file sqlite opens by itself, e.g. the target of an
+// ATTACH, which is relative to the working directory rather than to the
+// root, & returns it like Resolve.
+func (d DataDir) ResolveFile(file string) (string, error) {
+	if file == ":memory:" {
+		return file, nil
+	}
+	if strings.HasPrefix(strings.ToLower(file), "file:") || strings.ContainsAny(file, "?#\x00") {
+		return "", fmt.Errorf("%w: `%s`, URI filenames are not allowed", ErrInvalidDBName, file)
+	}
+	if d.Root == "" {
+		file = filepath.Clean(file)
+		return file, d.exists(file, file)
+	}
+	root, err := d.root()
+	if err != nil {
+		return "", err
+	}
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: `%s` is outside of the data directory", ErrInvalidDBName, file)
+	}
+	return d.resolve(rel)
+}
+
+// resolve returns the file of a name relative to the root
+func (d DataDir) resolve(name string) (string, error) {
+	root, err := d.root()
+	if err != nil {
+		return "", err
+	}
+	clean := filepath.Clean(name)
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: `%s` is outside of the data directory", ErrInvalidDBName, name)
+	}
+	path := filepath.Join(root, clean)
+
+	// the file, or the directory of a new file, must really be in the root
+	real, err := filepath.EvalSymlinks(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		if _, errLink := os.Lstat(path); errLink == nil {
+			return "", fmt.Errorf("%w: `%s` is a dangling link", ErrInvalidDBName, name)
+		}
+		if err := d.exists(path, name); err != nil {
+			return "", err
+		}
+		real, err = filepath.EvalSymlinks(filepath.Dir(path))
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("%w: the directory of `%s` does not exist", ErrDBNotFound, name)
+		}
+		if err == nil {
+			real = filepath.Join(real, filepath.Base(path))
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	if rel, err := filepath.Rel(root, real); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: `%s` links outside of the data directory", ErrInvalidDBName, name)
+	}
+	return real, nil
+}
+
+// root returns the real path of the root
+func (d DataDir) root() (string, error) {
+	root, err := filepath.Abs(d.Root)
+	if err == nil {
+		root, err = filepath.EvalSymlinks(root)
+	}
+	if err != nil {
+		return "", fmt.Errorf("invalid data directory: %w", err)
+	}
+	return root, nil
+}
+
+// Name returns the name of a resolved database file, relative to the root
+func (d DataDir) Name(file string) string {
+	if d.Root == "" || file == ":memory:" {
+		return file
+	}
+	root, err := d.root()
+	if err != nil {
+		return file
+	}
+	if rel, err := filepath.Rel(root, file); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return file
+}
+
+// List returns the files of the .db databases in the root & its
+// directories, or in the working directory when there is no root. Symbolic
+// links are not followed.
+func (d DataDir) List() ([]string, error) {
+	root := "."
+	if d.Root != "" {
+		var err error
+		if root, err = d.root(); err != nil {
+			return nil, err
+		}
+	}
+	var files []string
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.Type().IsRegular() && strings.HasSuffix(entry.Name(), ".db") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// exists checks that the database exists unless it may be created
+func (d DataDir) exists(path, name string) error {
+	if d.Create {
+		return nil
+	}
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: `%s`", ErrDBNotFound, name)
+	}
+	return nil
+}
@@ -3,11 +3,17 @@ package dbwrapper
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"github.com/aousomran/sqlite-og/internal/callback"
+	"github.com/aousomran/sqlite-og/internal/changes"
 	"github.com/mattn/go-sqlite3"
 	"golang.org/x/exp/slog"
+	"google.golang.org/protobuf/proto"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 
@@ -15,52 +21,19 @@ import (
 )
 
 const DefaultDBName = "test"
-const defaultDriverName = "sqlite-og"
+
+// BatchSize is the maximum number of rows sent in one streamed QueryResult
+const BatchSize = 500
+
+// maxBatchBytes keeps a streamed batch well below grpc's default 4MB message size
+const maxBatchBytes = 1 << 20
 
 type Columns []string
 type RowFields *[]string
 type Rows []RowFields
 
-type callbackFunction func(args ...interface{}) string
-
-func makeCallbackFunc(functionName string, channels *callback.CallbackChannels) callbackFunction {
-	return func(args ...interface{}) string {
-		slog.Debug("got invocation from DB", "func_name", functionName, "args", args)
-		args2 := make([]string, 0)
-		for _, v := range args {
-			args2 = append(args2, fmt.Sprintf("%v", v))
-		}
-		channels.ChanSend <- &pb.Invoke{
-			FunctionName: functionName,
-			Args:         args2,
-		}
-		result := <-channels.ChanReceive
-		slog.Debug("received result sending back to DB", "result", result.GetResult())
-		if len(result.GetResult()) < 1 {
-			return ""
-		}
-		return result.GetResult()[0]
-	}
-}
-
-func registerDriver(driverName string, functions []string, channels *callback.CallbackChannels) {
-	sql.Register(driverName, &sqlite3.SQLiteDriver{
-		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
-			for _, name := range functions {
-				slog.Debug("registering functions", "names", functions)
-				err := conn.RegisterFunc(name, makeCallbackFunc(name, channels), true)
-				if err != nil {
-					slog.Error("unable to register function", "name", name, "error", err.Error())
-					return err
-				}
-			}
-			return nil
-		},
-	})
-	return
-}
-
-func normalizeDBName(name string) string {
+// NormalizeDBName returns the file name of a database
+func NormalizeDBName(name string) string {
 	// allow in memory database
 	if name == ":memory:" {
 		return name
@@ -76,35 +49,235 @@ func normalizeDBName(name string) string {
 }
 
 type DBWrapper struct {
-	Name     string
+	Name string
+	// Owner identifies the client that opened the session, only it may
+	// use the session
+	Owner    string
 	Database *sql.DB
+	// conn is the single sqlite connection owned by this session, every
+	// statement runs on it so that session state (TEMP tables, PRAGMAs,
+	// last_insert_rowid(), attached databases...) behaves like a local
+	// sqlite connection. It's nil once the session is closed.
+	conn     atomic.Pointer[sql.Conn]
 	Channels *callback.CallbackChannels
+
+	// txMutex guards the transaction state
+	txMutex sync.Mutex
+	inTx    bool
+	txRO    bool
+
+	// stmtMutex guards the prepared statements, keyed by their handle
+	stmtMutex  sync.Mutex
+	stmts      map[string]*preparedStmt
+	nextStmtId uint64
+
+	// connector opens the sqlite connection with the session's callbacks
+	connector *connector
+	// capture publishes the changes committed by the session to watchers
+	capture *changeCapture
+	// sandbox checks the databases the session attaches
+	sandbox *sandbox
+	// guard fails the statements forging changelog entries
+	guard *changelogGuard
+	// collations fails the statements whose client collation failed
+	collations *collationErrors
+
+	// running counts the statements running on the session, lastUsed is
+	// the last time it was used in unix nanoseconds, see Idle
+	running  atomic.Int32
+	lastUsed atomic.Int64
+}
+
+// connector opens sqlite connections with go-sqlite3's driver directly, the
+// driver is never registered with database/sql, which keeps registered
+// drivers forever, so it's released with the DBWrapper.
+type connector struct {
+	name   string
+	driver *sqlite3.SQLiteDriver
+}
+
+// ReadOnlyURI returns the URI filename opening the database read only, the
+// characters with a meaning in URIs are escaped.
+func ReadOnlyURI(dbname string) string {
+	escaped := strings.NewReplacer("%", "%25", "?", "%3f", "#", "%23").Replace(dbname)
+	return "file:" + escaped + "?mode=ro"
+}
+
+func (c *connector) Connect(context.Context) (driver.Conn, error) {
+	conn, err := c.driver.Open(c.name)
+	if err != nil {
+		return nil, err
+	}
+	return &storageConn{conn.(*sqlite3.SQLiteConn)}, nil
+}
+
+// storageConn returns the values in their storage class. go-sqlite3
+// converts the values of the columns declared DATE, DATETIME, TIMESTAMP or
+// BOOLEAN to time.Time & bool, which loses the storage class of the integers
+// & the text it cannot parse.
+type storageConn struct {
+	*sqlite3.SQLiteConn
 }
 
-func New(dbname, id string, functions []string, channels *callback.CallbackChannels) *DBWrapper {
+func (c *storageConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return storageClasses(c.SQLiteConn.QueryContext(ctx, query, args))
+}
+
+// PrepareContext describes the statement when the context carries a
+// describeKey, see DBWrapper.Prepare
+func (c *storageConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	stmt, err := c.SQLiteConn.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	s := &storageStmt{stmt.(*sqlite3.SQLiteStmt)}
+	if prepared, ok := ctx.Value(describeKey{}).(*pb.PreparedStatement); ok {
+		if err := describe(ctx, s, prepared); err != nil {
+			_ = s.Close()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+type storageStmt struct {
+	*sqlite3.SQLiteStmt
+}
+
+func (s *storageStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return storageClasses(s.SQLiteStmt.QueryContext(ctx, args))
+}
+
+// storageClasses clears the declared types go-sqlite3 converts the values
+// by, DeclTypes returns the slice read by Next. The column types reported
+// to the clients are read from sqlite.
+func storageClasses(rows driver.Rows, err error) (driver.Rows, error) {
+	if r, ok := rows.(*sqlite3.SQLiteRows); ok {
+		declTypes := r.DeclTypes()
+		for i := range declTypes {
+			declTypes[i] = ""
+		}
+	}
+	return rows, err
+}
+
+func (c *connector) Driver() driver.Driver {
+	return c.driver
+}
+
+// New returns the session of a database file, as resolved by DataDir, the
+// changes it commits are published to hub unless it's nil. Every access applies to the session, nil
+// accesses are unrestricted. The databases it attaches are checked by attach,
+// they are not restricted when it's nil. The options are PRAGMAs applied to
+// the session's connection, see connectionOptions.
+func New(dbname string, callbacks Callbacks, access []*pb.Access, attach AttachFunc, options map[string]string, channels *callback.CallbackChannels, hub *changes.Hub) (*DBWrapper, error) {
 	// TODO: pass context to this function
-	dbname = normalizeDBName(dbname)
-	registerDriver(id, functions, channels)
-	slog.Info("registered drivers", "names", sql.Drivers())
-	return &DBWrapper{
+	auth := newAuthorizers(access)
+	statements, err := pragmas(options, auth)
+	if err != nil {
+		return nil, err
+	}
+	// in memory databases are private to the session so they are never
+	// watched
+	var capture *changeCapture
+	if hub != nil && dbname != ":memory:" {
+		capture = &changeCapture{hub: hub, dbname: dbname}
+	}
+	guard := newChangelogGuard(auth)
+	box := newSandbox(attach)
+	collationErrs := newCollationErrors(callbacks)
+	name := dbname
+	if auth.readOnly() && dbname != ":memory:" {
+		name = ReadOnlyURI(dbname)
+	}
+	w := &DBWrapper{
 		Name:     dbname,
 		Channels: channels,
+		connector: &connector{
+			name:   name,
+			driver: newDriver(callbacks, statements, auth, box, channels, capture, guard, collationErrs),
+		},
+		capture:    capture,
+		guard:      guard,
+		sandbox:    box,
+		collations: collationErrs,
 	}
+	w.Touch()
+	return w, nil
 }
 
-func (w *DBWrapper) Open(driverName string) error {
+// Touch marks the session as used
+func (w *DBWrapper) Touch() {
+	w.lastUsed.Store(time.Now().UnixNano())
+}
+
+// use marks the session as used until the returned func is called, e.g.
+// while a statement runs
+func (w *DBWrapper) use() func() {
+	w.running.Add(1)
+	w.Touch()
+	return func() {
+		w.Touch()
+		w.running.Add(-1)
+	}
+}
+
+// Idle returns how long the session has not been used, it's zero while a
+// statement runs
+func (w *DBWrapper) Idle() time.Duration {
+	if w.running.Load() > 0 {
+		return 0
+	}
+	return time.Since(time.Unix(0, w.lastUsed.Load()))
+}
+
+func (w *DBWrapper) Open() error {
 	if w.Database == nil {
-		db, err := sql.Open(driverName, w.Name)
+		db := sql.OpenDB(w.connector)
+		// the pool never needs more than the pinned connection
+		db.SetMaxOpenConns(1)
+		db.SetMaxIdleConns(1)
+		db.SetConnMaxLifetime(0)
+		db.SetConnMaxIdleTime(0)
+
+		conn, err := db.Conn(context.Background())
 		if err != nil {
+			_ = db.Close()
 			return err
 		}
 
 		w.Database = db
+		w.conn.Store(conn)
 	}
 	return nil
 }
 
+// connection returns the session's connection, the session may be closed
+// concurrently, e.g. when it's reaped, the connection then fails with
+// sql.ErrConnDone.
+func (w *DBWrapper) connection() (*sql.Conn, error) {
+	conn := w.conn.Load()
+	if conn == nil {
+		return nil, fmt.Errorf("connection is closed")
+	}
+	return conn, nil
+}
+
 func (w *DBWrapper) Close() error {
+	// statements waiting for a callback must not block the close
+	if w.Channels != nil {
+		w.Channels.Disconnect()
+	}
+	w.closeStatements()
+	if err := w.endTx(context.Background(), "ROLLBACK"); err != nil && err != ErrNoTransaction {
+		slog.Warn("unable to rollback transaction on close", "dbname", w.Name, "error", err)
+	}
+	if conn := w.conn.Swap(nil); conn != nil {
+		err := conn.Close()
+		if err != nil {
+			return err
+		}
+	}
 	if w.Database != nil {
 		err := w.Database.Close()
 		if err != nil {
@@ -116,15 +289,60 @@ func (w *DBWrapper) Close() error {
 }
 
 func (w *DBWrapper) Query(ctx context.Context, sql string, params ...interface{}) ([]string, []string, []*pb.Row, error) {
-	if w.Database == nil {
-		return nil, nil, nil, fmt.Errorf("connection is closed")
+	var cols, colTypes []string
+	pbRows := make([]*pb.Row, 0)
+	err := w.QueryBatches(ctx, func(batch *pb.QueryResult) error {
+		if batch.GetColumns() != nil {
+			cols, colTypes = batch.GetColumns(), batch.GetColumnTypes()
+		}
+		pbRows = append(pbRows, batch.GetRows()...)
+		return nil
+	}, sql, params...)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return cols, colTypes, pbRows, nil
+}
+
+// run returns the context of a statement, the returned func is called with
+// the statement's error once it ran & returns the error it fails with, see
+// collationErrors & changelogGuard.
+func (w *DBWrapper) run(ctx context.Context) (context.Context, func(error) error) {
+	ctx, done := w.collations.run(ctx)
+	return ctx, func(err error) error {
+		return w.guard.check(done(err))
 	}
+}
 
-	rows, err := w.Database.QueryContext(ctx, sql, params...)
+// QueryBatches runs the query and calls fn with the column names & types
+// first, then with the rows in batches of at most BatchSize rows or
+// maxBatchBytes bytes. Rows are read from the cursor as they are sent, so the
+// result set is never held in memory. If fn returns an error, or ctx is
+// cancelled, the cursor is closed and the error is returned.
+func (w *DBWrapper) QueryBatches(ctx context.Context, fn func(*pb.QueryResult) error, sql string, params ...interface{}) error {
+	conn, err := w.connection()
 	if err != nil {
-		return nil, nil, nil, err
+		return err
 	}
 
+	defer w.use()()
+	defer w.sandbox.run(sql)()
+	publish := w.trackChanges(ctx)
+	stmtCtx, done := w.run(ctx)
+	rows, err := conn.QueryContext(stmtCtx, sql, params...)
+	if err != nil {
+		err = done(err)
+		publish(err)
+		return err
+	}
+	err = done(streamRows(stmtCtx, rows, fn))
+	publish(err)
+	return err
+}
+
+// streamRows sends the column metadata followed by batches of rows to fn,
+// rows are always closed when it returns.
+func streamRows(ctx context.Context, rows *sql.Rows, fn func(*pb.QueryResult) error) error {
 	defer func() {
 		errClose := rows.Close()
 		if errClose != nil {
@@ -134,12 +352,12 @@ func (w *DBWrapper) Query(ctx context.Context, sql string, params ...interface{}
 
 	cols, err := rows.Columns()
 	if err != nil {
-		return nil, nil, nil, err
+		return err
 	}
 
 	colTypes, err := rows.ColumnTypes()
 	if err != nil {
-		return nil, nil, nil, err
+		return err
 	}
 
 	colTypes2 := make([]string, len(colTypes))
@@ -147,26 +365,54 @@ func (w *DBWrapper) Query(ctx context.Context, sql string, params ...interface{}
 		colTypes2[k] = v.DatabaseTypeName()
 	}
 
-	pbRows := make([]*pb.Row, 0)
+	err = fn(&pb.QueryResult{Columns: cols, ColumnTypes: colTypes2})
+	if err != nil {
+		return err
+	}
+
+	batch, batchBytes := make([]*pb.Row, 0, BatchSize), 0
 	for rows.Next() {
-		r, err := rowToStringSlice(cols, rows)
+		r, err := rowToValues(cols, rows)
 		if err != nil {
 			slog.ErrorCtx(ctx, "unable to fetch next row", "error", err)
-			return nil, nil, nil, err
+			return err
+		}
+		row := &pb.Row{Fields: r}
+		batch = append(batch, row)
+		batchBytes += proto.Size(row)
+		if len(batch) >= BatchSize || batchBytes >= maxBatchBytes {
+			if err := fn(&pb.QueryResult{Rows: batch}); err != nil {
+				return err
+			}
+			batch, batchBytes = make([]*pb.Row, 0, BatchSize), 0
 		}
-		pbRows = append(pbRows, &pb.Row{Fields: r})
+	}
+	if err := rows.Err(); err != nil {
+		return err
 	}
 
-	return cols, colTypes2, pbRows, nil
+	if len(batch) > 0 {
+		return fn(&pb.QueryResult{Rows: batch})
+	}
+	return nil
 }
 
 func (w *DBWrapper) Execute(ctx context.Context, sql string, params ...interface{}) (insertId int64, affected int64, err error) {
-	if w.Database == nil {
-		err = fmt.Errorf("connection is closed")
+	conn, err := w.connection()
+	if err != nil {
 		return
 	}
 
-	result, err := w.Database.ExecContext(ctx, sql, params...)
+	defer w.use()()
+	defer w.sandbox.run(sql)()
+	publish := w.trackChanges(ctx)
+	stmtCtx, done := w.run(ctx)
+	defer func() {
+		err = done(err)
+		publish(err)
+	}()
+
+	result, err := conn.ExecContext(stmtCtx, sql, params...)
 	if err != nil {
 		return
 	}
@@ -180,13 +426,17 @@ func (w *DBWrapper) Execute(ctx context.Context, sql string, params ...interface
 	return
 }
 
-func rowToStringSlice(columnNames []string, rows *sql.Rows) ([]string, error) {
+// rowToValues scans the current row into typed values, the connection
+// returns the value according to the storage class of each cell, see
+// storageConn, so NULLs, integers, reals, text and blobs are preserved on the
+// wire.
+func rowToValues(columnNames []string, rows *sql.Rows) ([]*pb.Value, error) {
 	lenCN := len(columnNames)
-	ret := make([]string, lenCN)
+	ret := make([]*pb.Value, lenCN)
 
 	columnPointers := make([]interface{}, lenCN)
 	for i := 0; i < lenCN; i++ {
-		columnPointers[i] = new(sql.RawBytes)
+		columnPointers[i] = new(interface{})
 	}
 
 	if err := rows.Scan(columnPointers...); err != nil {
@@ -194,12 +444,29 @@ func rowToStringSlice(columnNames []string, rows *sql.Rows) ([]string, error) {
 	}
 
 	for i := 0; i < lenCN; i++ {
-		if rb, ok := columnPointers[i].(*sql.RawBytes); ok {
-			ret[i] = string(*rb)
-		} else {
-			return nil, fmt.Errorf("cannot convert index %d column %s to type *sql.RawBytes", i, columnNames[i])
+		v, err := toValue(*(columnPointers[i].(*interface{})))
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert index %d column %s, error: %w", i, columnNames[i], err)
 		}
+		ret[i] = v
 	}
 
 	return ret, nil
 }
+
+func toValue(v interface{}) (*pb.Value, error) {
+	switch val := v.(type) {
+	case nil:
+		return &pb.Value{Value: &pb.Value_Null{Null: &pb.Empty{}}}, nil
+	case int64:
+		return &pb.Value{Value: &pb.Value_Integer{Integer: val}}, nil
+	case float64:
+		return &pb.Value{Value: &pb.Value_Real{Real: val}}, nil
+	case string:
+		return &pb.Value{Value: &pb.Value_Text{Text: val}}, nil
+	case []byte:
+		return &pb.Value{Value: &pb.Value_Blob{Blob: val}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %T", v)
+	}
+}
@@ -0,0 +1,91 @@
+package dbwrapper
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidOption = errors.New("invalid connection option")
+
+// connectionOptions are the PRAGMAs a client may set on its session, in the
+// order they are applied: busy_timeout first so that the others wait for
+// locks. Every value is validated, they are never quoted.
+var connectionOptions = []struct {
+	name  string
+	parse func(string) (string, bool)
+}{
+	{"busy_timeout", integerOption},
+	{"foreign_keys", booleanOption},
+	{"journal_mode", enumOption("delete", "truncate", "persist", "memory", "wal", "off")},
+	{"synchronous", enumOption("off", "normal", "full", "extra", "0", "1", "2", "3")},
+	{"cache_size", integerOption},
+	{"temp_store", enumOption("default", "file", "memory", "0", "1", "2")},
+	{"mmap_size", integerOption},
+	{"secure_delete", enumOption("on", "off", "true", "false", "yes", "no", "1", "0", "fast")},
+	{"recursive_triggers", booleanOption},
+	{"case_sensitive_like", booleanOption},
+	{"query_only", booleanOption},
+}
+
+func integerOption(value string) (string, bool) {
+	i, err := strconv.ParseInt(value, 10, 64)
+	return strconv.FormatInt(i, 10), err == nil
+}
+
+func booleanOption(value string) (string, bool) {
+	switch strings.ToLower(value) {
+	case "1", "on", "true", "yes":
+		return "1", true
+	case "0", "off", "false", "no":
+		return "0", true
+	default:
+		return "", false
+	}
+}
+
+func enumOption(values ...string) func(string) (string, bool) {
+	return func(value string) (string, bool) {
+		value = strings.ToLower(value)
+		for _, v := range values {
+			if v == value {
+				return value, true
+			}
+		}
+		return "", false
+	}
+}
+
+// pragmas returns the statements applying the options, a session with a
+// restricted access can only set the options it could set with PRAGMA.
+func pragmas(options map[string]string, auth authorizers) ([]string, error) {
+	lower := make(map[string]string, len(options))
+	for name, value := range options {
+		lower[strings.ToLower(name)] = value
+	}
+	options = lower
+	known := make(map[string]bool, len(connectionOptions))
+	var statements []string
+	for _, option := range connectionOptions {
+		known[option.name] = true
+		value, ok := options[option.name]
+		if !ok {
+			continue
+		}
+		parsed, ok := option.parse(strings.TrimSpace(value))
+		if !ok {
+			return nil, fmt.Errorf("%w: %s=%s", ErrInvalidOption, option.name, value)
+		}
+		if !auth.pragmaAllowed(option.name, parsed) {
+			return nil, fmt.Errorf("%w: %s is not allowed by the session's access", ErrInvalidOption, option.name)
+		}
+		statements = append(statements, fmt.Sprintf("PRAGMA %s = %s", option.name, parsed))
+	}
+	for name := range options {
+		if !known[name] {
+			return nil, fmt.Errorf("%w: unknown option %s", ErrInvalidOption, name)
+		}
+	}
+	return statements, nil
+}
@@ -0,0 +1,163 @@
+package dbwrapper
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+
+	"golang.org/x/exp/slog"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
+)
+
+// Prepare compiles the statement on the session's connection and returns
+// its handle along with the number of parameters and the result columns.
+func (w *DBWrapper) Prepare(ctx context.Context, query string) (*pb.PreparedStatement, error) {
+	conn, err := w.connection()
+	if err != nil {
+		return nil, err
+	}
+
+	defer w.use()()
+	// database/sql does not expose the driver statement, storageConn
+	// describes it as it's prepared
+	prepared := &pb.PreparedStatement{}
+	stmt, err := conn.PrepareContext(context.WithValue(ctx, describeKey{}, prepared), query)
+	if err != nil {
+		return nil, err
+	}
+
+	w.stmtMutex.Lock()
+	defer w.stmtMutex.Unlock()
+	if w.stmts == nil {
+		w.stmts = map[string]*preparedStmt{}
+	}
+	w.nextStmtId++
+	prepared.Id = strconv.FormatUint(w.nextStmtId, 10)
+	w.stmts[prepared.Id] = &preparedStmt{Stmt: stmt, query: query}
+	return prepared, nil
+}
+
+// preparedStmt is a statement prepared by the session's client, its query
+// is kept for the sandbox, see sandbox.run
+type preparedStmt struct {
+	*sql.Stmt
+	query string
+}
+
+// describeKey is the context key of the statement storageConn describes
+type describeKey struct{}
+
+// describe fills the number of inputs & the columns of the statement,
+// database/sql does not expose them so they're read from the driver.
+// Opening rows without stepping them has no side effects in sqlite.
+func describe(ctx context.Context, ds driver.Stmt, prepared *pb.PreparedStatement) error {
+	prepared.NumInput = int32(ds.NumInput())
+
+	queryer, ok := ds.(driver.StmtQueryContext)
+	if !ok {
+		return fmt.Errorf("driver statement %T cannot be queried", ds)
+	}
+	rows, err := queryer.QueryContext(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if errClose := rows.Close(); errClose != nil {
+			slog.ErrorContext(ctx, "unable to close rows", "error", errClose)
+		}
+	}()
+	prepared.Columns = rows.Columns()
+	prepared.ColumnTypes = make([]string, len(prepared.Columns))
+	if typed, ok := rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		for i := range prepared.Columns {
+			prepared.ColumnTypes[i] = typed.ColumnTypeDatabaseTypeName(i)
+		}
+	}
+	return nil
+}
+
+func (w *DBWrapper) statement(id string) (*preparedStmt, error) {
+	w.stmtMutex.Lock()
+	defer w.stmtMutex.Unlock()
+	stmt, ok := w.stmts[id]
+	if !ok {
+		return nil, fmt.Errorf("prepared statement with id `%s` does not exist", id)
+	}
+	return stmt, nil
+}
+
+func (w *DBWrapper) ExecutePrepared(ctx context.Context, id string, params ...interface{}) (insertId int64, affected int64, err error) {
+	stmt, err := w.statement(id)
+	if err != nil {
+		return
+	}
+
+	defer w.use()()
+	defer w.sandbox.run(stmt.query)()
+	publish := w.trackChanges(ctx)
+	stmtCtx, done := w.run(ctx)
+	defer func() {
+		err = done(err)
+		publish(err)
+	}()
+
+	result, err := stmt.ExecContext(stmtCtx, params...)
+	if err != nil {
+		return
+	}
+
+	insertId, err = result.LastInsertId()
+	if err != nil {
+		return
+	}
+
+	affected, err = result.RowsAffected()
+	return
+}
+
+// QueryPreparedBatches is the prepared statement equivalent of QueryBatches
+func (w *DBWrapper) QueryPreparedBatches(ctx context.Context, fn func(*pb.QueryResult) error, id string, params ...interface{}) error {
+	stmt, err := w.statement(id)
+	if err != nil {
+		return err
+	}
+
+	defer w.use()()
+	defer w.sandbox.run(stmt.query)()
+	publish := w.trackChanges(ctx)
+	stmtCtx, done := w.run(ctx)
+	rows, err := stmt.QueryContext(stmtCtx, params...)
+	if err != nil {
+		err = done(err)
+		publish(err)
+		return err
+	}
+	err = done(streamRows(stmtCtx, rows, fn))
+	publish(err)
+	return err
+}
+
+func (w *DBWrapper) ClosePrepared(id string) error {
+	w.stmtMutex.Lock()
+	stmt, ok := w.stmts[id]
+	delete(w.stmts, id)
+	w.stmtMutex.Unlock()
+	if !ok {
+		return fmt.Errorf("prepared statement with id `%s` does not exist", id)
+	}
+	return stmt.Close()
+}
+
+func (w *DBWrapper) closeStatements() {
+	w.stmtMutex.Lock()
+	defer w.stmtMutex.Unlock()
+	for id, stmt := range w.stmts {
+		if err := stmt.Close(); err != nil {
+			slog.Warn("unable to close prepared statement", "dbname", w.Name, "stmt_id", id, "error", err)
+		}
+	}
+	w.stmts = nil
+}
@@ -0,0 +1,91 @@
+package dbwrapper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
+)
+
+var ErrNoTransaction = errors.New("no transaction in progress")
+var ErrTransactionInProgress = errors.New("a transaction is already in progress")
+
+func beginStatement(lock pb.TxLock) (string, error) {
+	switch lock {
+	case pb.TxLock_DEFERRED:
+		return "BEGIN DEFERRED", nil
+	case pb.TxLock_IMMEDIATE:
+		return "BEGIN IMMEDIATE", nil
+	case pb.TxLock_EXCLUSIVE:
+		return "BEGIN EXCLUSIVE", nil
+	default:
+		return "", fmt.Errorf("unknown transaction lock %s", lock)
+	}
+}
+
+// Begin starts a transaction on the session's connection. sqlite has no
+// read-only transactions, so readOnly sets `PRAGMA query_only` for its duration.
+func (w *DBWrapper) Begin(ctx context.Context, readOnly bool, lock pb.TxLock) error {
+	conn, err := w.connection()
+	if err != nil {
+		return err
+	}
+	begin, err := beginStatement(lock)
+	if err != nil {
+		return err
+	}
+
+	w.txMutex.Lock()
+	defer w.txMutex.Unlock()
+	if w.inTx {
+		return ErrTransactionInProgress
+	}
+
+	if readOnly {
+		if _, err = conn.ExecContext(ctx, "PRAGMA query_only = ON"); err != nil {
+			return err
+		}
+	}
+	if _, err = conn.ExecContext(ctx, begin); err != nil {
+		if readOnly {
+			_, _ = conn.ExecContext(ctx, "PRAGMA query_only = OFF")
+		}
+		return err
+	}
+	w.inTx, w.txRO = true, readOnly
+	return nil
+}
+
+func (w *DBWrapper) Commit(ctx context.Context) error {
+	return w.endTx(ctx, "COMMIT")
+}
+
+func (w *DBWrapper) Rollback(ctx context.Context) error {
+	return w.endTx(ctx, "ROLLBACK")
+}
+
+func (w *DBWrapper) endTx(ctx context.Context, statement string) error {
+	w.txMutex.Lock()
+	defer w.txMutex.Unlock()
+	conn := w.conn.Load()
+	if !w.inTx || conn == nil {
+		return ErrNoTransaction
+	}
+
+	publish := w.trackChanges(ctx)
+	_, err := conn.ExecContext(ctx, statement)
+	if err != nil && statement == "COMMIT" {
+		// sqlite can leave the transaction open when the commit fails
+		// (e.g. SQLITE_BUSY), but database/sql on the client considers
+		// it complete, so it's rolled back like go-sqlite3 does.
+		_, _ = conn.ExecContext(context.Background(), "ROLLBACK")
+	}
+
+	if w.txRO {
+		_, _ = conn.ExecContext(context.Background(), "PRAGMA query_only = OFF")
+	}
+	w.inTx, w.txRO = false, false
+	publish(err)
+	return err
+}
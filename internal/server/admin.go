@@ -0,0 +1,224 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
+	"github.com/aousomran/sqlite-og/internal/auth"
+	"github.com/aousomran/sqlite-og/internal/connections"
+)
+
+// Admin manages the databases of the sessions' server, it applies the same
+// policy
+type Admin struct {
+	pb.UnimplementedAdminServer
+	server *Server
+}
+
+func NewAdmin(server *Server) *Admin {
+	return &Admin{server: server}
+}
+
+// adminError returns the status of the errors managing a database
+func adminError(err error) error {
+	switch {
+	case errors.Is(err, connections.ErrDBExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, connections.ErrSessionsOpen):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return databaseError(err)
+	}
+}
+
+// ListDatabases lists the databases the identity of the request may open
+func (a *Admin) ListDatabases(ctx context.Context, _ *pb.Empty) (*pb.DatabaseList, error) {
+	infos, err := a.server.Manager.ListDatabases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list := &pb.DatabaseList{}
+	for _, info := range infos {
+		if _, err := a.server.authorize(ctx, info.GetDbName()); err == nil {
+			list.Databases = append(list.Databases, info)
+		}
+	}
+	return list, nil
+}
+
+func (a *Admin) CreateDatabase(ctx context.Context, in *pb.CreateDatabaseRequest) (*pb.DatabaseInfo, error) {
+	if err := a.server.authorizeAdmin(ctx, in.GetDbName()); err != nil {
+		return nil, err
+	}
+	if in.GetTemplate() != "" {
+		if _, err := a.server.authorize(ctx, in.GetTemplate()); err != nil {
+			return nil, err
+		}
+	}
+	info, err := a.server.Manager.CreateDatabase(ctx, in.GetDbName(), in.GetTemplate())
+	if err != nil {
+		return nil, adminError(err)
+	}
+	return info, nil
+}
+
+func (a *Admin) DropDatabase(ctx context.Context, in *pb.DropDatabaseRequest) (*pb.Empty, error) {
+	if err := a.server.authorizeAdmin(ctx, in.GetDbName()); err != nil {
+		return nil, err
+	}
+	if err := a.server.Manager.DropDatabase(ctx, in.GetDbName(), in.GetForce()); err != nil {
+		return nil, adminError(err)
+	}
+	return &pb.Empty{}, nil
+}
+
+func (a *Admin) RenameDatabase(ctx context.Context, in *pb.RenameDatabaseRequest) (*pb.DatabaseInfo, error) {
+	for _, dbname := range []string{in.GetDbName(), in.GetNewDbName()} {
+		if err := a.server.authorizeAdmin(ctx, dbname); err != nil {
+			return nil, err
+		}
+	}
+	info, err := a.server.Manager.RenameDatabase(ctx, in.GetDbName(), in.GetNewDbName())
+	if err != nil {
+		return nil, adminError(err)
+	}
+	return info, nil
+}
+
+// backupChunkSize is the size of the chunks of the files streamed
+const backupChunkSize = 64 << 10
+
+// Backup takes a snapshot of the database to a temporary file, then streams
+// it. The identity of the request must be allowed to read the whole
+// database.
+func (a *Admin) Backup(in *pb.BackupRequest, stream pb.Admin_BackupServer) error {
+	ctx := stream.Context()
+	access, err := a.server.authorize(ctx, in.GetDbName())
+	if err != nil {
+		return err
+	}
+	if !auth.AllowsTables(access, nil, true) {
+		return status.Errorf(codes.PermissionDenied, "the access to %s does not allow reading all of it", in.GetDbName())
+	}
+
+	dir, err := os.MkdirTemp("", "sqliteog-backup-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	snapshot := filepath.Join(dir, "backup.db")
+	if err := a.server.Manager.Backup(ctx, in.GetDbName(), snapshot); err != nil {
+		return adminError(err)
+	}
+	f, err := os.Open(snapshot)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return sendFile(stream, f)
+}
+
+// sendFile streams the file in chunks, the last chunk carries its size &
+// checksum
+func sendFile(stream pb.Admin_BackupServer, r io.Reader) error {
+	hash := sha256.New()
+	var size int64
+	for {
+		// the messages sent may still be in use, they are never reused
+		buf := make([]byte, backupChunkSize)
+		n, err := io.ReadFull(r, buf)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			hash.Write(buf[:n])
+			return stream.Send(&pb.FileChunk{
+				Data:   buf[:n],
+				Sha256: hex.EncodeToString(hash.Sum(nil)),
+				Size:   size + int64(n),
+			})
+		}
+		if err != nil {
+			return err
+		}
+		hash.Write(buf)
+		size += int64(n)
+		if err := stream.Send(&pb.FileChunk{Data: buf}); err != nil {
+			return err
+		}
+	}
+}
+
+// Restore replaces a database with the file streamed by the client, the
+// file is verified against the checksum of its last chunk.
+func (a *Admin) Restore(stream pb.Admin_RestoreServer) error {
+	ctx := stream.Context()
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	if err := a.server.authorizeAdmin(ctx, first.GetDbName()); err != nil {
+		return err
+	}
+	r := &chunkReader{stream: stream, chunk: first.GetChunk(), hash: sha256.New()}
+	info, err := a.server.Manager.Restore(ctx, first.GetDbName(), first.GetForce(), r)
+	switch {
+	case errors.Is(err, errIncompleteFile) || errors.Is(err, connections.ErrIntegrity):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case err != nil:
+		return adminError(err)
+	}
+	return stream.SendAndClose(info)
+}
+
+var errIncompleteFile = errors.New("file does not match its checksum")
+
+// chunkReader reads the file of a Restore stream, it fails with
+// errIncompleteFile unless the file ends with a chunk carrying its checksum
+type chunkReader struct {
+	stream pb.Admin_RestoreServer
+	chunk  *pb.FileChunk
+	data   []byte
+	hash   hash.Hash
+	size   int64
+	done   bool
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.data) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if r.chunk == nil {
+			msg, err := r.stream.Recv()
+			if err == io.EOF {
+				return 0, fmt.Errorf("%w: the stream ended without a checksum", errIncompleteFile)
+			}
+			if err != nil {
+				return 0, err
+			}
+			r.chunk = msg.GetChunk()
+		}
+		r.data = r.chunk.GetData()
+		r.hash.Write(r.data)
+		r.size += int64(len(r.data))
+		if sum := r.chunk.GetSha256(); sum != "" {
+			if sum != hex.EncodeToString(r.hash.Sum(nil)) || r.size != r.chunk.GetSize() {
+				return 0, fmt.Errorf("%w: got %d bytes, want %d", errIncompleteFile, r.size, r.chunk.GetSize())
+			}
+			r.done = true
+		}
+		r.chunk = nil
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
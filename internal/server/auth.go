@@ -0,0 +1,119 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net"
+	"path"
+
+	"golang.org/x/exp/slog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
+	"github.com/aousomran/sqlite-og/internal/auth"
+	"github.com/aousomran/sqlite-og/internal/connections"
+	"github.com/aousomran/sqlite-og/internal/dbwrapper"
+)
+
+// authorize returns the access the policy grants to the identity of the
+// request on the database, nil when there is no policy or it grants full
+// access. Databases are matched by their normalized name, e.g. "app.db".
+func (s *Server) authorize(ctx context.Context, dbname string) (*pb.Access, error) {
+	return s.Policy.Authorize(auth.FromContext(ctx), policyName(dbname))
+}
+
+// authorizeAdmin checks that the policy lets the identity of the request
+// manage the database
+func (s *Server) authorizeAdmin(ctx context.Context, dbname string) error {
+	return s.Policy.AuthorizeAdmin(auth.FromContext(ctx), policyName(dbname))
+}
+
+// attachPolicy returns the policy applying to the databases attached by the
+// session opened by the request, nil when there is no policy
+func (s *Server) attachPolicy(ctx context.Context) func(dbname string) (*pb.Access, error) {
+	if s.Policy == nil {
+		return nil
+	}
+	id := auth.FromContext(ctx)
+	return func(dbname string) (*pb.Access, error) {
+		return s.Policy.Authorize(id, policyName(dbname))
+	}
+}
+
+// policyName returns the name of the database the policy rules match
+func policyName(dbname string) string {
+	dbname = dbwrapper.NormalizeDBName(dbname)
+	if dbname != ":memory:" {
+		dbname = path.Clean(dbname)
+	}
+	return dbname
+}
+
+// authorizeTables checks that the policy allows watching the tables of the
+// database, see auth.AllowsTables
+func (s *Server) authorizeTables(ctx context.Context, dbname string, tables []string, values bool) error {
+	access, err := s.authorize(ctx, dbname)
+	if err != nil {
+		return err
+	}
+	if !auth.AllowsTables(access, tables, values) {
+		return status.Errorf(codes.PermissionDenied, "the access to %s does not allow watching these tables", dbname)
+	}
+	return nil
+}
+
+// authorizeWrite checks that the policy allows writing to the database &
+// its schema, without table restrictions
+func (s *Server) authorizeWrite(ctx context.Context, dbname string) error {
+	access, err := s.authorize(ctx, dbname)
+	if err != nil {
+		return err
+	}
+	if access.GetMode() != pb.AccessMode_READ_WRITE || !auth.AllowsTables(access, nil, true) {
+		return status.Errorf(codes.PermissionDenied, "the access to %s does not allow managing its changelog", dbname)
+	}
+	return nil
+}
+
+// owner identifies the client of a request, the sessions it opens can only
+// be used by the same owner: its authenticated identity, else the client
+// certificate it presented, else its IP address, which is kept when the
+// client reconnects. The address is shared by the clients behind the same
+// NAT or proxy, only the secrecy of the session ids keeps them apart.
+func owner(ctx context.Context) string {
+	if id := auth.FromContext(ctx); id != nil {
+		return "identity:" + id.Name
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	if info, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(info.State.PeerCertificates) > 0 {
+		fingerprint := sha256.Sum256(info.State.PeerCertificates[0].Raw)
+		return "certificate:" + hex.EncodeToString(fingerprint[:])
+	}
+	addr := p.Addr.String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return "address:" + addr
+}
+
+// session returns the session of the client, other clients are denied & the
+// sessions that were closed, e.g. reaped once idle, are not found
+func (s *Server) session(ctx context.Context, id string) (*dbwrapper.DBWrapper, error) {
+	db, err := s.Manager.GetConnection(id, owner(ctx))
+	if errors.Is(err, connections.ErrNotOwner) {
+		slog.WarnContext(ctx, "session used by another client", "owner", owner(ctx))
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	if errors.Is(err, connections.ErrNoSession) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return db, err
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"golang.org/x/exp/slog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
+	"github.com/aousomran/sqlite-og/internal/changelog"
+	"github.com/aousomran/sqlite-og/internal/changes"
+	"github.com/aousomran/sqlite-og/internal/connections"
+)
+
+// changelogBatch is the number of changelog entries read at once
+const changelogBatch = 500
+
+// changelogPollInterval bounds how late the changes made outside of
+// sqliteogd, which are not signalled by the hub, are sent.
+var changelogPollInterval = time.Second
+
+// changelogError returns the status of the changelog errors a client cannot
+// recover from by retrying
+func changelogError(err error) error {
+	switch {
+	case errors.Is(err, changelog.ErrTokenExpired):
+		return status.Error(codes.OutOfRange, err.Error())
+	case errors.Is(err, changelog.ErrNotEnabled):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, connections.ErrInMemory):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return databaseError(err)
+	}
+}
+
+func (s *Server) EnableChangelog(ctx context.Context, in *pb.ChangelogRequest) (*pb.Empty, error) {
+	if err := s.authorizeWrite(ctx, in.GetDbName()); err != nil {
+		return nil, err
+	}
+	if err := s.Manager.EnableChangelog(ctx, in.GetDbName(), in.GetTables()); err != nil {
+		slog.ErrorContext(ctx, "cannot enable changelog", "error", err, "dbname", in.GetDbName())
+		return nil, changelogError(err)
+	}
+	return &pb.Empty{}, nil
+}
+
+func (s *Server) DisableChangelog(ctx context.Context, in *pb.ChangelogRequest) (*pb.Empty, error) {
+	if err := s.authorizeWrite(ctx, in.GetDbName()); err != nil {
+		return nil, err
+	}
+	if err := s.Manager.DisableChangelog(ctx, in.GetDbName()); err != nil {
+		slog.ErrorContext(ctx, "cannot disable changelog", "error", err, "dbname", in.GetDbName())
+		return nil, changelogError(err)
+	}
+	return &pb.Empty{}, nil
+}
+
+// Changes streams the changelog entries after the request's token, then
+// waits for new ones. Every entry carries its token, a client resuming from
+// the token of the last change it processed receives every later change at
+// least once.
+func (s *Server) Changes(in *pb.ChangesRequest, stream pb.SqliteOG_ChangesServer) error {
+	ctx := stream.Context()
+	if err := s.authorizeTables(ctx, in.GetDbName(), in.GetTables(), true); err != nil {
+		return err
+	}
+	// subscribe before reading so that no entry committed in between is missed
+	sub, err := s.Manager.WatchChangelog(in.GetDbName())
+	if err != nil {
+		return changelogError(err)
+	}
+	defer func() { sub.Close() }()
+
+	after := in.GetAfter()
+	events, err := s.Manager.ReadChangelog(ctx, in.GetDbName(), after, in.GetTables(), changelogBatch)
+	if err != nil {
+		return changelogError(err)
+	}
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "streaming changelog", "dbname", in.GetDbName(), "after", after, "tables", in.GetTables())
+
+	poll := time.NewTicker(changelogPollInterval)
+	defer poll.Stop()
+	for {
+		for _, ev := range events {
+			if err := stream.Send(ev); err != nil {
+				return err
+			}
+			after = ev.GetToken()
+		}
+		if len(events) < changelogBatch {
+			if sub, err = s.waitChangelog(ctx, in.GetDbName(), sub, poll.C); err != nil {
+				return err
+			}
+		}
+		events, err = s.Manager.ReadChangelog(ctx, in.GetDbName(), after, in.GetTables(), changelogBatch)
+		if err != nil {
+			return changelogError(err)
+		}
+	}
+}
+
+// waitChangelog returns once entries may have been logged, the subscription
+// is replaced when it overflowed.
+func (s *Server) waitChangelog(ctx context.Context, dbname string, sub *changes.Subscription, poll <-chan time.Time) (*changes.Subscription, error) {
+	select {
+	case <-ctx.Done():
+		return sub, ctx.Err()
+	case <-poll:
+	case _, ok := <-sub.Events:
+		if !ok {
+			sub.Close()
+			return s.Manager.WatchChangelog(dbname)
+		}
+	}
+	// a transaction logs many entries, drain their signals
+	for {
+		select {
+		case _, ok := <-sub.Events:
+			if !ok {
+				sub.Close()
+				return s.Manager.WatchChangelog(dbname)
+			}
+		default:
+			return sub, nil
+		}
+	}
+}
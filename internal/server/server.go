@@ -2,22 +2,30 @@ package server
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"io"
 	"sync"
 
 	"golang.org/x/exp/slog"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"vitess.io/vitess/go/vt/sqlparser"
 
 	pb "github.com/aousomran/sqlite-og/gen/proto"
+	"github.com/aousomran/sqlite-og/internal/auth"
 	"github.com/aousomran/sqlite-og/internal/connections"
+	"github.com/aousomran/sqlite-og/internal/dbwrapper"
 )
 
 type Server struct {
 	pb.UnimplementedSqliteOGServer
 	Manager *connections.Manager
+	// Policy restricts the databases & the access of the authenticated
+	// identities, every database is allowed when it's nil
+	Policy *auth.Policy
 }
 
 func New(manager *connections.Manager) *Server {
@@ -26,19 +34,93 @@ func New(manager *connections.Manager) *Server {
 	}
 }
 
-func toInterfaceSlice(s []string) []interface{} {
-	res := make([]interface{}, len(s))
-	for k, v := range s {
-		res[k] = v
+// paramsToArgs converts the typed statement parameters into the values
+// expected by database/sql, so that they are bound with their original type.
+// Named parameters are passed as sql.NamedArg and bound by sqlite to the
+// matching :name, @name or $name placeholder.
+func paramsToArgs(params []*pb.Parameter) ([]interface{}, error) {
+	res := make([]interface{}, len(params))
+	for k, v := range params {
+		var arg interface{}
+		switch val := v.GetValue().(type) {
+		case *pb.Parameter_I:
+			arg = val.I
+		case *pb.Parameter_D:
+			arg = val.D
+		case *pb.Parameter_B:
+			arg = val.B
+		case *pb.Parameter_Y:
+			arg = val.Y
+		case *pb.Parameter_S:
+			arg = val.S
+		case *pb.Parameter_Null, nil:
+			arg = nil
+		default:
+			return nil, fmt.Errorf("unsupported parameter type %T at index %d", val, k)
+		}
+		if name := v.GetName(); name != "" {
+			arg = sql.Named(name, arg)
+		}
+		res[k] = arg
+	}
+	return res, nil
+}
+
+// statementError returns PermissionDenied for the statements the session's
+// access does not allow, other errors are returned as they are.
+func statementError(err error) error {
+	if dbwrapper.IsPermissionDenied(err) {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	return err
+}
+
+// databaseError returns the status of the database names that are invalid
+// or do not exist, other errors are returned as they are.
+func databaseError(err error) error {
+	switch {
+	case errors.Is(err, dbwrapper.ErrInvalidDBName):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, dbwrapper.ErrDBNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	default:
+		return err
 	}
-	return res
+}
+
+// aggregators returns the aggregate functions of the request, the clients
+// predating aggregate_functions only name deterministic aggregators.
+func aggregators(in *pb.ConnectionRequest) []*pb.Function {
+	aggs := append([]*pb.Function{}, in.GetAggregateFunctions()...)
+	named := map[string]bool{}
+	for _, agg := range aggs {
+		named[agg.GetName()] = true
+	}
+	for _, name := range in.GetAggregators() {
+		if !named[name] {
+			aggs = append(aggs, &pb.Function{Name: name, NumArgs: -1, Deterministic: true})
+		}
+	}
+	return aggs
 }
 
 func (s *Server) Connection(ctx context.Context, in *pb.ConnectionRequest) (*pb.ConnectionId, error) {
-	id, err := s.Manager.Connect(in.GetDbName(), in.GetFunctions(), in.GetAggregators())
+	granted, err := s.authorize(ctx, in.GetDbName())
 	if err != nil {
 		return nil, err
 	}
+	id, err := s.Manager.Connect(owner(ctx), in.GetDbName(), dbwrapper.Callbacks{
+		Functions:         in.GetFunctions(),
+		Aggregators:       aggregators(in),
+		Collations:        in.GetCollations(),
+		BuiltinCollations: in.GetBuiltinCollations(),
+	}, []*pb.Access{granted, in.GetAccess()}, s.attachPolicy(ctx), in.GetOptions())
+	if errors.Is(err, dbwrapper.ErrInvalidOption) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, databaseError(err)
+	}
 	return &pb.ConnectionId{
 		Id: id,
 	}, nil
@@ -102,12 +184,16 @@ func (s *Server) Callback(cbs pb.SqliteOG_CallbackServer) error {
 		slog.Error(mdErr.Error(), "want", 1, "got", len(cnxIdSlice))
 		return mdErr
 	}
-	slog.InfoContext(ctx, "got connection id", "cnx_id", cnxIdSlice[0])
-	db, err := s.Manager.GetConnection(cnxIdSlice[0])
+	db, err := s.session(ctx, cnxIdSlice[0])
 	if err != nil {
 		slog.ErrorContext(ctx, "cannot get database from manager", "error", err)
+		return err
 	}
+	// statements waiting for a callback fail once the stream is gone
+	defer db.Channels.Disconnect()
 
+	// recvDone stops the sending go routine when the client stops streaming
+	recvDone := make(chan struct{})
 	wg := sync.WaitGroup{}
 	wg.Add(2)
 	go func() {
@@ -118,11 +204,16 @@ func (s *Server) Callback(cbs pb.SqliteOG_CallbackServer) error {
 				slog.Debug("callback go routine 1 received done")
 				wg.Done()
 				break OUTER
+			case <-recvDone:
+				slog.Debug("callback go routine 1 received end of results")
+				wg.Done()
+				break OUTER
 			case invoke := <-db.Channels.ChanSend:
 				slog.Debug("sending invoke", "func_name", invoke.GetFunctionName(), "args", invoke.Args)
 				errSend := cbs.Send(invoke)
 				if errSend != nil {
 					slog.Error("error sending invocation", "error", errSend.Error())
+					db.Channels.Disconnect()
 					wg.Done()
 					break OUTER
 				}
@@ -133,6 +224,7 @@ func (s *Server) Callback(cbs pb.SqliteOG_CallbackServer) error {
 	}()
 
 	go func() {
+		defer close(recvDone)
 	OUTER:
 		for {
 			select {
@@ -150,10 +242,11 @@ func (s *Server) Callback(cbs pb.SqliteOG_CallbackServer) error {
 					} else {
 						slog.Error("error receiving invocation result", "error", errRecv)
 					}
+					db.Channels.Disconnect()
 					wg.Done()
 					break OUTER
 				}
-				db.Channels.ChanReceive <- invokeResult
+				db.Channels.Deliver(invokeResult)
 			}
 		}
 		slog.Debug("server: end of go routine 2")
@@ -168,20 +261,21 @@ func (s *Server) Close(ctx context.Context, in *pb.ConnectionId) (*pb.Empty, err
 	if cnxId == "" {
 		return nil, fmt.Errorf("got empty database connection")
 	}
-	connection, err := s.Manager.GetConnection(cnxId)
+	connection, err := s.session(ctx, cnxId)
 	if err != nil {
-		return nil, fmt.Errorf("connection %s not found", cnxId)
+		return nil, err
 	}
+	// a connection that failed to close is unusable, forget it anyway
 	errClose := connection.Close()
+	s.Manager.DeleteConnection(cnxId)
 	if errClose != nil {
 		return nil, fmt.Errorf("unable to close connection %s, error: %s", cnxId, errClose.Error())
 	}
-	s.Manager.DeleteConnection(cnxId)
 	return &pb.Empty{}, nil
 }
 
 func (s *Server) IsValid(ctx context.Context, in *pb.ConnectionId) (*pb.Empty, error) {
-	_, err := s.Manager.GetConnection(in.GetId())
+	_, err := s.session(ctx, in.GetId())
 	if err != nil {
 		return nil, err
 	}
@@ -203,18 +297,57 @@ func (s *Server) ResetSession(ctx context.Context, in *pb.ConnectionId) (*pb.Con
 	return in, nil
 }
 
+func (s *Server) Begin(ctx context.Context, in *pb.BeginRequest) (*pb.Empty, error) {
+	db, err := s.session(ctx, in.GetCnxId())
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Begin(ctx, in.GetReadOnly(), in.GetLock()); err != nil {
+		slog.ErrorContext(ctx, "error calling db.Begin", "error", err.Error())
+		return nil, statementError(err)
+	}
+	return &pb.Empty{}, nil
+}
+
+func (s *Server) Commit(ctx context.Context, in *pb.ConnectionId) (*pb.Empty, error) {
+	db, err := s.session(ctx, in.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Commit(ctx); err != nil {
+		slog.ErrorContext(ctx, "error calling db.Commit", "error", err.Error())
+		return nil, statementError(err)
+	}
+	return &pb.Empty{}, nil
+}
+
+func (s *Server) Rollback(ctx context.Context, in *pb.ConnectionId) (*pb.Empty, error) {
+	db, err := s.session(ctx, in.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Rollback(ctx); err != nil {
+		slog.ErrorContext(ctx, "error calling db.Rollback", "error", err.Error())
+		return nil, err
+	}
+	return &pb.Empty{}, nil
+}
+
 func (s *Server) Query(ctx context.Context, in *pb.Statement) (*pb.QueryResult, error) {
-	db, err := s.Manager.GetConnection(in.GetCnxId())
+	db, err := s.session(ctx, in.GetCnxId())
 	if err != nil {
 		slog.ErrorContext(ctx, "cannot get database from manager", "error", err)
 		return nil, err
 	}
 
-	params := toInterfaceSlice(in.GetParams())
+	params, err := paramsToArgs(in.GetParams())
+	if err != nil {
+		return nil, err
+	}
 	columns, columnTypes, rows, err := db.Query(ctx, in.GetSql(), params...)
 	if err != nil {
 		slog.ErrorContext(ctx, "error calling db.Query", "error", err.Error())
-		return nil, err
+		return nil, statementError(err)
 	}
 
 	return &pb.QueryResult{
@@ -224,18 +357,143 @@ func (s *Server) Query(ctx context.Context, in *pb.Statement) (*pb.QueryResult,
 	}, nil
 }
 
+func (s *Server) QueryStream(in *pb.Statement, stream pb.SqliteOG_QueryStreamServer) error {
+	ctx := stream.Context()
+	db, err := s.session(ctx, in.GetCnxId())
+	if err != nil {
+		slog.ErrorContext(ctx, "cannot get database from manager", "error", err)
+		return err
+	}
+
+	params, err := paramsToArgs(in.GetParams())
+	if err != nil {
+		return err
+	}
+
+	// the stream's context is cancelled when the client closes its rows,
+	// which stops the cursor on the database
+	err = db.QueryBatches(ctx, stream.Send, in.GetSql(), params...)
+	if err != nil {
+		slog.ErrorContext(ctx, "error calling db.QueryBatches", "error", err.Error())
+		return statementError(err)
+	}
+	return nil
+}
+
+func (s *Server) Prepare(ctx context.Context, in *pb.Statement) (*pb.PreparedStatement, error) {
+	db, err := s.session(ctx, in.GetCnxId())
+	if err != nil {
+		return nil, err
+	}
+	prepared, err := db.Prepare(ctx, in.GetSql())
+	if err != nil {
+		slog.ErrorContext(ctx, "error calling db.Prepare", "error", err.Error())
+		return nil, statementError(err)
+	}
+	return prepared, nil
+}
+
+func (s *Server) ExecPrepared(ctx context.Context, in *pb.Statement) (*pb.ExecuteResult, error) {
+	db, err := s.session(ctx, in.GetCnxId())
+	if err != nil {
+		return nil, err
+	}
+	params, err := paramsToArgs(in.GetParams())
+	if err != nil {
+		return nil, err
+	}
+
+	lastInsertId, affectedRows, err := db.ExecutePrepared(ctx, in.GetStmtId(), params...)
+	if err != nil {
+		slog.ErrorContext(ctx, "error calling db.ExecutePrepared", "error", err.Error())
+		return nil, statementError(err)
+	}
+
+	return &pb.ExecuteResult{
+		LastInsertId: lastInsertId,
+		AffectedRows: affectedRows,
+	}, nil
+}
+
+func (s *Server) QueryPrepared(in *pb.Statement, stream pb.SqliteOG_QueryPreparedServer) error {
+	ctx := stream.Context()
+	db, err := s.session(ctx, in.GetCnxId())
+	if err != nil {
+		return err
+	}
+	params, err := paramsToArgs(in.GetParams())
+	if err != nil {
+		return err
+	}
+
+	err = db.QueryPreparedBatches(ctx, stream.Send, in.GetStmtId(), params...)
+	if err != nil {
+		slog.ErrorContext(ctx, "error calling db.QueryPreparedBatches", "error", err.Error())
+		return statementError(err)
+	}
+	return nil
+}
+
+func (s *Server) ClosePrepared(ctx context.Context, in *pb.Statement) (*pb.Empty, error) {
+	db, err := s.session(ctx, in.GetCnxId())
+	if err != nil {
+		return nil, err
+	}
+	if err := db.ClosePrepared(in.GetStmtId()); err != nil {
+		return nil, err
+	}
+	return &pb.Empty{}, nil
+}
+
+// Watch streams the changes committed to the database, the response header
+// is sent once the subscription is active. The stream ends with an error if
+// the watcher falls too far behind.
+func (s *Server) Watch(in *pb.WatchRequest, stream pb.SqliteOG_WatchServer) error {
+	ctx := stream.Context()
+	if err := s.authorizeTables(ctx, in.GetDbName(), in.GetTables(), in.GetIncludeValues()); err != nil {
+		return err
+	}
+	sub, err := s.Manager.Watch(in.GetDbName(), in.GetTables(), in.GetIncludeValues())
+	if err != nil {
+		return databaseError(err)
+	}
+	defer sub.Close()
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "watching changes", "dbname", in.GetDbName(), "tables", in.GetTables())
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-sub.Events:
+			if !ok {
+				slog.WarnContext(ctx, "watch ended", "dbname", in.GetDbName(), "error", sub.Err())
+				return sub.Err()
+			}
+			if err := stream.Send(ev); err != nil {
+				return err
+			}
+		}
+	}
+}
+
 func (s *Server) Execute(ctx context.Context, in *pb.Statement) (*pb.ExecuteResult, error) {
-	db, err := s.Manager.GetConnection(in.GetCnxId())
+	db, err := s.session(ctx, in.GetCnxId())
 	if err != nil {
 		slog.Error("cannot get database from manager", "error", err)
 		return nil, err
 	}
-	params := toInterfaceSlice(in.GetParams())
+	params, err := paramsToArgs(in.GetParams())
+	if err != nil {
+		return nil, err
+	}
 
 	lastInsertId, affectedRows, err := db.Execute(ctx, in.GetSql(), params...)
 	if err != nil {
 		slog.Error("error calling db.Execute", "error", err.Error())
-		return nil, err
+		return nil, statementError(err)
 	}
 
 	return &pb.ExecuteResult{
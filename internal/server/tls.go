@@ -0,0 +1,65 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// TLSOptions are the files of the server's certificate & of the CAs of
+// the client certificates
+type TLSOptions struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile enables client certificates, they are verified when the
+	// client presents one
+	ClientCAFile string
+	// RequireClientCert rejects the clients without a valid certificate
+	RequireClientCert bool
+}
+
+// NewTLSConfig returns the TLS configuration of the server
+func NewTLSConfig(opts TLSOptions) (*tls.Config, error) {
+	if opts.CertFile == "" || opts.KeyFile == "" {
+		return nil, fmt.Errorf("TLS requires both a certificate & a key")
+	}
+	cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the server certificate: %w", err)
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if opts.ClientCAFile == "" {
+		if opts.RequireClientCert {
+			return nil, fmt.Errorf("client certificates cannot be required without a client CA")
+		}
+		return config, nil
+	}
+	pool, err := loadCertPool(opts.ClientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	config.ClientCAs = pool
+	config.ClientAuth = tls.VerifyClientCertIfGiven
+	if opts.RequireClientCert {
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
+
+// loadCertPool returns the certificates of a PEM bundle
+func loadCertPool(file string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read CA bundle: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificate found in CA bundle %s", file)
+	}
+	return pool, nil
+}
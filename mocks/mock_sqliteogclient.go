@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/aousomran/sqlite-og/gen/proto (interfaces: SqliteOGClient)
+// Source: github.com/aousomran/sqlite-og/gen/proto (interfaces: SqliteOGClient,SqliteOG_QueryStreamClient)
 
 // Package mocks is a generated GoMock package.
 package mocks
@@ -11,6 +11,7 @@ import (
 	sqlite_og "github.com/aousomran/sqlite-og/gen/proto"
 	gomock "go.uber.org/mock/gomock"
 	grpc "google.golang.org/grpc"
+	metadata "google.golang.org/grpc/metadata"
 )
 
 // MockSqliteOGClient is a mock of SqliteOGClient interface.
@@ -36,6 +37,26 @@ func (m *MockSqliteOGClient) EXPECT() *MockSqliteOGClientMockRecorder {
 	return m.recorder
 }
 
+// Begin mocks base method.
+func (m *MockSqliteOGClient) Begin(arg0 context.Context, arg1 *sqlite_og.BeginRequest, arg2 ...grpc.CallOption) (*sqlite_og.Empty, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Begin", varargs...)
+	ret0, _ := ret[0].(*sqlite_og.Empty)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Begin indicates an expected call of Begin.
+func (mr *MockSqliteOGClientMockRecorder) Begin(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Begin", reflect.TypeOf((*MockSqliteOGClient)(nil).Begin), varargs...)
+}
+
 // Callback mocks base method.
 func (m *MockSqliteOGClient) Callback(arg0 context.Context, arg1 ...grpc.CallOption) (sqlite_og.SqliteOG_CallbackClient, error) {
 	m.ctrl.T.Helper()
@@ -56,6 +77,26 @@ func (mr *MockSqliteOGClientMockRecorder) Callback(arg0 interface{}, arg1 ...int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Callback", reflect.TypeOf((*MockSqliteOGClient)(nil).Callback), varargs...)
 }
 
+// Changes mocks base method.
+func (m *MockSqliteOGClient) Changes(arg0 context.Context, arg1 *sqlite_og.ChangesRequest, arg2 ...grpc.CallOption) (sqlite_og.SqliteOG_ChangesClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Changes", varargs...)
+	ret0, _ := ret[0].(sqlite_og.SqliteOG_ChangesClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Changes indicates an expected call of Changes.
+func (mr *MockSqliteOGClientMockRecorder) Changes(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Changes", reflect.TypeOf((*MockSqliteOGClient)(nil).Changes), varargs...)
+}
+
 // Close mocks base method.
 func (m *MockSqliteOGClient) Close(arg0 context.Context, arg1 *sqlite_og.ConnectionId, arg2 ...grpc.CallOption) (*sqlite_og.Empty, error) {
 	m.ctrl.T.Helper()
@@ -76,6 +117,46 @@ func (mr *MockSqliteOGClientMockRecorder) Close(arg0, arg1 interface{}, arg2 ...
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockSqliteOGClient)(nil).Close), varargs...)
 }
 
+// ClosePrepared mocks base method.
+func (m *MockSqliteOGClient) ClosePrepared(arg0 context.Context, arg1 *sqlite_og.Statement, arg2 ...grpc.CallOption) (*sqlite_og.Empty, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ClosePrepared", varargs...)
+	ret0, _ := ret[0].(*sqlite_og.Empty)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClosePrepared indicates an expected call of ClosePrepared.
+func (mr *MockSqliteOGClientMockRecorder) ClosePrepared(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClosePrepared", reflect.TypeOf((*MockSqliteOGClient)(nil).ClosePrepared), varargs...)
+}
+
+// Commit mocks base method.
+func (m *MockSqliteOGClient) Commit(arg0 context.Context, arg1 *sqlite_og.ConnectionId, arg2 ...grpc.CallOption) (*sqlite_og.Empty, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Commit", varargs...)
+	ret0, _ := ret[0].(*sqlite_og.Empty)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Commit indicates an expected call of Commit.
+func (mr *MockSqliteOGClientMockRecorder) Commit(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commit", reflect.TypeOf((*MockSqliteOGClient)(nil).Commit), varargs...)
+}
+
 // Connection mocks base method.
 func (m *MockSqliteOGClient) Connection(arg0 context.Context, arg1 *sqlite_og.ConnectionRequest, arg2 ...grpc.CallOption) (*sqlite_og.ConnectionId, error) {
 	m.ctrl.T.Helper()
@@ -96,6 +177,66 @@ func (mr *MockSqliteOGClientMockRecorder) Connection(arg0, arg1 interface{}, arg
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Connection", reflect.TypeOf((*MockSqliteOGClient)(nil).Connection), varargs...)
 }
 
+// DisableChangelog mocks base method.
+func (m *MockSqliteOGClient) DisableChangelog(arg0 context.Context, arg1 *sqlite_og.ChangelogRequest, arg2 ...grpc.CallOption) (*sqlite_og.Empty, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DisableChangelog", varargs...)
+	ret0, _ := ret[0].(*sqlite_og.Empty)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DisableChangelog indicates an expected call of DisableChangelog.
+func (mr *MockSqliteOGClientMockRecorder) DisableChangelog(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableChangelog", reflect.TypeOf((*MockSqliteOGClient)(nil).DisableChangelog), varargs...)
+}
+
+// EnableChangelog mocks base method.
+func (m *MockSqliteOGClient) EnableChangelog(arg0 context.Context, arg1 *sqlite_og.ChangelogRequest, arg2 ...grpc.CallOption) (*sqlite_og.Empty, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "EnableChangelog", varargs...)
+	ret0, _ := ret[0].(*sqlite_og.Empty)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnableChangelog indicates an expected call of EnableChangelog.
+func (mr *MockSqliteOGClientMockRecorder) EnableChangelog(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableChangelog", reflect.TypeOf((*MockSqliteOGClient)(nil).EnableChangelog), varargs...)
+}
+
+// ExecPrepared mocks base method.
+func (m *MockSqliteOGClient) ExecPrepared(arg0 context.Context, arg1 *sqlite_og.Statement, arg2 ...grpc.CallOption) (*sqlite_og.ExecuteResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ExecPrepared", varargs...)
+	ret0, _ := ret[0].(*sqlite_og.ExecuteResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExecPrepared indicates an expected call of ExecPrepared.
+func (mr *MockSqliteOGClientMockRecorder) ExecPrepared(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecPrepared", reflect.TypeOf((*MockSqliteOGClient)(nil).ExecPrepared), varargs...)
+}
+
 // Execute mocks base method.
 func (m *MockSqliteOGClient) Execute(arg0 context.Context, arg1 *sqlite_og.Statement, arg2 ...grpc.CallOption) (*sqlite_og.ExecuteResult, error) {
 	m.ctrl.T.Helper()
@@ -176,6 +317,26 @@ func (mr *MockSqliteOGClientMockRecorder) Ping(arg0, arg1 interface{}, arg2 ...i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockSqliteOGClient)(nil).Ping), varargs...)
 }
 
+// Prepare mocks base method.
+func (m *MockSqliteOGClient) Prepare(arg0 context.Context, arg1 *sqlite_og.Statement, arg2 ...grpc.CallOption) (*sqlite_og.PreparedStatement, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Prepare", varargs...)
+	ret0, _ := ret[0].(*sqlite_og.PreparedStatement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Prepare indicates an expected call of Prepare.
+func (mr *MockSqliteOGClientMockRecorder) Prepare(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Prepare", reflect.TypeOf((*MockSqliteOGClient)(nil).Prepare), varargs...)
+}
+
 // Query mocks base method.
 func (m *MockSqliteOGClient) Query(arg0 context.Context, arg1 *sqlite_og.Statement, arg2 ...grpc.CallOption) (*sqlite_og.QueryResult, error) {
 	m.ctrl.T.Helper()
@@ -196,6 +357,46 @@ func (mr *MockSqliteOGClientMockRecorder) Query(arg0, arg1 interface{}, arg2 ...
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockSqliteOGClient)(nil).Query), varargs...)
 }
 
+// QueryPrepared mocks base method.
+func (m *MockSqliteOGClient) QueryPrepared(arg0 context.Context, arg1 *sqlite_og.Statement, arg2 ...grpc.CallOption) (sqlite_og.SqliteOG_QueryPreparedClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "QueryPrepared", varargs...)
+	ret0, _ := ret[0].(sqlite_og.SqliteOG_QueryPreparedClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryPrepared indicates an expected call of QueryPrepared.
+func (mr *MockSqliteOGClientMockRecorder) QueryPrepared(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryPrepared", reflect.TypeOf((*MockSqliteOGClient)(nil).QueryPrepared), varargs...)
+}
+
+// QueryStream mocks base method.
+func (m *MockSqliteOGClient) QueryStream(arg0 context.Context, arg1 *sqlite_og.Statement, arg2 ...grpc.CallOption) (sqlite_og.SqliteOG_QueryStreamClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "QueryStream", varargs...)
+	ret0, _ := ret[0].(sqlite_og.SqliteOG_QueryStreamClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryStream indicates an expected call of QueryStream.
+func (mr *MockSqliteOGClientMockRecorder) QueryStream(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryStream", reflect.TypeOf((*MockSqliteOGClient)(nil).QueryStream), varargs...)
+}
+
 // ResetSession mocks base method.
 func (m *MockSqliteOGClient) ResetSession(arg0 context.Context, arg1 *sqlite_og.ConnectionId, arg2 ...grpc.CallOption) (*sqlite_og.ConnectionId, error) {
 	m.ctrl.T.Helper()
@@ -215,3 +416,166 @@ func (mr *MockSqliteOGClientMockRecorder) ResetSession(arg0, arg1 interface{}, a
 	varargs := append([]interface{}{arg0, arg1}, arg2...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetSession", reflect.TypeOf((*MockSqliteOGClient)(nil).ResetSession), varargs...)
 }
+
+// Rollback mocks base method.
+func (m *MockSqliteOGClient) Rollback(arg0 context.Context, arg1 *sqlite_og.ConnectionId, arg2 ...grpc.CallOption) (*sqlite_og.Empty, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Rollback", varargs...)
+	ret0, _ := ret[0].(*sqlite_og.Empty)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rollback indicates an expected call of Rollback.
+func (mr *MockSqliteOGClientMockRecorder) Rollback(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rollback", reflect.TypeOf((*MockSqliteOGClient)(nil).Rollback), varargs...)
+}
+
+// Watch mocks base method.
+func (m *MockSqliteOGClient) Watch(arg0 context.Context, arg1 *sqlite_og.WatchRequest, arg2 ...grpc.CallOption) (sqlite_og.SqliteOG_WatchClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Watch", varargs...)
+	ret0, _ := ret[0].(sqlite_og.SqliteOG_WatchClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Watch indicates an expected call of Watch.
+func (mr *MockSqliteOGClientMockRecorder) Watch(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watch", reflect.TypeOf((*MockSqliteOGClient)(nil).Watch), varargs...)
+}
+
+// MockSqliteOG_QueryStreamClient is a mock of SqliteOG_QueryStreamClient interface.
+type MockSqliteOG_QueryStreamClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockSqliteOG_QueryStreamClientMockRecorder
+}
+
+// MockSqliteOG_QueryStreamClientMockRecorder is the mock recorder for MockSqliteOG_QueryStreamClient.
+type MockSqliteOG_QueryStreamClientMockRecorder struct {
+	mock *MockSqliteOG_QueryStreamClient
+}
+
+// NewMockSqliteOG_QueryStreamClient creates a new mock instance.
+func NewMockSqliteOG_QueryStreamClient(ctrl *gomock.Controller) *MockSqliteOG_QueryStreamClient {
+	mock := &MockSqliteOG_QueryStreamClient{ctrl: ctrl}
+	mock.recorder = &MockSqliteOG_QueryStreamClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSqliteOG_QueryStreamClient) EXPECT() *MockSqliteOG_QueryStreamClientMockRecorder {
+	return m.recorder
+}
+
+// CloseSend mocks base method.
+func (m *MockSqliteOG_QueryStreamClient) CloseSend() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseSend")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseSend indicates an expected call of CloseSend.
+func (mr *MockSqliteOG_QueryStreamClientMockRecorder) CloseSend() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseSend", reflect.TypeOf((*MockSqliteOG_QueryStreamClient)(nil).CloseSend))
+}
+
+// Context mocks base method.
+func (m *MockSqliteOG_QueryStreamClient) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockSqliteOG_QueryStreamClientMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockSqliteOG_QueryStreamClient)(nil).Context))
+}
+
+// Header mocks base method.
+func (m *MockSqliteOG_QueryStreamClient) Header() (metadata.MD, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Header")
+	ret0, _ := ret[0].(metadata.MD)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Header indicates an expected call of Header.
+func (mr *MockSqliteOG_QueryStreamClientMockRecorder) Header() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Header", reflect.TypeOf((*MockSqliteOG_QueryStreamClient)(nil).Header))
+}
+
+// Recv mocks base method.
+func (m *MockSqliteOG_QueryStreamClient) Recv() (*sqlite_og.QueryResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(*sqlite_og.QueryResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recv indicates an expected call of Recv.
+func (mr *MockSqliteOG_QueryStreamClientMockRecorder) Recv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockSqliteOG_QueryStreamClient)(nil).Recv))
+}
+
+// RecvMsg mocks base method.
+func (m *MockSqliteOG_QueryStreamClient) RecvMsg(arg0 interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecvMsg", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockSqliteOG_QueryStreamClientMockRecorder) RecvMsg(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockSqliteOG_QueryStreamClient)(nil).RecvMsg), arg0)
+}
+
+// SendMsg mocks base method.
+func (m *MockSqliteOG_QueryStreamClient) SendMsg(arg0 interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMsg", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockSqliteOG_QueryStreamClientMockRecorder) SendMsg(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockSqliteOG_QueryStreamClient)(nil).SendMsg), arg0)
+}
+
+// Trailer mocks base method.
+func (m *MockSqliteOG_QueryStreamClient) Trailer() metadata.MD {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trailer")
+	ret0, _ := ret[0].(metadata.MD)
+	return ret0
+}
+
+// Trailer indicates an expected call of Trailer.
+func (mr *MockSqliteOG_QueryStreamClientMockRecorder) Trailer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trailer", reflect.TypeOf((*MockSqliteOG_QueryStreamClient)(nil).Trailer))
+}
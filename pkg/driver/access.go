@@ -0,0 +1,21 @@
+package driver
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
+)
+
+// Access modes of the connections, see SQLiteOGDriver.Access
+const (
+	AccessReadWrite = pb.AccessMode_READ_WRITE
+	AccessReadOnly  = pb.AccessMode_READ_ONLY
+	AccessNoDDL     = pb.AccessMode_NO_DDL
+)
+
+// IsPermissionDenied reports whether a statement failed because the access
+// of the connection does not allow it
+func IsPermissionDenied(err error) bool {
+	return status.Code(err) == codes.PermissionDenied
+}
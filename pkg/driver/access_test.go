@@ -0,0 +1,141 @@
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
+)
+
+func TestAccess(t *testing.T) {
+	s := setupSuite(t)
+
+	_, err := s.ogDB.Exec(`CREATE TABLE IF NOT EXISTS access_items (id INTEGER PRIMARY KEY, name TEXT, secret TEXT)`)
+	require.NoError(t, err)
+	_, err = s.ogDB.Exec(`INSERT INTO access_items (name, secret) VALUES ('a', 's')`)
+	require.NoError(t, err)
+
+	open := func(name string, access *pb.Access) *sql.DB {
+		sql.Register(name, &SQLiteOGDriver{Access: access})
+		db, err := sql.Open(name, s.dsn)
+		require.NoError(t, err)
+		return db
+	}
+	denied := func(db *sql.DB, query string) {
+		_, err := db.Exec(query)
+		require.Error(t, err, query)
+		require.True(t, IsPermissionDenied(err), "%s: %v", query, err)
+	}
+	connector, err := NewConnector(Config{Addr: s.addr, DBName: databaseName})
+	require.NoError(t, err)
+	require.NoError(t, connector.EnableChangelog(context.Background(), "access_items"))
+	defer connector.DisableChangelog(context.Background())
+
+	readOnly := open("og_read_only", &pb.Access{Mode: AccessReadOnly})
+	defer readOnly.Close()
+	var n int
+	require.NoError(t, readOnly.QueryRow(`SELECT count(*) FROM access_items`).Scan(&n))
+	require.Equal(t, 1, n)
+	denied(readOnly, `INSERT INTO access_items (name) VALUES ('b')`)
+	denied(readOnly, `DELETE FROM access_items`)
+	denied(readOnly, `CREATE TABLE access_other (id INTEGER)`)
+	denied(readOnly, `DROP TABLE access_items`)
+	denied(readOnly, `ATTACH DATABASE 'other.db' AS other`)
+	denied(readOnly, `PRAGMA journal_mode = DELETE`)
+	// TEMP objects are private to the session
+	_, err = readOnly.Exec(`CREATE TEMP TABLE scratch (id INTEGER)`)
+	require.NoError(t, err)
+	_, err = readOnly.Exec(`INSERT INTO scratch VALUES (1)`)
+	require.NoError(t, err)
+
+	noDDL := open("og_no_ddl", &pb.Access{Mode: AccessNoDDL})
+	defer noDDL.Close()
+	_, err = noDDL.Exec(`UPDATE access_items SET name = 'b'`)
+	require.NoError(t, err)
+	denied(noDDL, `CREATE TABLE access_other (id INTEGER)`)
+	denied(noDDL, `CREATE INDEX access_items_name ON access_items (name)`)
+	denied(noDDL, `ALTER TABLE access_items ADD COLUMN other TEXT`)
+	denied(noDDL, `DROP TABLE access_items`)
+
+	restricted := open("og_restricted", &pb.Access{
+		AllowedTables: []string{"ACCESS_ITEMS"},
+		DeniedColumns: []string{"access_items.secret"},
+	})
+	defer restricted.Close()
+	var name string
+	require.NoError(t, restricted.QueryRow(`SELECT name FROM access_items`).Scan(&name))
+	require.Equal(t, "b", name)
+	denied(restricted, `SELECT secret FROM access_items`)
+	denied(restricted, `SELECT * FROM access_items`)
+	denied(restricted, `UPDATE access_items SET secret = 'x'`)
+	denied(restricted, `SELECT * FROM example_table`)
+	denied(restricted, `PRAGMA table_info(example_table)`)
+	// the changelog triggers write to the changelog for the session
+	logged := func() int {
+		var n int
+		require.NoError(t, s.ogDB.QueryRow(`SELECT count(*) FROM _sqliteog_changelog WHERE tbl = 'access_items'`).Scan(&n))
+		return n
+	}
+	before := logged()
+	_, err = restricted.Exec(`INSERT INTO access_items (name) VALUES ('c')`)
+	require.NoError(t, err)
+	require.Equal(t, before+1, logged())
+	// nor rewrite the schema or the changelog
+	denied(restricted, `PRAGMA writable_schema = ON`)
+	denied(restricted, `DELETE FROM _sqliteog_changelog`)
+	denied(restricted, `UPDATE _sqliteog_changelog SET tbl = 'other'`)
+	denied(restricted, `DROP TABLE _sqliteog_changelog`)
+	// nor forge the changes of the tables they are denied
+	forge := `INSERT INTO _sqliteog_changelog (tbl, op, row_id, ts) VALUES ('example_table', 1, 1, 0)`
+	forged := func() int {
+		var n int
+		require.NoError(t, s.ogDB.QueryRow(`SELECT count(*) FROM _sqliteog_changelog WHERE tbl = 'example_table'`).Scan(&n))
+		return n
+	}
+	noDDLItems := open("og_no_ddl_items", &pb.Access{Mode: AccessNoDDL, AllowedTables: []string{"access_items"}})
+	defer noDDLItems.Close()
+	denied(noDDLItems, forge)
+	denied(restricted, forge)
+	tx, err := restricted.Begin()
+	require.NoError(t, err)
+	_, err = tx.Exec(forge)
+	require.True(t, IsPermissionDenied(err), "%v", err)
+	require.Error(t, tx.Commit())
+	require.Zero(t, forged())
+	before = logged()
+	_, err = noDDLItems.Exec(`UPDATE access_items SET name = 'd' WHERE name = 'c'`)
+	require.NoError(t, err)
+	require.Equal(t, before+1, logged())
+	denied(restricted, `CREATE TEMP TRIGGER access_forge AFTER INSERT ON access_items BEGIN `+forge+`; END`)
+	denied(restricted, `DROP TRIGGER _sqliteog_changelog_access_items_insert`)
+	denied(noDDLItems, `SELECT * FROM _sqliteog_changelog`)
+	denied(restricted, `SELECT count(*) FROM _sqliteog_changelog`)
+	hidden := open("og_hidden", &pb.Access{DeniedTables: []string{"access_items"}})
+	defer hidden.Close()
+	denied(hidden, `SELECT name FROM access_items`)
+	denied(hidden, `PRAGMA writable_schema = ON`)
+	denied(hidden, `DELETE FROM _sqliteog_changelog`)
+	denied(hidden, `INSERT INTO _sqliteog_changelog (tbl, op, row_id, ts) VALUES ('access_items', 1, 1, 0)`)
+	denied(hidden, `SELECT * FROM _sqliteog_changelog`)
+	_, err = hidden.Exec(`PRAGMA busy_timeout = 100`)
+	require.NoError(t, err)
+	// read only transactions are allowed to restricted sessions
+	for _, db := range []*sql.DB{readOnly, noDDL, restricted} {
+		tx, err := db.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: true})
+		require.NoError(t, err)
+		require.NoError(t, tx.QueryRow(`SELECT count(*) FROM access_items`).Scan(&n))
+		_, err = tx.Exec(`DELETE FROM access_items`)
+		require.Error(t, err)
+		require.NoError(t, tx.Rollback())
+	}
+	// prepared statements are authorized when they are prepared
+	_, err = restricted.Prepare(`SELECT secret FROM access_items WHERE id = ?`)
+	require.True(t, IsPermissionDenied(err), "%v", err)
+
+	// the other sessions keep their full access
+	require.NoError(t, s.ogDB.QueryRow(`SELECT secret FROM access_items WHERE name = 'b'`).Scan(&name))
+	require.Equal(t, "s", name)
+}
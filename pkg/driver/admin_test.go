@@ -0,0 +1,123 @@
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
+	"github.com/aousomran/sqlite-og/internal/dbwrapper"
+)
+
+// adminClient connects to the Admin service with the configuration
+func adminClient(t *testing.T, cfg Config) pb.AdminClient {
+	tlsConfig, err := cfg.tlsConfig()
+	require.NoError(t, err)
+	conn, err := grpc.Dial(cfg.Addr, cfg.dialOptions(tlsConfig)...)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return pb.NewAdminClient(conn)
+}
+
+// findDatabase returns the database of the list, nil when it's not listed
+func findDatabase(list *pb.DatabaseList, dbname string) *pb.DatabaseInfo {
+	for _, info := range list.GetDatabases() {
+		if info.GetDbName() == dbname {
+			return info
+		}
+	}
+	return nil
+}
+
+func TestAdmin(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, "sub"), 0o700))
+	addr := startDataDirServer(t, dbwrapper.DataDir{Root: root})
+	admin := adminClient(t, Config{Addr: addr})
+	ctx := context.Background()
+
+	info, err := admin.CreateDatabase(ctx, &pb.CreateDatabaseRequest{DbName: "app"})
+	require.NoError(t, err)
+	require.Equal(t, "app.db", info.GetDbName())
+	_, err = admin.CreateDatabase(ctx, &pb.CreateDatabaseRequest{DbName: "app.db"})
+	require.Equal(t, codes.AlreadyExists, status.Code(err), err)
+	_, err = admin.CreateDatabase(ctx, &pb.CreateDatabaseRequest{DbName: "../app.db"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err), err)
+
+	// the databases created can be opened without -create-databases
+	db, err := sql.Open("sqliteog", addr+"/app.db")
+	require.NoError(t, err)
+	defer db.Close()
+	session, err := db.Conn(ctx)
+	require.NoError(t, err)
+	defer session.Close()
+	_, err = session.ExecContext(ctx, `CREATE TABLE t (id INTEGER); INSERT INTO t VALUES (1), (2)`)
+	require.NoError(t, err)
+
+	list, err := admin.ListDatabases(ctx, &pb.Empty{})
+	require.NoError(t, err)
+	info = findDatabase(list, "app.db")
+	require.NotNil(t, info, list)
+	require.EqualValues(t, 1, info.GetSessions())
+	require.Equal(t, "delete", info.GetJournalMode())
+	require.EqualValues(t, 2, info.GetPageCount())
+	require.Equal(t, info.GetPageCount()*info.GetPageSize(), info.GetSize())
+
+	t.Run("templates", func(t *testing.T) {
+		info, err := admin.CreateDatabase(ctx, &pb.CreateDatabaseRequest{DbName: "sub/copy.db", Template: "app.db"})
+		require.NoError(t, err)
+		require.Equal(t, "sub/copy.db", info.GetDbName())
+		require.EqualValues(t, 0, info.GetSessions())
+
+		copyDB, err := sql.Open("sqliteog", addr+"/sub/copy.db")
+		require.NoError(t, err)
+		defer copyDB.Close()
+		var count int
+		require.NoError(t, copyDB.QueryRow(`SELECT count(*) FROM t`).Scan(&count))
+		require.Equal(t, 2, count)
+
+		_, err = admin.CreateDatabase(ctx, &pb.CreateDatabaseRequest{DbName: "other.db", Template: "missing.db"})
+		require.Equal(t, codes.NotFound, status.Code(err), err)
+	})
+
+	t.Run("rename", func(t *testing.T) {
+		_, err := admin.RenameDatabase(ctx, &pb.RenameDatabaseRequest{DbName: "app.db", NewDbName: "moved.db"})
+		require.Equal(t, codes.FailedPrecondition, status.Code(err), err)
+		_, err = admin.RenameDatabase(ctx, &pb.RenameDatabaseRequest{DbName: "sub/copy.db", NewDbName: "app.db"})
+		require.Equal(t, codes.AlreadyExists, status.Code(err), err)
+
+		info, err := admin.CreateDatabase(ctx, &pb.CreateDatabaseRequest{DbName: "old.db", Template: "app.db"})
+		require.NoError(t, err)
+		info, err = admin.RenameDatabase(ctx, &pb.RenameDatabaseRequest{DbName: "old.db", NewDbName: "sub/new.db"})
+		require.NoError(t, err)
+		require.Equal(t, "sub/new.db", info.GetDbName())
+		require.NoFileExists(t, filepath.Join(root, "old.db"))
+		require.FileExists(t, filepath.Join(root, "sub", "new.db"))
+	})
+
+	t.Run("drop", func(t *testing.T) {
+		_, err := admin.DropDatabase(ctx, &pb.DropDatabaseRequest{DbName: "app.db"})
+		require.Equal(t, codes.FailedPrecondition, status.Code(err), err)
+		_, err = admin.DropDatabase(ctx, &pb.DropDatabaseRequest{DbName: "app.db", Force: true})
+		require.NoError(t, err)
+		require.NoFileExists(t, filepath.Join(root, "app.db"))
+
+		// the session was closed
+		_, err = session.ExecContext(ctx, `INSERT INTO t VALUES (3)`)
+		require.Error(t, err)
+
+		_, err = admin.DropDatabase(ctx, &pb.DropDatabaseRequest{DbName: "app.db"})
+		require.Equal(t, codes.NotFound, status.Code(err), err)
+		list, err := admin.ListDatabases(ctx, &pb.Empty{})
+		require.NoError(t, err)
+		require.Nil(t, findDatabase(list, "app.db"))
+		require.NotNil(t, findDatabase(list, "sub/copy.db"))
+	})
+}
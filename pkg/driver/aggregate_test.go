@@ -0,0 +1,106 @@
+package driver
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregators(t *testing.T) {
+	s := setupSuite(t)
+
+	sql.Register("og_aggregates", &SQLiteOGDriver{
+		Aggregators: map[string]func() Aggregator{
+			"total_age": func() Aggregator { return &totalAggregator{} },
+		},
+		CallbacksEnabled: true,
+	})
+
+	db, err := sql.Open("og_aggregates", s.dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	query := `SELECT is_student, %s(age) FROM example_table GROUP BY is_student ORDER BY is_student`
+	groups := func(db *sql.DB, fn string) map[int]string {
+		rows, err := db.Query(fmt.Sprintf(query, fn))
+		require.NoError(t, err)
+		defer rows.Close()
+		result := map[int]string{}
+		for rows.Next() {
+			var group int
+			var total string
+			require.NoError(t, rows.Scan(&group, &total))
+			result[group] = total
+		}
+		require.NoError(t, rows.Err())
+		return result
+	}
+	expected := groups(s.sqliteDB, "sum")
+	require.NotEmpty(t, expected)
+	require.Equal(t, expected, groups(db, "total_age"))
+
+	// a group without rows is finalised without any step
+	var empty string
+	require.NoError(t, db.QueryRow(`SELECT total_age(age) FROM example_table WHERE 0`).Scan(&empty))
+	require.Equal(t, "0", empty)
+
+	// an error returned by Step fails the statement
+	err = db.QueryRow(`SELECT total_age(name) FROM example_table`).Scan(&empty)
+	require.ErrorContains(t, err, "invalid syntax")
+
+	// typed aggregators keep the types of their arguments & result
+	typed := &SQLiteOGDriver{}
+	require.NoError(t, typed.RegisterAggregator("max_age", func() *maxAggregator { return &maxAggregator{} }, false))
+	sql.Register("og_typed_aggregates", typed)
+	typedDB, err := sql.Open("og_typed_aggregates", s.dsn)
+	require.NoError(t, err)
+	defer typedDB.Close()
+	var want, got int64
+	require.NoError(t, s.sqliteDB.QueryRow(`SELECT max(age) FROM example_table`).Scan(&want))
+	var kind string
+	require.NoError(t, typedDB.QueryRow(`SELECT max_age(age), typeof(max_age(age)) FROM example_table`).Scan(&got, &kind))
+	require.Equal(t, want, got)
+	require.Equal(t, "integer", kind)
+	require.NoError(t, typedDB.QueryRow(`SELECT typeof(max_age(age)) FROM example_table WHERE 0`).Scan(&kind))
+	require.Equal(t, "null", kind)
+	err = typedDB.QueryRow(`SELECT max_age(name) FROM example_table`).Scan(&got)
+	require.ErrorContains(t, err, "argument must be an INTEGER")
+}
+
+// maxAggregator returns NULL for groups without rows
+type maxAggregator struct {
+	max *int64
+}
+
+func (a *maxAggregator) Step(v int64) {
+	if a.max == nil || v > *a.max {
+		a.max = &v
+	}
+}
+
+func (a *maxAggregator) Done() interface{} {
+	if a.max == nil {
+		return nil
+	}
+	return *a.max
+}
+
+type totalAggregator struct {
+	total int64
+}
+
+func (a *totalAggregator) Step(args ...string) error {
+	v, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return err
+	}
+	a.total += v
+	return nil
+}
+
+func (a *totalAggregator) Done() (string, error) {
+	return strconv.FormatInt(a.total, 10), nil
+}
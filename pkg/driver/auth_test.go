@@ -0,0 +1,165 @@
+package driver
+
+import (
+	"context"
+	"crypto/x509"
+	"database/sql"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
+	"github.com/aousomran/sqlite-og/internal/auth"
+	"github.com/aousomran/sqlite-og/internal/server"
+)
+
+func TestAuth(t *testing.T) {
+	ca := newCertificate(t, "ca", nil, &x509.Certificate{})
+	serverCert := newCertificate(t, "server", ca, &x509.Certificate{
+		DNSNames:    []string{"localhost"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	clientCert := newCertificate(t, "writer", ca, &x509.Certificate{
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+
+	dir := t.TempDir()
+	tokensFile := filepath.Join(dir, "tokens")
+	require.NoError(t, os.WriteFile(tokensFile, []byte("# identity token\nwriter s3cret\nreader r3ader\nmemory m3mory\nadmin adm1n\n"), 0o600))
+	tokens, err := auth.LoadTokens(tokensFile)
+	require.NoError(t, err)
+	policyFile := filepath.Join(dir, "policy.json")
+	require.NoError(t, os.WriteFile(policyFile, []byte(`{"rules": [
+		{"identities": ["admin"], "databases": ["*"], "admin": true},
+		{"identities": ["writer"], "databases": ["*"]},
+		{"identities": ["reader"], "databases": [":memory:"], "access": {"mode": "READ_ONLY"}},
+		{"identities": ["memory"], "databases": [":memory:"]}
+	]}`), 0o600))
+	policy, err := auth.LoadPolicy(policyFile)
+	require.NoError(t, err)
+
+	addr := startAuthServer(t, server.TLSOptions{
+		CertFile:     serverCert.certFile,
+		KeyFile:      serverCert.keyFile,
+		ClientCAFile: ca.certFile,
+	}, auth.Authenticators{tokens, auth.MTLS{}}, policy)
+
+	t.Run("bearer tokens", func(t *testing.T) {
+		require.NoError(t, ping(Config{Addr: addr, DBName: ":memory:", TLSCAFile: ca.certFile, Token: "s3cret"}))
+
+		err := ping(Config{Addr: addr, DBName: ":memory:", TLSCAFile: ca.certFile, Token: "wrong"})
+		require.Equal(t, codes.Unauthenticated, status.Code(err), err)
+		err = ping(Config{Addr: addr, DBName: ":memory:", TLSCAFile: ca.certFile})
+		require.Equal(t, codes.Unauthenticated, status.Code(err), err)
+
+		_, err = NewConnector(Config{Addr: addr, Token: "s3cret"})
+		require.ErrorContains(t, err, "only sent over TLS")
+
+		db, err := sql.Open("sqliteog", fmt.Sprintf("sqliteog://%s/:memory:?tls_ca=%s&token=s3cret", addr, ca.certFile))
+		require.NoError(t, err)
+		defer db.Close()
+		require.NoError(t, db.Ping())
+	})
+
+	t.Run("client certificates", func(t *testing.T) {
+		require.NoError(t, ping(Config{
+			Addr:        addr,
+			DBName:      filepath.Join(dir, "mtls.db"),
+			TLSCAFile:   ca.certFile,
+			TLSCertFile: clientCert.certFile,
+			TLSKeyFile:  clientCert.keyFile,
+		}))
+	})
+
+	t.Run("policy", func(t *testing.T) {
+		connector, err := NewConnector(Config{Addr: addr, DBName: ":memory:", TLSCAFile: ca.certFile, Token: "r3ader"})
+		require.NoError(t, err)
+		db := sql.OpenDB(connector)
+		defer db.Close()
+		var one int
+		require.NoError(t, db.QueryRow(`SELECT 1`).Scan(&one))
+		_, err = db.Exec(`CREATE TABLE t (id INTEGER)`)
+		require.True(t, IsPermissionDenied(err), err)
+
+		// the reader is not allowed to open other databases
+		err = ping(Config{Addr: addr, DBName: filepath.Join(dir, "other.db"), TLSCAFile: ca.certFile, Token: "r3ader"})
+		require.Equal(t, codes.PermissionDenied, status.Code(err), err)
+
+		// nor to attach them
+		connector, err = NewConnector(Config{Addr: addr, DBName: ":memory:", TLSCAFile: ca.certFile, Token: "m3mory"})
+		require.NoError(t, err)
+		memory := sql.OpenDB(connector)
+		defer memory.Close()
+		conn, err := memory.Conn(context.Background())
+		require.NoError(t, err)
+		defer conn.Close()
+		_, err = conn.ExecContext(context.Background(), `ATTACH '`+filepath.Join(dir, "other.db")+`' AS other`)
+		require.True(t, IsPermissionDenied(err), err)
+		_, err = conn.ExecContext(context.Background(), `VACUUM INTO '`+filepath.Join(dir, "copy.db")+`'`)
+		require.True(t, IsPermissionDenied(err), err)
+		require.NoFileExists(t, filepath.Join(dir, "copy.db"))
+		_, err = conn.ExecContext(context.Background(), `ATTACH ':memory:' AS scratch`)
+		require.NoError(t, err)
+	})
+
+	t.Run("sessions belong to their owner", func(t *testing.T) {
+		connect := func(token string) *SQLiteOGConn {
+			connector, err := NewConnector(Config{Addr: addr, DBName: ":memory:", TLSCAFile: ca.certFile, Token: token})
+			require.NoError(t, err)
+			cnx, err := connector.Connect(context.Background())
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = cnx.Close() })
+			return cnx.(*SQLiteOGConn)
+		}
+		writer, reader := connect("s3cret"), connect("r3ader")
+		require.GreaterOrEqual(t, len(writer.ID), 43, "session ids must not be guessable")
+		require.NotEqual(t, writer.ID, reader.ID)
+
+		// the reader uses the writer's session
+		id := reader.ID
+		reader.ID = writer.ID
+		_, err := reader.ExecContext(context.Background(), `CREATE TABLE t (id INTEGER)`, nil)
+		require.Equal(t, codes.PermissionDenied, status.Code(err), err)
+		require.False(t, reader.IsValid())
+		reader.ID = id
+		require.True(t, reader.IsValid())
+		require.True(t, writer.IsValid())
+	})
+
+	t.Run("admin", func(t *testing.T) {
+		dbname := filepath.Join(dir, "admin.db")
+		create := &pb.CreateDatabaseRequest{DbName: dbname}
+		writer := adminClient(t, Config{Addr: addr, TLSCAFile: ca.certFile, Token: "s3cret"})
+		_, err := writer.CreateDatabase(context.Background(), create)
+		require.Equal(t, codes.PermissionDenied, status.Code(err), err)
+		_, err = adminClient(t, Config{Addr: addr, TLSCAFile: ca.certFile}).CreateDatabase(context.Background(), create)
+		require.Equal(t, codes.Unauthenticated, status.Code(err), err)
+
+		admin := adminClient(t, Config{Addr: addr, TLSCAFile: ca.certFile, Token: "adm1n"})
+		_, err = admin.CreateDatabase(context.Background(), create)
+		require.NoError(t, err)
+		_, err = admin.DropDatabase(context.Background(), &pb.DropDatabaseRequest{DbName: dbname})
+		require.NoError(t, err)
+
+		// the reader only sees the databases it may open
+		list, err := adminClient(t, Config{Addr: addr, TLSCAFile: ca.certFile, Token: "r3ader"}).ListDatabases(context.Background(), &pb.Empty{})
+		require.NoError(t, err)
+		require.Empty(t, list.GetDatabases())
+	})
+
+	t.Run("invalid files", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(tokensFile, []byte("writer\n"), 0o600))
+		_, err := auth.LoadTokens(tokensFile)
+		require.ErrorContains(t, err, "expected `<identity> <token>`")
+		require.NoError(t, os.WriteFile(policyFile, []byte(`{"rules": [{"identities": ["x"], "databases": ["*"], "access": {"mode": "WRITE_ONLY"}}]}`), 0o600))
+		_, err = auth.LoadPolicy(policyFile)
+		require.ErrorContains(t, err, "rule 0 access")
+	})
+}
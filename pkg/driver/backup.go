@@ -0,0 +1,130 @@
+package driver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
+)
+
+// ErrChecksum is returned when a file received does not match the checksum
+// sent by the server
+var ErrChecksum = errors.New("checksum mismatch")
+
+// Backup writes a copy of the database of the dsn to w, see
+// SQLiteOGConnector.Backup
+func Backup(ctx context.Context, dsn string, w io.Writer) (int64, error) {
+	ctr, err := (&SQLiteOGDriver{}).OpenConnector(dsn)
+	if err != nil {
+		return 0, err
+	}
+	return ctr.(*SQLiteOGConnector).Backup(ctx, w)
+}
+
+// Backup writes a consistent copy of the connector's database to w, the
+// server takes it while the database is in use. It returns the size of the
+// copy, or ErrChecksum when the copy does not match the server's checksum.
+// The server must enable its Admin service.
+func (c *SQLiteOGConnector) Backup(ctx context.Context, w io.Writer) (int64, error) {
+	grpcConn, err := c.dial()
+	if err != nil {
+		return 0, err
+	}
+	defer grpcConn.Close()
+	stream, err := pb.NewAdminClient(grpcConn).Backup(ctx, &pb.BackupRequest{DbName: c.config.DBName})
+	if err != nil {
+		return 0, err
+	}
+
+	hash := sha256.New()
+	var size int64
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			return size, fmt.Errorf("backup stream ended without a checksum")
+		}
+		if err != nil {
+			return size, err
+		}
+		hash.Write(chunk.GetData())
+		n, err := w.Write(chunk.GetData())
+		size += int64(n)
+		if err != nil {
+			return size, err
+		}
+		if chunk.GetSha256() == "" {
+			continue
+		}
+		if sum := hex.EncodeToString(hash.Sum(nil)); sum != chunk.GetSha256() || size != chunk.GetSize() {
+			return size, fmt.Errorf("%w: got %d bytes with sha256 %s, want %d bytes with sha256 %s", ErrChecksum, size, sum, chunk.GetSize(), chunk.GetSha256())
+		}
+		return size, nil
+	}
+}
+
+// restoreChunkSize is the size of the chunks of the files restored
+const restoreChunkSize = 64 << 10
+
+// Restore replaces the database of the dsn, see SQLiteOGConnector.Restore
+func Restore(ctx context.Context, dsn string, r io.Reader, force bool) error {
+	ctr, err := (&SQLiteOGDriver{}).OpenConnector(dsn)
+	if err != nil {
+		return err
+	}
+	return ctr.(*SQLiteOGConnector).Restore(ctx, r, force)
+}
+
+// Restore replaces the connector's database, or creates it, with the
+// database file read from r, e.g. a backup. The server checks the file's
+// integrity before swapping it in. It's refused with
+// codes.FailedPrecondition while sessions are open on the database unless
+// force is set, the server then closes them once their running statements
+// are done. The server must enable its Admin service.
+func (c *SQLiteOGConnector) Restore(ctx context.Context, r io.Reader, force bool) error {
+	grpcConn, err := c.dial()
+	if err != nil {
+		return err
+	}
+	defer grpcConn.Close()
+	stream, err := pb.NewAdminClient(grpcConn).Restore(ctx)
+	if err != nil {
+		return err
+	}
+
+	hash := sha256.New()
+	var size int64
+	req := &pb.RestoreRequest{DbName: c.config.DBName, Force: force}
+	for {
+		buf := make([]byte, restoreChunkSize)
+		n, err := io.ReadFull(r, buf)
+		last := err == io.EOF || err == io.ErrUnexpectedEOF
+		if err != nil && !last {
+			_ = stream.CloseSend()
+			return err
+		}
+		hash.Write(buf[:n])
+		size += int64(n)
+		req.Chunk = &pb.FileChunk{Data: buf[:n]}
+		if last {
+			req.Chunk.Sha256 = hex.EncodeToString(hash.Sum(nil))
+			req.Chunk.Size = size
+		}
+		if err := stream.Send(req); err != nil {
+			// the server's error is returned by CloseAndRecv
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+		if last {
+			break
+		}
+		req = &pb.RestoreRequest{}
+	}
+	_, err = stream.CloseAndRecv()
+	return err
+}
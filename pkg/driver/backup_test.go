@@ -0,0 +1,191 @@
+package driver
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
+	"github.com/aousomran/sqlite-og/internal/dbwrapper"
+)
+
+// corruptAdmin sends backups that do not match their checksum
+type corruptAdmin struct {
+	pb.UnimplementedAdminServer
+}
+
+func (corruptAdmin) Backup(_ *pb.BackupRequest, stream pb.Admin_BackupServer) error {
+	return stream.Send(&pb.FileChunk{Data: []byte("data"), Sha256: "00", Size: 4})
+}
+
+func TestBackup(t *testing.T) {
+	root := t.TempDir()
+	addr := startDataDirServer(t, dbwrapper.DataDir{Root: root, Create: true})
+	ctx := context.Background()
+
+	db, err := sql.Open("sqliteog", addr+"/app.db")
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec(`CREATE TABLE t (id INTEGER PRIMARY KEY, data BLOB)`)
+	require.NoError(t, err)
+	// enough pages for the backup to take several steps
+	_, err = db.Exec(`WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 2000)
+		INSERT INTO t (data) SELECT randomblob(1024) FROM n`)
+	require.NoError(t, err)
+
+	t.Run("writers are not blocked", func(t *testing.T) {
+		var writes atomic.Int64
+		done := make(chan struct{})
+		writerDone := make(chan error, 1)
+		go func() {
+			for {
+				select {
+				case <-done:
+					writerDone <- nil
+					return
+				default:
+				}
+				if _, err := db.Exec(`INSERT INTO t (data) VALUES (randomblob(16))`); err != nil {
+					writerDone <- err
+					return
+				}
+				writes.Add(1)
+			}
+		}()
+
+		var buf bytes.Buffer
+		size, err := Backup(ctx, addr+"/app.db", &buf)
+		close(done)
+		require.NoError(t, err)
+		require.NoError(t, <-writerDone)
+		require.Greater(t, writes.Load(), int64(0), "the writer was blocked by the backup")
+		require.EqualValues(t, buf.Len(), size)
+
+		file := filepath.Join(t.TempDir(), "backup.db")
+		require.NoError(t, os.WriteFile(file, buf.Bytes(), 0o600))
+		backup, err := sql.Open("sqlite3", file)
+		require.NoError(t, err)
+		defer backup.Close()
+		var check string
+		require.NoError(t, backup.QueryRow(`PRAGMA integrity_check`).Scan(&check))
+		require.Equal(t, "ok", check)
+		var count int64
+		require.NoError(t, backup.QueryRow(`SELECT count(*) FROM t`).Scan(&count))
+		require.GreaterOrEqual(t, count, int64(2000))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := Backup(ctx, addr+"/missing.db", &bytes.Buffer{})
+		require.Equal(t, codes.NotFound, status.Code(err), err)
+		_, err = Backup(ctx, addr+"/:memory:", &bytes.Buffer{})
+		require.Equal(t, codes.InvalidArgument, status.Code(err), err)
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		srv := grpc.NewServer()
+		pb.RegisterAdminServer(srv, corruptAdmin{})
+		go srv.Serve(listener)
+		defer srv.Stop()
+		_, err = Backup(ctx, listener.Addr().String()+"/app.db", &bytes.Buffer{})
+		require.ErrorIs(t, err, ErrChecksum)
+	})
+}
+
+func TestRestore(t *testing.T) {
+	root := t.TempDir()
+	addr := startDataDirServer(t, dbwrapper.DataDir{Root: root, Create: true})
+	ctx := context.Background()
+
+	db, err := sql.Open("sqliteog", addr+"/app.db")
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec(`CREATE TABLE t (id INTEGER PRIMARY KEY); INSERT INTO t VALUES (1), (2), (3)`)
+	require.NoError(t, err)
+	connector, err := NewConnector(Config{Addr: addr, DBName: "app.db"})
+	require.NoError(t, err)
+	require.NoError(t, connector.EnableChangelog(ctx, "t"))
+	var backup bytes.Buffer
+	_, err = connector.Backup(ctx, &backup)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO t VALUES (4)`)
+	require.NoError(t, err)
+	count := func(db *sql.DB) int {
+		var n int
+		require.NoError(t, db.QueryRow(`SELECT count(*) FROM t`).Scan(&n))
+		return n
+	}
+
+	t.Run("open sessions", func(t *testing.T) {
+		session, err := db.Conn(ctx)
+		require.NoError(t, err)
+		defer session.Close()
+		_, err = session.ExecContext(ctx, `INSERT INTO t VALUES (5)`)
+		require.NoError(t, err)
+
+		err = connector.Restore(ctx, bytes.NewReader(backup.Bytes()), false)
+		require.Equal(t, codes.FailedPrecondition, status.Code(err), err)
+		require.NoError(t, connector.Restore(ctx, bytes.NewReader(backup.Bytes()), true))
+
+		// the session was drained & the database is the backup's
+		_, err = session.ExecContext(ctx, `INSERT INTO t VALUES (6)`)
+		require.Error(t, err)
+		restored, err := sql.Open("sqliteog", addr+"/app.db")
+		require.NoError(t, err)
+		defer restored.Close()
+		require.Equal(t, 3, count(restored))
+
+		// the changelog uses the new file
+		require.NoError(t, connector.DisableChangelog(ctx))
+		var triggers int
+		require.NoError(t, restored.QueryRow(`SELECT count(*) FROM sqlite_master WHERE type = 'trigger'`).Scan(&triggers))
+		require.Zero(t, triggers)
+	})
+
+	t.Run("new database", func(t *testing.T) {
+		require.NoError(t, Restore(ctx, addr+"/sub.db", bytes.NewReader(backup.Bytes()), false))
+		restored, err := sql.Open("sqliteog", addr+"/sub.db")
+		require.NoError(t, err)
+		defer restored.Close()
+		require.Equal(t, 3, count(restored))
+	})
+
+	t.Run("invalid files", func(t *testing.T) {
+		// the header of the second page, the root of t, is overwritten
+		corrupt := append([]byte{}, backup.Bytes()...)
+		pageSize := int(corrupt[16])<<8 | int(corrupt[17])
+		copy(corrupt[pageSize:], bytes.Repeat([]byte{0xff}, 16))
+		for _, file := range [][]byte{[]byte("not a database, not a database, not a database, not a database, not a database, not a database"), corrupt} {
+			err := connector.Restore(ctx, bytes.NewReader(file), true)
+			require.Equal(t, codes.InvalidArgument, status.Code(err), err)
+		}
+
+		// the chunks must match the checksum
+		stream, err := adminClient(t, Config{Addr: addr}).Restore(ctx)
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&pb.RestoreRequest{DbName: "app.db", Chunk: &pb.FileChunk{Data: backup.Bytes()}}))
+		require.NoError(t, stream.Send(&pb.RestoreRequest{Chunk: &pb.FileChunk{Sha256: "00", Size: int64(backup.Len())}}))
+		_, err = stream.CloseAndRecv()
+		require.Equal(t, codes.InvalidArgument, status.Code(err), err)
+
+		// the database is untouched
+		restored, err := sql.Open("sqliteog", addr+"/app.db")
+		require.NoError(t, err)
+		defer restored.Close()
+		require.Equal(t, 3, count(restored))
+		entries, err := os.ReadDir(root)
+		require.NoError(t, err)
+		for _, entry := range entries {
+			require.NotContains(t, entry.Name(), ".restore-", "temporary files are removed")
+		}
+	})
+}
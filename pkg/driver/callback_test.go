@@ -0,0 +1,196 @@
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallbacks(t *testing.T) {
+	s := setupSuite(t)
+
+	sayHello := func(args ...string) ([]string, error) {
+		return []string{"hello " + args[0]}, nil
+	}
+	fail := func(args ...string) ([]string, error) {
+		return nil, errors.New("cannot say hello to " + args[0])
+	}
+	panics := func(args ...string) ([]string, error) {
+		panic("boom")
+	}
+	slow := func(args ...string) ([]string, error) {
+		time.Sleep(2 * s.manager.CallbackTimeout)
+		return args, nil
+	}
+
+	sql.Register("og_custom", &SQLiteOGDriver{
+		Funcs: map[string]callbackFunc{
+			"say_hello": sayHello,
+			"fail":      fail,
+			"panics":    panics,
+			"slow":      slow,
+		},
+		CallbacksEnabled: true,
+	})
+
+	db, err := sql.Open("og_custom", s.dsn)
+
+	rows, err := db.Query(`select say_hello(?)`, "ao")
+	require.NoError(t, err)
+	result := ""
+	for rows.Next() {
+		errScan := rows.Scan(&result)
+		require.NoError(t, errScan)
+	}
+	require.Equal(t, "hello ao", result)
+
+	// failing callbacks fail the statement instead of the application
+	err = db.QueryRow(`select fail(?)`, "ao").Scan(&result)
+	require.ErrorContains(t, err, "cannot say hello to ao")
+	err = db.QueryRow(`select panics(?)`, "ao").Scan(&result)
+	require.ErrorContains(t, err, "boom")
+	_, err = db.Exec(`UPDATE example_table SET name = fail(name)`)
+	require.ErrorContains(t, err, "cannot say hello to")
+	err = db.QueryRow(`select slow(?)`, "ao").Scan(&result)
+	require.ErrorContains(t, err, "timed out")
+	require.NoError(t, db.QueryRow(`select say_hello(?)`, "again").Scan(&result))
+	require.Equal(t, "hello again", result)
+
+	// concurrent statements on one connection get their own results
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	require.NoError(t, err)
+	errs := make(chan error, 50)
+	wg := sync.WaitGroup{}
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var r string
+			name := strconv.Itoa(i)
+			if err := conn.QueryRowContext(ctx, `select say_hello(?)`, name).Scan(&r); err != nil {
+				errs <- err
+				return
+			}
+			if r != "hello "+name {
+				errs <- fmt.Errorf("got %q for %q", r, name)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+	require.NoError(t, conn.Close())
+
+	err = db.Close()
+	require.NoError(t, err)
+	t.Log("test done")
+}
+
+func TestTypedFunctions(t *testing.T) {
+	s := setupSuite(t)
+
+	d := &SQLiteOGDriver{}
+	require.NoError(t, d.RegisterFunc("add_ints", func(a, b int64) int64 { return a + b }, true))
+	require.NoError(t, d.RegisterFunc("add_random", func(a int64) int64 { return a + rand.Int63n(10) }, false))
+	require.NoError(t, d.RegisterFunc("join_strings", func(sep string, parts ...string) string {
+		return strings.Join(parts, sep)
+	}, true))
+	require.NoError(t, d.RegisterFunc("nullif_empty", func(v interface{}) interface{} {
+		if v == "" {
+			return nil
+		}
+		return v
+	}, true))
+	require.NoError(t, d.RegisterFunc("reverse", func(b []byte) []byte {
+		r := make([]byte, len(b))
+		for i := range b {
+			r[len(b)-1-i] = b[i]
+		}
+		return r
+	}, true))
+	require.NoError(t, d.RegisterFunc("safe_div", func(a, b float64) (float64, error) {
+		if b == 0 {
+			return 0, errors.New("division by zero")
+		}
+		return a / b, nil
+	}, true))
+	require.Error(t, d.RegisterFunc("invalid", func(a chan int) int64 { return 0 }, true))
+	sql.Register("og_typed", d)
+
+	db, err := sql.Open("og_typed", s.dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	var sum int64
+	require.NoError(t, db.QueryRow(`SELECT add_ints(?, 2)`, 40).Scan(&sum))
+	require.Equal(t, int64(42), sum)
+
+	// sqlite checks the number of arguments
+	_, err = db.Exec(`SELECT add_ints(1)`)
+	require.ErrorContains(t, err, "wrong number of arguments")
+
+	var joined string
+	require.NoError(t, db.QueryRow(`SELECT join_strings('-', 'a', 'b', 'c')`).Scan(&joined))
+	require.Equal(t, "a-b-c", joined)
+
+	var typ string
+	require.NoError(t, db.QueryRow(`SELECT typeof(nullif_empty(''))`).Scan(&typ))
+	require.Equal(t, "null", typ)
+	require.NoError(t, db.QueryRow(`SELECT typeof(nullif_empty(1.5))`).Scan(&typ))
+	require.Equal(t, "real", typ)
+
+	var reversed []byte
+	require.NoError(t, db.QueryRow(`SELECT reverse(?)`, []byte{1, 2, 3}).Scan(&reversed))
+	require.Equal(t, []byte{3, 2, 1}, reversed)
+
+	var quotient float64
+	require.NoError(t, db.QueryRow(`SELECT safe_div(1.0, 4.0)`).Scan(&quotient))
+	require.Equal(t, 0.25, quotient)
+	err = db.QueryRow(`SELECT safe_div(1.0, 0.0)`).Scan(&quotient)
+	require.ErrorContains(t, err, "division by zero")
+	err = db.QueryRow(`SELECT safe_div(1, 2)`).Scan(&quotient)
+	require.ErrorContains(t, err, "argument must be a FLOAT")
+
+	// only deterministic functions can be used in indexes
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.ExecContext(ctx, `CREATE TEMP TABLE numbers (n INTEGER)`)
+	require.NoError(t, err)
+	_, err = conn.ExecContext(ctx, `CREATE INDEX temp.numbers_add ON numbers (add_ints(n, 1))`)
+	require.NoError(t, err)
+	_, err = conn.ExecContext(ctx, `CREATE INDEX temp.numbers_random ON numbers (add_random(n))`)
+	require.ErrorContains(t, err, "non-deterministic functions prohibited")
+}
+
+func TestConnectionsDoNotRegisterDrivers(t *testing.T) {
+	s := setupSuite(t)
+
+	sql.Register("og_unregistered", &SQLiteOGDriver{
+		Funcs: map[string]callbackFunc{
+			"echo": func(args ...string) ([]string, error) { return args, nil },
+		},
+		CallbacksEnabled: true,
+	})
+	drivers := len(sql.Drivers())
+	for i := 0; i < 20; i++ {
+		db, err := sql.Open("og_unregistered", s.dsn)
+		require.NoError(t, err)
+		require.NoError(t, db.Ping())
+		require.NoError(t, db.Close())
+	}
+	require.Len(t, sql.Drivers(), drivers)
+}
@@ -0,0 +1,84 @@
+package driver
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
+)
+
+type ChangesOptions struct {
+	// After is the token of the last change processed, the changelog is
+	// streamed from its start when it's zero
+	After uint64
+	// Tables are the tables to stream, every logged table is streamed when
+	// empty
+	Tables []string
+	// MaxBackoff caps the delay between reconnection attempts (30s by default)
+	MaxBackoff time.Duration
+	// OnError is called with the error ending the stream before reconnecting
+	// or closing the channel, errors are logged when it's nil.
+	OnError func(error)
+}
+
+// Changes streams the changelog of the database of the dsn, see
+// SQLiteOGConnector.Changes.
+func Changes(ctx context.Context, dsn string, opts ChangesOptions) (<-chan Change, error) {
+	ctr, err := (&SQLiteOGDriver{}).OpenConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return ctr.(*SQLiteOGConnector).Changes(ctx, opts)
+}
+
+// EnableChangelog makes the server log the changes of the tables, or of
+// every table when none is given, to a changelog table of the connector's
+// database. Changes are logged in the transaction making them so they are
+// kept across server restarts, see Changes.
+func (c *SQLiteOGConnector) EnableChangelog(ctx context.Context, tables ...string) error {
+	return c.changelogRequest(ctx, func(client pb.SqliteOGClient, req *pb.ChangelogRequest) error {
+		req.Tables = tables
+		_, err := client.EnableChangelog(ctx, req)
+		return err
+	})
+}
+
+// DisableChangelog drops the changelog of the connector's database
+func (c *SQLiteOGConnector) DisableChangelog(ctx context.Context) error {
+	return c.changelogRequest(ctx, func(client pb.SqliteOGClient, req *pb.ChangelogRequest) error {
+		_, err := client.DisableChangelog(ctx, req)
+		return err
+	})
+}
+
+func (c *SQLiteOGConnector) changelogRequest(ctx context.Context, call func(pb.SqliteOGClient, *pb.ChangelogRequest) error) error {
+	grpcConn, err := c.dial()
+	if err != nil {
+		return err
+	}
+	defer grpcConn.Close()
+	return call(pb.NewSqliteOGClient(grpcConn), &pb.ChangelogRequest{DbName: c.config.DBName})
+}
+
+// Changes streams the changelog of the connector's database after
+// opts.After, then the changes logged afterwards, it returns once the server
+// is streaming. The stream resumes after the last change sent to the channel
+// when it's re-established. A consumer that stores the token of every change
+// it processed & resumes from it receives every change at least once, even
+// across restarts of either side.
+//
+// The channel is closed once ctx is done or when the stream cannot be
+// resumed, e.g. when the changelog is disabled or the retention deleted the
+// changes after opts.After (codes.OutOfRange), OnError receives the reason.
+func (c *SQLiteOGConnector) Changes(ctx context.Context, opts ChangesOptions) (<-chan Change, error) {
+	after := opts.After
+	return c.follow(ctx, opts.MaxBackoff, opts.OnError, func(ctx context.Context, client pb.SqliteOGClient) (changeStream, error) {
+		return client.Changes(ctx, &pb.ChangesRequest{
+			DbName: c.config.DBName,
+			After:  after,
+			Tables: opts.Tables,
+		})
+	}, func(change Change) {
+		after = change.Token
+	})
+}
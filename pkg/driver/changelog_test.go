@@ -0,0 +1,207 @@
+package driver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
+	"github.com/aousomran/sqlite-og/internal/changelog"
+	"github.com/aousomran/sqlite-og/internal/connections"
+	"github.com/aousomran/sqlite-og/internal/server"
+)
+
+func TestChangelog(t *testing.T) {
+	s := setupSuite(t)
+
+	_, err := s.ogDB.Exec(`CREATE TABLE IF NOT EXISTS logged_items (id INTEGER PRIMARY KEY, name TEXT)`)
+	require.NoError(t, err)
+	_, err = s.ogDB.Exec(`CREATE TABLE IF NOT EXISTS other_items (id INTEGER PRIMARY KEY, name TEXT)`)
+	require.NoError(t, err)
+
+	ctr, err := (&SQLiteOGDriver{}).OpenConnector(s.dsn)
+	require.NoError(t, err)
+	connector := ctr.(*SQLiteOGConnector)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// streaming requires a changelog
+	errs := make(chan error, 10)
+	_, err = connector.Changes(ctx, ChangesOptions{OnError: func(err error) { errs <- err }})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	require.NoError(t, connector.EnableChangelog(ctx, "logged_items", "other_items"))
+	defer connector.DisableChangelog(context.Background())
+
+	// changes are logged in their transaction
+	_, err = s.ogDB.Exec(`INSERT INTO logged_items (name) VALUES ('a'), ('b')`)
+	require.NoError(t, err)
+	tx, err := s.ogDB.Begin()
+	require.NoError(t, err)
+	_, err = tx.Exec(`DELETE FROM logged_items`)
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback())
+	_, err = s.ogDB.Exec(`UPDATE logged_items SET id = 10 WHERE name = 'b'`)
+	require.NoError(t, err)
+	// changes made outside of sqliteogd are logged too
+	_, err = s.sqliteDB.Exec(`INSERT INTO other_items (name) VALUES ('c')`)
+	require.NoError(t, err)
+
+	next := func(changes <-chan Change) Change {
+		select {
+		case change, ok := <-changes:
+			require.True(t, ok, "changes closed")
+			return change
+		case <-time.After(5 * time.Second):
+			t.Fatal("no change received")
+			return Change{}
+		}
+	}
+	changes, err := connector.Changes(ctx, ChangesOptions{})
+	require.NoError(t, err)
+	var logged []Change
+	for i := 0; i < 5; i++ {
+		logged = append(logged, next(changes))
+	}
+	type op struct {
+		table string
+		op    pb.ChangeOperation
+		rowid int64
+	}
+	var ops []op
+	for i, change := range logged {
+		ops = append(ops, op{change.Table, change.Operation, change.RowID})
+		if i > 0 {
+			require.Greater(t, change.Token, logged[i-1].Token)
+		}
+	}
+	require.Equal(t, []op{
+		{"logged_items", ChangeInsert, 1},
+		{"logged_items", ChangeInsert, 2},
+		{"logged_items", ChangeDelete, 2},
+		{"logged_items", ChangeUpdate, 10},
+		{"other_items", ChangeInsert, 1},
+	}, ops)
+
+	// new changes follow
+	_, err = s.ogDB.Exec(`DELETE FROM logged_items WHERE id = 1`)
+	require.NoError(t, err)
+	change := next(changes)
+	require.Equal(t, ChangeDelete, change.Operation)
+	require.Greater(t, change.Token, logged[4].Token)
+
+	// a consumer resumes after its last token & can filter tables
+	resumed, err := connector.Changes(ctx, ChangesOptions{After: logged[1].Token, Tables: []string{"LOGGED_ITEMS"}})
+	require.NoError(t, err)
+	require.Equal(t, logged[2], next(resumed))
+	require.Equal(t, logged[3], next(resumed))
+	require.Equal(t, change, next(resumed))
+
+	// compaction keeps the last change of every row
+	s.manager.ChangelogRetention = changelog.Retention{Compact: true}
+	s.manager.CompactChangelogs(ctx)
+	compacted, err := connector.Changes(ctx, ChangesOptions{})
+	require.NoError(t, err)
+	require.Equal(t, logged[2], next(compacted))
+	require.Equal(t, logged[3], next(compacted))
+	require.Equal(t, logged[4], next(compacted))
+	require.Equal(t, change, next(compacted))
+
+	// tokens of entries deleted by retention expire
+	s.manager.ChangelogRetention = changelog.Retention{MaxEntries: 1}
+	s.manager.CompactChangelogs(ctx)
+	_, err = connector.Changes(ctx, ChangesOptions{After: logged[0].Token})
+	require.Equal(t, codes.OutOfRange, status.Code(err))
+	latest, err := connector.Changes(ctx, ChangesOptions{After: logged[4].Token})
+	require.NoError(t, err)
+	require.Equal(t, change, next(latest))
+
+	// streams end once the changelog is disabled
+	require.NoError(t, connector.DisableChangelog(ctx))
+	_, err = s.ogDB.Exec(`INSERT INTO logged_items (name) VALUES ('d')`)
+	require.NoError(t, err)
+	select {
+	case _, ok := <-latest:
+		require.False(t, ok)
+	case <-time.After(5 * time.Second):
+		t.Fatal("changes were not closed")
+	}
+	var n int
+	require.NoError(t, s.sqliteDB.QueryRow(`SELECT count(*) FROM sqlite_schema WHERE name GLOB '_sqliteog_*'`).Scan(&n))
+	require.Zero(t, n)
+}
+
+func TestChangelogSurvivesRestarts(t *testing.T) {
+	s := setupSuite(t)
+
+	start := func(listener net.Listener) (*grpc.Server, *connections.Manager) {
+		manager := connections.NewManager()
+		manager.DataDir = s.dataDir
+		srv := grpc.NewServer()
+		pb.RegisterSqliteOGServer(srv, server.New(manager))
+		go srv.Serve(listener)
+		return srv, manager
+	}
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	srv, manager := start(listener)
+
+	_, err = s.sqliteDB.Exec(`CREATE TABLE IF NOT EXISTS logged_items (id INTEGER PRIMARY KEY, name TEXT)`)
+	require.NoError(t, err)
+
+	dsn := fmt.Sprintf("%s/%s", addr, databaseName)
+	ctr, err := (&SQLiteOGDriver{}).OpenConnector(dsn)
+	require.NoError(t, err)
+	connector := ctr.(*SQLiteOGConnector)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, connector.EnableChangelog(ctx))
+	defer connector.DisableChangelog(context.Background())
+
+	errs := make(chan error, 10)
+	changes, err := connector.Changes(ctx, ChangesOptions{
+		MaxBackoff: 200 * time.Millisecond,
+		OnError:    func(err error) { errs <- err },
+	})
+	require.NoError(t, err)
+	_, err = s.sqliteDB.Exec(`INSERT INTO logged_items (name) VALUES ('before')`)
+	require.NoError(t, err)
+	select {
+	case change := <-changes:
+		require.Equal(t, "logged_items", change.Table)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change received")
+	}
+
+	srv.Stop()
+	_ = manager.Close()
+	select {
+	case err := <-errs:
+		require.Error(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream failure not reported")
+	}
+	// changes made while the server is down are delivered once it's back
+	_, err = s.sqliteDB.Exec(`INSERT INTO logged_items (name) VALUES ('while down')`)
+	require.NoError(t, err)
+
+	listener, err = net.Listen("tcp", addr)
+	require.NoError(t, err)
+	srv, manager = start(listener)
+	defer manager.Close()
+	defer srv.Stop()
+	select {
+	case change := <-changes:
+		require.Equal(t, ChangeInsert, change.Operation)
+		require.Equal(t, int64(2), change.RowID)
+	case <-time.After(10 * time.Second):
+		t.Fatal("changes were not resumed")
+	}
+}
@@ -0,0 +1,86 @@
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollations(t *testing.T) {
+	s := setupSuite(t)
+
+	sql.Register("og_collations", &SQLiteOGDriver{
+		Collations: map[string]func(a, b string) int{
+			"reversed": func(a, b string) int { return strings.Compare(b, a) },
+			"failing": func(a, b string) int {
+				if a == "zebra" || b == "zebra" {
+					panic("no zebras")
+				}
+				return strings.Compare(a, b)
+			},
+		},
+		BuiltinCollations: []string{"natural", "natural_nocase", "unicode", "locale_sv"},
+		CallbacksEnabled:  true,
+	})
+
+	db, err := sql.Open("og_collations", s.dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.ExecContext(ctx, `CREATE TEMP TABLE files (name TEXT)`)
+	require.NoError(t, err)
+	for _, name := range []string{"file10", "file2", "File1", "zebra", "äpple", "apple"} {
+		_, err = conn.ExecContext(ctx, `INSERT INTO files VALUES (?)`, name)
+		require.NoError(t, err)
+	}
+
+	ordered := func(collation string) []string {
+		rows, err := conn.QueryContext(ctx, fmt.Sprintf(`SELECT name FROM files ORDER BY name COLLATE %s`, collation))
+		require.NoError(t, err)
+		defer rows.Close()
+		var names []string
+		for rows.Next() {
+			var name string
+			require.NoError(t, rows.Scan(&name))
+			names = append(names, name)
+		}
+		require.NoError(t, rows.Err())
+		return names
+	}
+	require.Equal(t, []string{"äpple", "zebra", "file2", "file10", "apple", "File1"}, ordered("reversed"))
+	require.Equal(t, []string{"File1", "apple", "file2", "file10", "zebra", "äpple"}, ordered(`"natural"`))
+	require.Equal(t, []string{"apple", "File1", "file2", "file10", "zebra", "äpple"}, ordered("natural_nocase"))
+	require.Equal(t, []string{"apple", "äpple", "File1", "file10", "file2", "zebra"}, ordered("unicode"))
+	require.Equal(t, []string{"apple", "File1", "file10", "file2", "zebra", "äpple"}, ordered("locale_sv"))
+
+	// failing client collations fail the statement, its writes are undone
+	rows, err := conn.QueryContext(ctx, `SELECT name FROM files ORDER BY name COLLATE failing`)
+	if err == nil {
+		for rows.Next() {
+		}
+		err = rows.Err()
+		require.NoError(t, rows.Close())
+	}
+	require.ErrorContains(t, err, "no zebras")
+	_, err = conn.ExecContext(ctx, `UPDATE files SET name = upper(name) WHERE name >= 'a' COLLATE failing`)
+	require.ErrorContains(t, err, "no zebras")
+	var updated int
+	require.NoError(t, conn.QueryRowContext(ctx, `SELECT count(*) FROM files WHERE name = upper(name)`).Scan(&updated))
+	require.Zero(t, updated)
+	require.Equal(t, []string{"äpple", "zebra", "file2", "file10", "apple", "File1"}, ordered("reversed"))
+
+	// unknown builtin collations fail the connection
+	sql.Register("og_bad_collation", &SQLiteOGDriver{BuiltinCollations: []string{"klingon_order"}})
+	badDB, err := sql.Open("og_bad_collation", s.dsn)
+	require.NoError(t, err)
+	defer badDB.Close()
+	require.ErrorContains(t, badDB.Ping(), "unknown builtin collation")
+}
@@ -0,0 +1,239 @@
+package driver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+)
+
+// Scheme is the scheme of URL dsns
+const Scheme = "sqliteog"
+
+// Config configures the connections to a database, see ParseDSN
+type Config struct {
+	// Addr is the host:port of the server, IPv6 hosts are enclosed in
+	// brackets: [::1]:9091
+	Addr string
+	// DBName is the database, relative to the server's -data-dir, or to
+	// its working directory when it has none, it may contain directories.
+	// The server uses its default database when it's empty.
+	DBName string
+	// Options are the PRAGMAs applied to the sessions, e.g. busy_timeout
+	Options map[string]string
+	// TLS connects to the server with TLS, it's implied by the other TLS
+	// settings
+	TLS bool
+	// TLSCAFile is a PEM bundle of the CAs verifying the server's
+	// certificate, the system's CAs are used when it's empty
+	TLSCAFile string
+	// TLSCertFile & TLSKeyFile are the client certificate presented to the
+	// servers requiring mutual TLS
+	TLSCertFile string
+	TLSKeyFile  string
+	// TLSServerName overrides the name the server's certificate is verified
+	// against, which is the host of Addr by default
+	TLSServerName string
+	// TLSConfig is used as is when it's set, the other TLS settings are then
+	// ignored
+	TLSConfig *tls.Config
+	// Token is the bearer token authenticating the client to the servers
+	// requiring one, it is only sent over TLS
+	Token string
+	// Timeout bounds opening a connection, from dialing the server until its
+	// session is open
+	Timeout time.Duration
+	// Compression compresses the messages, gzip is the only compressor
+	Compression string
+	// MaxMsgSize is the maximum size in bytes of the messages sent &
+	// received, grpc's defaults are used when it's zero
+	MaxMsgSize int
+}
+
+// ParseDSN parses a dsn, either a URL:
+//
+//	sqliteog://host:port/path/to/db.db?tls=true&timeout=5s&compress=gzip&max_msg_size=16777216&_busy_timeout=5000
+//
+// or, without the scheme, `host:port/dbname?_busy_timeout=5000`. Parameters
+// prefixed with an underscore are the PRAGMAs applied to the sessions. The
+// TLS files & server name are set with tls_ca, tls_cert, tls_key &
+// tls_server_name, the bearer token with token.
+func ParseDSN(dsn string) (*Config, error) {
+	if !strings.HasPrefix(dsn, Scheme+"://") {
+		dsn = Scheme + "://" + dsn
+	}
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("wrong dsn format, must be `sqliteog://host:port/dbname`: %w", err)
+	}
+	if u.User != nil || u.Fragment != "" {
+		return nil, fmt.Errorf("wrong dsn format, must be `sqliteog://host:port/dbname`, got `%s`", dsn)
+	}
+	cfg := &Config{
+		Addr:   u.Host,
+		DBName: strings.TrimPrefix(u.Path, "/"),
+	}
+
+	values, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("wrong dsn parameters `%s`: %w", u.RawQuery, err)
+	}
+	for key, v := range values {
+		value := v[len(v)-1]
+		if name, ok := strings.CutPrefix(key, "_"); ok && name != "" {
+			if cfg.Options == nil {
+				cfg.Options = make(map[string]string, len(values))
+			}
+			cfg.Options[name] = value
+			continue
+		}
+		switch key {
+		case "tls":
+			cfg.TLS, err = strconv.ParseBool(value)
+		case "timeout":
+			cfg.Timeout, err = time.ParseDuration(value)
+		case "tls_ca":
+			cfg.TLSCAFile = value
+		case "tls_cert":
+			cfg.TLSCertFile = value
+		case "tls_key":
+			cfg.TLSKeyFile = value
+		case "tls_server_name":
+			cfg.TLSServerName = value
+		case "token":
+			cfg.Token = value
+		case "compress":
+			cfg.Compression = value
+		case "max_msg_size":
+			cfg.MaxMsgSize, err = strconv.Atoi(value)
+		default:
+			err = fmt.Errorf("unknown dsn parameter, connection options start with an underscore")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("wrong dsn parameter `%s`: %w", key, err)
+		}
+	}
+	return cfg, cfg.validate()
+}
+
+func (c *Config) validate() error {
+	host, port, err := net.SplitHostPort(c.Addr)
+	if err != nil || host == "" || port == "" {
+		return fmt.Errorf("wrong server address `%s`, must be `host:port`", c.Addr)
+	}
+	if c.Compression != "" && c.Compression != gzip.Name {
+		return fmt.Errorf("unknown compression `%s`, only %s is supported", c.Compression, gzip.Name)
+	}
+	if c.Timeout < 0 || c.MaxMsgSize < 0 {
+		return fmt.Errorf("timeout & max message size cannot be negative")
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		return fmt.Errorf("a client certificate requires both a certificate & a key")
+	}
+	return nil
+}
+
+// tlsConfig returns the TLS configuration of the connections, nil when TLS
+// is disabled
+func (c *Config) tlsConfig() (*tls.Config, error) {
+	if c.TLSConfig != nil {
+		return c.TLSConfig, nil
+	}
+	if !c.TLS && c.TLSCAFile == "" && c.TLSCertFile == "" && c.TLSServerName == "" {
+		return nil, nil
+	}
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: c.TLSServerName,
+	}
+	if c.TLSCAFile != "" {
+		pem, err := os.ReadFile(c.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read CA bundle: %w", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in CA bundle %s", c.TLSCAFile)
+		}
+	}
+	if c.TLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.TLSCertFile, c.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load the client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// dialOptions returns the grpc options of the connections to the server,
+// TLS is disabled when tlsConfig is nil
+func (c *Config) dialOptions(tlsConfig *tls.Config) []grpc.DialOption {
+	creds := insecure.NewCredentials()
+	if tlsConfig != nil {
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	if c.Token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(tokenCredentials(c.Token)))
+	}
+	var callOpts []grpc.CallOption
+	if c.Compression != "" {
+		callOpts = append(callOpts, grpc.UseCompressor(c.Compression))
+	}
+	if c.MaxMsgSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallRecvMsgSize(c.MaxMsgSize), grpc.MaxCallSendMsgSize(c.MaxMsgSize))
+	}
+	if len(callOpts) > 0 {
+		opts = append(opts, grpc.WithDefaultCallOptions(callOpts...))
+	}
+	return opts
+}
+
+// NewConnector returns a connector to use with sql.OpenDB, its connections
+// have no callbacks & full access, see SQLiteOGDriver.NewConnector.
+func NewConnector(cfg Config) (*SQLiteOGConnector, error) {
+	return (&SQLiteOGDriver{}).NewConnector(cfg)
+}
+
+// NewConnector returns a connector opening the driver's connections with the
+// configuration, to use with sql.OpenDB.
+func (d *SQLiteOGDriver) NewConnector(cfg Config) (*SQLiteOGConnector, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	tlsConfig, err := cfg.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Token != "" && tlsConfig == nil {
+		return nil, fmt.Errorf("a token is only sent over TLS, enable it with tls=true")
+	}
+	return &SQLiteOGConnector{
+		driver:    d,
+		config:    cfg,
+		tlsConfig: tlsConfig,
+	}, nil
+}
+
+// tokenCredentials sends a bearer token with every call
+type tokenCredentials string
+
+func (t tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+func (t tokenCredentials) RequireTransportSecurity() bool {
+	return true
+}
@@ -5,39 +5,89 @@ import (
 	"database/sql/driver"
 	"errors"
 	"fmt"
+	"io"
 	"log"
-	"strings"
+	"reflect"
+	"sync"
+	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	pb "github.com/aousomran/sqlite-og/gen/proto"
 )
 
 type SQLiteOGConn struct {
-	ID                string
-	DBName            string
-	GRPCConn          *grpc.ClientConn
-	OGClient          pb.SqliteOGClient
-	Funcs             map[string]callbackFunc
+	ID     string
+	DBName string
+	// GRPCConn is closed with the connection
+	GRPCConn *grpc.ClientConn
+	OGClient pb.SqliteOGClient
+	Funcs    map[string]*function
+	// Aggregators are the aggregate functions, aggregates holds the state
+	// of every group being aggregated.
+	Aggregators     map[string]*aggregator
+	aggregates      map[int64]reflect.Value
+	aggregatesMutex sync.Mutex
+	Collations      map[string]func(a, b string) int
+	// TxLock is the locking mode used by transactions, see WithTxLock
+	TxLock            pb.TxLock
 	callbackCanceller context.CancelFunc
+	// callbackDone is closed once the callback stream ended, the server
+	// cannot call the callbacks anymore
+	callbackDone chan struct{}
 }
 
-func NewConnection(ctx context.Context, dbname string, grpcConn *grpc.ClientConn, callbacksEnabled bool, callbacks map[string]callbackFunc) (*SQLiteOGConn, error) {
+func NewConnection(ctx context.Context, dbname string, grpcConn *grpc.ClientConn, callbacksEnabled bool, callbacks map[string]*function, aggregators map[string]*aggregator, collations map[string]func(a, b string) int, builtinCollations []string, access *pb.Access, options map[string]string, timeout time.Duration) (*SQLiteOGConn, error) {
 	client := pb.NewSqliteOGClient(grpcConn)
-	funcs := make(map[string]callbackFunc)
-	var funcNames []string
+	funcs := make(map[string]*function)
+	aggs := make(map[string]*aggregator)
+	colls := make(map[string]func(a, b string) int)
+	var funcSpecs, aggSpecs []*pb.Function
+	var aggNames, collNames []string
 	if callbacksEnabled {
 		for k, v := range callbacks {
 			funcs[k] = v
-			funcNames = append(funcNames, k)
+			funcSpecs = append(funcSpecs, &pb.Function{
+				Name:          k,
+				NumArgs:       v.numArgs(),
+				Deterministic: v.deterministic,
+			})
+		}
+		for k, v := range aggregators {
+			aggs[k] = v
+			aggSpecs = append(aggSpecs, &pb.Function{
+				Name:          k,
+				NumArgs:       v.step.numArgs(),
+				Deterministic: v.deterministic,
+			})
+			aggNames = append(aggNames, k)
+		}
+		for k, v := range collations {
+			colls[k] = v
+			collNames = append(collNames, k)
 		}
 	}
 
-	cnxId, err := client.Connection(ctx, &pb.ConnectionRequest{
-		DbName:      dbname,
-		Functions:   funcNames,
-		Aggregators: nil,
+	// the deadline bounds dialing the server, which grpc does on the first
+	// call, it must not end the callback stream
+	connectCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		connectCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	cnxId, err := client.Connection(connectCtx, &pb.ConnectionRequest{
+		DbName:             dbname,
+		Functions:          funcSpecs,
+		Aggregators:        aggNames,
+		AggregateFunctions: aggSpecs,
+		Collations:         collNames,
+		BuiltinCollations:  builtinCollations,
+		Access:             access,
+		Options:            options,
 	})
 
 	if err != nil {
@@ -45,15 +95,20 @@ func NewConnection(ctx context.Context, dbname string, grpcConn *grpc.ClientConn
 	}
 
 	cnx := &SQLiteOGConn{
-		ID:       cnxId.Id,
-		DBName:   dbname,
-		GRPCConn: grpcConn,
-		OGClient: client,
-		Funcs:    funcs,
+		ID:          cnxId.Id,
+		DBName:      dbname,
+		GRPCConn:    grpcConn,
+		OGClient:    client,
+		Funcs:       funcs,
+		Aggregators: aggs,
+		aggregates:  make(map[int64]reflect.Value),
+		Collations:  colls,
 	}
 
 	if callbacksEnabled {
-		cbCtx, cancel := context.WithCancel(ctx)
+		// the stream lives as long as the connection, not as the ctx of
+		// the statement that opened it
+		cbCtx, cancel := context.WithCancel(context.Background())
 		if err := cnx.DoCallbackDance(cbCtx); err != nil {
 			cancel()
 			return nil, err
@@ -65,6 +120,10 @@ func NewConnection(ctx context.Context, dbname string, grpcConn *grpc.ClientConn
 	return cnx, nil
 }
 
+// DoCallbackDance opens the Callback stream, every invocation received on it
+// is evaluated in its own goroutine & its result is sent back with the same
+// id, so callbacks used by concurrent statements don't block each other.
+// Functions & aggregators must therefore be safe for concurrent use.
 func (c *SQLiteOGConn) DoCallbackDance(ctx context.Context) error {
 	ctx = metadata.AppendToOutgoingContext(ctx, "cnx_id", c.ID)
 	callbackClient, err := c.OGClient.Callback(ctx)
@@ -72,68 +131,137 @@ func (c *SQLiteOGConn) DoCallbackDance(ctx context.Context) error {
 		return err
 	}
 
-	receiveChannel := make(chan *pb.Invoke)
-
-	var msgsSent, msgsReceived int
+	// grpc streams do not support concurrent calls to Send & CloseSend,
+	// results of callbacks still running once the stream is closed are dropped
+	var sendMutex sync.Mutex
+	sendClosed := false
+	done := make(chan struct{})
+	c.callbackDone = done
 
 	go func() {
-	OUTER:
+		defer close(done)
+		defer func() {
+			sendMutex.Lock()
+			defer sendMutex.Unlock()
+			sendClosed = true
+			if errClose := callbackClient.CloseSend(); errClose != nil {
+				log.Printf("could not close cbClient %v", errClose)
+			}
+		}()
 		for {
-			select {
-			case <-ctx.Done():
-				log.Println("1st go routine received ctx.Done()")
-				break OUTER
-			default:
-				invoke, invErr := callbackClient.Recv()
-				if invErr != nil {
+			invoke, invErr := callbackClient.Recv()
+			if invErr != nil {
+				if ctx.Err() == nil && invErr != io.EOF {
 					log.Printf("error receiving %v\n", invErr)
 				}
-				receiveChannel <- invoke
-				msgsReceived++
+				return
 			}
-		}
-	}()
-
-	go func() {
-	OUTER:
-		for {
-			select {
-			case <-ctx.Done():
-				if errClose := callbackClient.CloseSend(); errClose != nil {
-					log.Printf("could not close cbClient %v", errClose)
+			go func() {
+				result := &pb.InvocationResult{Id: invoke.GetId()}
+				evaluate, errEval := c.evaluate(invoke)
+				if errEval != nil {
+					result.Error = errEval.Error()
+				} else {
+					result.Result = evaluate
 				}
-				log.Println("2nd go routine received ctx.Done()")
-				break OUTER
-			case invoke := <-receiveChannel:
-				funcName := invoke.GetFunctionName()
-				callable, ok := c.Funcs[funcName]
-				if !ok {
-					log.Fatalf("requested function name that does not exist %s", funcName)
+				sendMutex.Lock()
+				defer sendMutex.Unlock()
+				if sendClosed {
+					return
 				}
-				evaluate := callable(invoke.Args...)
-				errSend := callbackClient.Send(&pb.InvocationResult{
-					Initial: false,
-					Result:  evaluate,
-				})
+				errSend := callbackClient.Send(result)
 				if errSend != nil {
 					log.Printf("got an error sending invocation result %v\n", errSend)
 				}
-				msgsSent++
-			}
+			}()
 		}
 	}()
 
 	return nil
 }
 
+// evaluate calls the function or aggregator requested by the server, errors
+// & panics are sent back to the server, which fails the statement with them.
+func (c *SQLiteOGConn) evaluate(invoke *pb.Invoke) (result *pb.Value, err error) {
+	funcName := invoke.GetFunctionName()
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, fmt.Errorf("callback %s panicked: %v", funcName, r)
+		}
+	}()
+	switch invoke.GetType() {
+	case pb.InvokeType_AGGREGATE_STEP, pb.InvokeType_AGGREGATE_DONE:
+		agg, ok := c.Aggregators[funcName]
+		if !ok {
+			return nil, fmt.Errorf("no such aggregator: %s", funcName)
+		}
+		state := c.aggregate(agg, invoke)
+		if invoke.GetType() == pb.InvokeType_AGGREGATE_STEP {
+			return nil, agg.stepOn(state, invoke.GetArgs())
+		}
+		return agg.doneOn(state)
+	case pb.InvokeType_COLLATION:
+		cmp, ok := c.Collations[funcName]
+		if !ok {
+			return nil, fmt.Errorf("no such collation: %s", funcName)
+		}
+		if len(invoke.GetArgs()) != 2 {
+			return nil, fmt.Errorf("collation %s expects 2 arguments, got %d", funcName, len(invoke.GetArgs()))
+		}
+		args := valuesToStrings(invoke.GetArgs())
+		return &pb.Value{Value: &pb.Value_Integer{Integer: int64(cmp(args[0], args[1]))}}, nil
+	default:
+		fn, ok := c.Funcs[funcName]
+		if !ok {
+			return nil, fmt.Errorf("no such function: %s", funcName)
+		}
+		return fn.call(invoke.GetArgs())
+	}
+}
+
+// aggregate returns the state of the invocation's group, it's forgotten once
+// the group is done. A group without rows only gets the done invocation.
+func (c *SQLiteOGConn) aggregate(agg *aggregator, invoke *pb.Invoke) reflect.Value {
+	c.aggregatesMutex.Lock()
+	defer c.aggregatesMutex.Unlock()
+	state, ok := c.aggregates[invoke.GetStateId()]
+	if !ok {
+		state = agg.newState()
+		c.aggregates[invoke.GetStateId()] = state
+	}
+	if invoke.GetType() == pb.InvokeType_AGGREGATE_DONE {
+		delete(c.aggregates, invoke.GetStateId())
+	}
+	return state
+}
+
+// forgetAggregates drops the state of the groups whose statement ended
+// before they were done, e.g. when it failed or was interrupted
+func (c *SQLiteOGConn) forgetAggregates() {
+	c.aggregatesMutex.Lock()
+	defer c.aggregatesMutex.Unlock()
+	clear(c.aggregates)
+}
+
 func (c *SQLiteOGConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *SQLiteOGConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
 	if query == "" {
 		return nil, errors.New("query is empty")
 	}
+	prepared, err := c.OGClient.Prepare(ctx, &pb.Statement{
+		Sql:   query,
+		CnxId: c.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
 	return &SQLiteOGStmt{
 		c:        c,
-		sql:      query,
-		numInput: strings.Count(query, "?"),
+		id:       prepared.GetId(),
+		numInput: int(prepared.GetNumInput()),
 	}, nil
 }
 
@@ -141,15 +269,13 @@ func (c *SQLiteOGConn) Close() error {
 	if c.callbackCanceller != nil {
 		c.callbackCanceller()
 	}
-	if _, err := c.OGClient.Close(context.Background(), &pb.ConnectionId{Id: c.ID}); err != nil {
-		return err
+	_, err := c.OGClient.Close(context.Background(), &pb.ConnectionId{Id: c.ID})
+	if c.GRPCConn != nil {
+		if errClose := c.GRPCConn.Close(); err == nil {
+			err = errClose
+		}
 	}
-	return nil
-}
-
-func (c *SQLiteOGConn) Begin() (driver.Tx, error) {
-	//TODO implement me
-	panic("implement me")
+	return err
 }
 
 func (c *SQLiteOGConn) Ping(ctx context.Context) error {
@@ -160,8 +286,33 @@ func (c *SQLiteOGConn) Ping(ctx context.Context) error {
 	return nil
 }
 
+// callbacksLost reports whether the callback stream of the connection ended,
+// e.g. when the server went away
+func (c *SQLiteOGConn) callbacksLost() bool {
+	if c.callbackDone == nil {
+		return false
+	}
+	select {
+	case <-c.callbackDone:
+		return true
+	default:
+		return false
+	}
+}
+
+// ResetSession checks the session before database/sql reuses the
+// connection, the server closes the sessions that stay idle for too long,
+// the connection is then replaced, as it is when its callback stream ended.
 func (c *SQLiteOGConn) ResetSession(ctx context.Context) error {
+	if c.callbacksLost() {
+		return driver.ErrBadConn
+	}
+	// no statement runs on a connection being reused
+	c.forgetAggregates()
 	_, err := c.OGClient.ResetSession(ctx, &pb.ConnectionId{Id: c.ID})
+	if status.Code(err) == codes.NotFound {
+		return driver.ErrBadConn
+	}
 	if err != nil {
 		return err
 	}
@@ -169,6 +320,9 @@ func (c *SQLiteOGConn) ResetSession(ctx context.Context) error {
 }
 
 func (c *SQLiteOGConn) IsValid() bool {
+	if c.callbacksLost() {
+		return false
+	}
 	_, err := c.OGClient.IsValid(context.Background(), &pb.ConnectionId{Id: c.ID})
 	if err != nil {
 		return false
@@ -203,21 +357,62 @@ func (c *SQLiteOGConn) QueryContext(ctx context.Context, query string, args []dr
 		Params: params,
 		CnxId:  c.ID,
 	}
-	pbr, err := c.OGClient.Query(ctx, stmt)
+	streamCtx, cancel := context.WithCancel(context.Background())
+	stream, err := c.OGClient.QueryStream(streamCtx, stmt)
 	if err != nil {
+		cancel()
 		return nil, err
 	}
-	return rowsFromPB(pbr)
+	return rowsFromStream(stream, cancel)
+}
+
+// CheckNamedValue implements driver.NamedValueChecker, values are converted
+// with the default converter while their names are kept, so that named
+// (:name, @name, $name) and ordinal parameters can be mixed in one statement.
+func (c *SQLiteOGConn) CheckNamedValue(nv *driver.NamedValue) error {
+	var err error
+	nv.Value, err = driver.DefaultParameterConverter.ConvertValue(nv.Value)
+	return err
 }
 
-func namedValuesToParams(namedValues []driver.NamedValue) ([]string, error) {
-	// TODO: ignoring the "Name" now, this should be reviewed (see definition of driver.NamedValue)
-	params := make([]string, len(namedValues))
+func namedValuesToParams(namedValues []driver.NamedValue) ([]*pb.Parameter, error) {
+	params := make([]*pb.Parameter, len(namedValues))
 	for _, v := range namedValues {
-		if v.Ordinal < 1 {
-			return nil, fmt.Errorf("ordinal cannot be < 1 %s %v", v.Name, v.Value)
+		if v.Ordinal < 1 || v.Ordinal > len(namedValues) {
+			return nil, fmt.Errorf("ordinal out of range %s %v", v.Name, v.Value)
 		}
-		params[v.Ordinal-1] = fmt.Sprintf("%v", v.Value)
+		param, err := valueToParam(v.Value)
+		if err != nil {
+			return nil, err
+		}
+		param.Name = v.Name
+		params[v.Ordinal-1] = param
 	}
 	return params, nil
 }
+
+func valueToParam(value driver.Value) (*pb.Parameter, error) {
+	switch v := value.(type) {
+	case nil:
+		return &pb.Parameter{Value: &pb.Parameter_Null{Null: &pb.Empty{}}}, nil
+	case int64:
+		return &pb.Parameter{Value: &pb.Parameter_I{I: v}}, nil
+	case float64:
+		return &pb.Parameter{Value: &pb.Parameter_D{D: v}}, nil
+	case bool:
+		return &pb.Parameter{Value: &pb.Parameter_B{B: v}}, nil
+	case []byte:
+		return &pb.Parameter{Value: &pb.Parameter_Y{Y: v}}, nil
+	case string:
+		return &pb.Parameter{Value: &pb.Parameter_S{S: v}}, nil
+	case time.Time:
+		return &pb.Parameter{Value: &pb.Parameter_S{S: v.Format(timestampFormats[0])}}, nil
+	default:
+		// values that did not go through database/sql's converter (e.g. int)
+		converted, err := driver.DefaultParameterConverter.ConvertValue(v)
+		if err != nil {
+			return nil, err
+		}
+		return valueToParam(converted)
+	}
+}
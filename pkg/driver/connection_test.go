@@ -2,13 +2,17 @@ package driver
 
 import (
 	"context"
+	"database/sql"
 	"database/sql/driver"
 	"fmt"
 	pb "github.com/aousomran/sqlite-og/gen/proto"
 	"github.com/aousomran/sqlite-og/mocks"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
+	"io"
+	"reflect"
 	"testing"
+	"time"
 )
 
 const testConnectionId = "fc11e35e-932d-48eb-9c53-1f9e20c8d514"
@@ -42,8 +46,17 @@ func TestSQLiteOGConn_ResetSession(t *testing.T) {
 	}
 	cnxId := &pb.ConnectionId{Id: testConnectionId}
 	client.EXPECT().ResetSession(gomock.Any(), cnxId).Return(cnxId, nil).Times(1)
+	// the groups left by the statements that failed are dropped
+	conn.aggregates = map[int64]reflect.Value{1: reflect.ValueOf(&totalAggregator{})}
 	err := conn.ResetSession(ctx)
 	assert.NoError(t, err)
+	assert.Empty(t, conn.aggregates)
+
+	// the connection is replaced once its callback stream ended
+	conn.callbackDone = make(chan struct{})
+	close(conn.callbackDone)
+	err = conn.ResetSession(ctx)
+	assert.ErrorIs(t, err, driver.ErrBadConn)
 }
 
 func TestSQLiteOGConn_IsValid(t *testing.T) {
@@ -63,24 +76,50 @@ func TestSQLiteOGConn_IsValid(t *testing.T) {
 	client.EXPECT().IsValid(gomock.Any(), cnxId).Return(&pb.Empty{}, fmt.Errorf("an error")).Times(1)
 	isValid = conn.IsValid()
 	assert.False(t, isValid)
+
+	conn.callbackDone = make(chan struct{})
+	close(conn.callbackDone)
+	assert.False(t, conn.IsValid())
 }
 
 func TestSQLiteOGConn_Prepare(t *testing.T) {
-	conn := &SQLiteOGConn{}
-	t.Run("sql with 2 qmark returns a statement", func(t *testing.T) {
-		sql := `select * from mytable where mycolumn=? and myothercolumn=?`
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mocks.NewMockSqliteOGClient(ctrl)
+	conn := &SQLiteOGConn{
+		ID:       testConnectionId,
+		GRPCConn: nil,
+		OGClient: client,
+	}
+
+	t.Run("statement uses the handle & parameter count from the server", func(t *testing.T) {
+		sql := `select * from mytable where mycolumn=? and myothercolumn='?'`
+		client.EXPECT().Prepare(gomock.Any(), &pb.Statement{Sql: sql, CnxId: testConnectionId}).
+			Return(&pb.PreparedStatement{Id: "1", NumInput: 1}, nil).Times(1)
 		stmt, err := conn.Prepare(sql)
 		assert.NoError(t, err)
 		assert.NotNil(t, stmt)
-		assert.Equal(t, 2, stmt.NumInput())
-	})
+		assert.Equal(t, 1, stmt.NumInput())
 
-	t.Run("sql with no qmark returns a statement", func(t *testing.T) {
-		sql := `select * from mytable`
-		stmt, err := conn.Prepare(sql)
+		client.EXPECT().ExecPrepared(gomock.Any(), &pb.Statement{
+			CnxId:  testConnectionId,
+			StmtId: "1",
+			Params: []*pb.Parameter{{Value: &pb.Parameter_I{I: 1}}},
+		}).Return(&pb.ExecuteResult{AffectedRows: 1}, nil).Times(1)
+		_, err = stmt.(driver.StmtExecContext).ExecContext(context.Background(), []driver.NamedValue{{Ordinal: 1, Value: int64(1)}})
 		assert.NoError(t, err)
-		assert.NotNil(t, stmt)
-		assert.Equal(t, 0, stmt.NumInput())
+
+		client.EXPECT().ClosePrepared(gomock.Any(), &pb.Statement{CnxId: testConnectionId, StmtId: "1"}).
+			Return(&pb.Empty{}, nil).Times(1)
+		assert.NoError(t, stmt.Close())
+	})
+
+	t.Run("server errors are returned", func(t *testing.T) {
+		client.EXPECT().Prepare(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("syntax error")).Times(1)
+		stmt, err := conn.Prepare("selec 1")
+		assert.Error(t, err)
+		assert.Nil(t, stmt)
 	})
 
 	t.Run("empty query returns an error", func(t *testing.T) {
@@ -109,22 +148,49 @@ func TestSQLiteOGConn_QueryContext(t *testing.T) {
 		},
 	}
 
-	client.EXPECT().Query(gomock.Any(), gomock.Any()).Return(&pb.QueryResult{
-		Columns:     []string{"col1", "col2"},
-		ColumnTypes: []string{"NUMBER", "TEXT"},
-		Rows: []*pb.Row{
-			{
-				Fields: []string{"1", "two"},
+	stream := mocks.NewMockSqliteOG_QueryStreamClient(ctrl)
+	client.EXPECT().QueryStream(gomock.Any(), gomock.Any()).Return(stream, nil).Times(1)
+	gomock.InOrder(
+		stream.EXPECT().Recv().Return(&pb.QueryResult{
+			Columns:     []string{"col1", "col2", "col3"},
+			ColumnTypes: []string{"NUMBER", "TEXT", "BLOB"},
+		}, nil),
+		stream.EXPECT().Recv().Return(&pb.QueryResult{
+			Rows: []*pb.Row{
+				{
+					Fields: []*pb.Value{
+						{Value: &pb.Value_Integer{Integer: 1}},
+						{Value: &pb.Value_Text{Text: "two"}},
+						{Value: &pb.Value_Blob{Blob: []byte{3}}},
+					},
+				},
 			},
-			{
-				Fields: []string{"3", "four"},
+		}, nil),
+		stream.EXPECT().Recv().Return(&pb.QueryResult{
+			Rows: []*pb.Row{
+				{
+					Fields: []*pb.Value{
+						{Value: &pb.Value_Real{Real: 4.5}},
+						{Value: &pb.Value_Text{Text: ""}},
+						{Value: &pb.Value_Null{Null: &pb.Empty{}}},
+					},
+				},
 			},
-		},
-	}, nil).Times(1)
+		}, nil),
+		stream.EXPECT().Recv().Return(nil, io.EOF),
+	)
 	rows, err := conn.QueryContext(ctx, sql, args)
 	assert.NoError(t, err)
 	assert.NotNil(t, rows)
 
+	dest := make([]driver.Value, 3)
+	assert.NoError(t, rows.Next(dest))
+	assert.Equal(t, []driver.Value{int64(1), "two", []byte{3}}, dest)
+	assert.NoError(t, rows.Next(dest))
+	assert.Equal(t, []driver.Value{4.5, "", nil}, dest)
+	assert.ErrorIs(t, rows.Next(dest), io.EOF)
+	assert.ErrorIs(t, rows.Next(dest), io.EOF)
+	assert.NoError(t, rows.Close())
 }
 
 func TestSQLiteOGConn_ExecContext(t *testing.T) {
@@ -164,6 +230,42 @@ func TestSQLiteOGConn_ExecContext(t *testing.T) {
 	assert.Equal(t, int64(1), rowsAffected)
 }
 
+func TestSQLiteOGConn_BeginTx(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mocks.NewMockSqliteOGClient(ctrl)
+	conn := &SQLiteOGConn{
+		ID:       testConnectionId,
+		GRPCConn: nil,
+		OGClient: client,
+	}
+	cnxId := &pb.ConnectionId{Id: testConnectionId}
+
+	t.Run("read only transaction is committed", func(t *testing.T) {
+		client.EXPECT().Begin(gomock.Any(), &pb.BeginRequest{CnxId: testConnectionId, ReadOnly: true}).Return(&pb.Empty{}, nil).Times(1)
+		client.EXPECT().Commit(gomock.Any(), cnxId).Return(&pb.Empty{}, nil).Times(1)
+		tx, err := conn.BeginTx(context.Background(), driver.TxOptions{ReadOnly: true})
+		assert.NoError(t, err)
+		assert.NoError(t, tx.Commit())
+	})
+
+	t.Run("locking mode from context is used", func(t *testing.T) {
+		ctx := WithTxLock(context.Background(), TxLockImmediate)
+		client.EXPECT().Begin(gomock.Any(), &pb.BeginRequest{CnxId: testConnectionId, Lock: TxLockImmediate}).Return(&pb.Empty{}, nil).Times(1)
+		client.EXPECT().Rollback(gomock.Any(), cnxId).Return(&pb.Empty{}, nil).Times(1)
+		tx, err := conn.BeginTx(ctx, driver.TxOptions{})
+		assert.NoError(t, err)
+		assert.NoError(t, tx.Rollback())
+	})
+
+	t.Run("unsupported isolation level returns an error", func(t *testing.T) {
+		tx, err := conn.BeginTx(context.Background(), driver.TxOptions{Isolation: driver.IsolationLevel(sql.LevelReadCommitted)})
+		assert.Error(t, err)
+		assert.Nil(t, tx)
+	})
+}
+
 func Test_namedValuesToParams(t *testing.T) {
 	t.Run("namedValues are in order", func(t *testing.T) {
 		namedValues := []driver.NamedValue{
@@ -180,7 +282,11 @@ func Test_namedValuesToParams(t *testing.T) {
 				Value:   "three",
 			},
 		}
-		expected := []string{"one", "2", "three"}
+		expected := []*pb.Parameter{
+			{Value: &pb.Parameter_S{S: "one"}},
+			{Value: &pb.Parameter_I{I: 2}},
+			{Value: &pb.Parameter_S{S: "three"}},
+		}
 		actual, err := namedValuesToParams(namedValues)
 		assert.NoError(t, err)
 		assert.NotNil(t, actual)
@@ -202,7 +308,11 @@ func Test_namedValuesToParams(t *testing.T) {
 				Value:   2,
 			},
 		}
-		expected := []string{"one", "2", "three"}
+		expected := []*pb.Parameter{
+			{Value: &pb.Parameter_S{S: "one"}},
+			{Value: &pb.Parameter_I{I: 2}},
+			{Value: &pb.Parameter_S{S: "three"}},
+		}
 		actual, err := namedValuesToParams(namedValues)
 		assert.NoError(t, err)
 		assert.NotNil(t, actual)
@@ -224,10 +334,43 @@ func Test_namedValuesToParams(t *testing.T) {
 				Value:   2,
 			},
 		}
-		expected := []string{"one", "2", "three"}
 		actual, err := namedValuesToParams(namedValues)
 		assert.Error(t, err)
 		assert.Nil(t, actual)
-		assert.NotEqual(t, expected, actual)
+	})
+
+	t.Run("values keep their type", func(t *testing.T) {
+		ts := time.Date(2023, 9, 1, 10, 30, 0, 0, time.UTC)
+		namedValues := []driver.NamedValue{
+			{Ordinal: 1, Value: nil},
+			{Ordinal: 2, Value: []byte{1, 2, 3}},
+			{Ordinal: 3, Value: 1.5},
+			{Ordinal: 4, Value: true},
+			{Ordinal: 5, Value: ts},
+		}
+		expected := []*pb.Parameter{
+			{Value: &pb.Parameter_Null{Null: &pb.Empty{}}},
+			{Value: &pb.Parameter_Y{Y: []byte{1, 2, 3}}},
+			{Value: &pb.Parameter_D{D: 1.5}},
+			{Value: &pb.Parameter_B{B: true}},
+			{Value: &pb.Parameter_S{S: "2023-09-01 10:30:00+00:00"}},
+		}
+		actual, err := namedValuesToParams(namedValues)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("names are kept", func(t *testing.T) {
+		namedValues := []driver.NamedValue{
+			{Ordinal: 1, Value: int64(5), Name: "id"},
+			{Ordinal: 2, Value: "two"},
+		}
+		expected := []*pb.Parameter{
+			{Value: &pb.Parameter_I{I: 5}, Name: "id"},
+			{Value: &pb.Parameter_S{S: "two"}},
+		}
+		actual, err := namedValuesToParams(namedValues)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
 	})
 }
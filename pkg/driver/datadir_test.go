@@ -0,0 +1,171 @@
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
+	"github.com/aousomran/sqlite-og/internal/connections"
+	"github.com/aousomran/sqlite-og/internal/dbwrapper"
+	"github.com/aousomran/sqlite-og/internal/server"
+)
+
+// startDataDirServer starts a plaintext server confined to the data
+// directory, with the Admin service
+func startDataDirServer(t *testing.T, dataDir dbwrapper.DataDir) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	manager := connections.NewManager()
+	manager.DataDir = dataDir
+	srv := grpc.NewServer()
+	sqliteOG := server.New(manager)
+	pb.RegisterSqliteOGServer(srv, sqliteOG)
+	pb.RegisterAdminServer(srv, server.NewAdmin(sqliteOG))
+	go srv.Serve(listener)
+	t.Cleanup(func() {
+		srv.Stop()
+		_ = manager.Close()
+	})
+	return listener.Addr().String()
+}
+
+func TestDataDir(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, "sub"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "secret.db"), nil, 0o600))
+	require.NoError(t, os.Symlink(outside, filepath.Join(root, "escape")))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "secret.db"), filepath.Join(root, "secret.db")))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "missing.db"), filepath.Join(root, "dangling.db")))
+
+	t.Run("names are confined", func(t *testing.T) {
+		addr := startDataDirServer(t, dbwrapper.DataDir{Root: root, Create: true})
+
+		for _, dbname := range []string{"app", "sub/app.db", "sub/../other.db", ":memory:"} {
+			require.NoError(t, ping(Config{Addr: addr, DBName: dbname}), dbname)
+		}
+		require.FileExists(t, filepath.Join(root, "app.db"))
+		require.FileExists(t, filepath.Join(root, "sub", "app.db"))
+		require.FileExists(t, filepath.Join(root, "other.db"))
+
+		for _, dbname := range []string{
+			"../escape.db",
+			"sub/../../escape.db",
+			filepath.Join(outside, "absolute.db"),
+			"escape/secret.db",
+			"escape/new.db",
+			"secret.db",
+			"dangling.db",
+			"file:app.db?vfs=unix-none",
+			"app.db?mode=memory",
+		} {
+			err := ping(Config{Addr: addr, DBName: dbname})
+			require.Equal(t, codes.InvalidArgument, status.Code(err), "%s: %v", dbname, err)
+		}
+		require.NoFileExists(t, filepath.Join(outside, "new.db"))
+		require.NoFileExists(t, filepath.Join(outside, "absolute.db"))
+
+		err := ping(Config{Addr: addr, DBName: "missing/app.db"})
+		require.Equal(t, codes.NotFound, status.Code(err), err)
+
+		// links are opened as their target is named
+		require.NoError(t, os.WriteFile(filepath.Join(root, "real.sqlite"), nil, 0o600))
+		require.NoError(t, os.Symlink("real.sqlite", filepath.Join(root, "link.db")))
+		require.NoError(t, ping(Config{Addr: addr, DBName: "link.db"}))
+		require.NoFileExists(t, filepath.Join(root, "real.sqlite.db"))
+	})
+
+	t.Run("databases are not created", func(t *testing.T) {
+		addr := startDataDirServer(t, dbwrapper.DataDir{Root: root})
+
+		require.NoError(t, ping(Config{Addr: addr, DBName: "app.db"}))
+		err := ping(Config{Addr: addr, DBName: "unknown.db"})
+		require.Equal(t, codes.NotFound, status.Code(err), err)
+		require.NoFileExists(t, filepath.Join(root, "unknown.db"))
+	})
+
+	t.Run("attached databases are confined", func(t *testing.T) {
+		addr := startDataDirServer(t, dbwrapper.DataDir{Root: root, Create: true})
+		// sqlite opens the targets relative to the working directory
+		realRoot, err := filepath.EvalSymlinks(root)
+		require.NoError(t, err)
+		db, err := sql.Open("sqliteog", addr+"/app.db")
+		require.NoError(t, err)
+		defer db.Close()
+		conn, err := db.Conn(context.Background())
+		require.NoError(t, err)
+		defer conn.Close()
+		exec := func(query string, args ...interface{}) error {
+			_, err := conn.ExecContext(context.Background(), query, args...)
+			return err
+		}
+
+		require.NoError(t, exec(`CREATE TABLE t (id INTEGER); INSERT INTO t VALUES (1)`))
+		require.NoError(t, exec(`ATTACH '`+filepath.Join(realRoot, "sub", "attached.db")+`' AS a`))
+		require.NoError(t, exec(`CREATE TABLE a.t (id INTEGER)`))
+		require.NoError(t, exec(`DETACH a`))
+		require.NoError(t, exec(`VACUUM INTO '`+filepath.Join(realRoot, "copy.db")+`'`))
+		require.FileExists(t, filepath.Join(root, "copy.db"))
+		require.NoError(t, exec(`VACUUM`))
+		// prepared statements are sandboxed when they run
+		vacuum, err := conn.PrepareContext(context.Background(), `VACUUM`)
+		require.NoError(t, err)
+		_, err = vacuum.Exec()
+		require.NoError(t, err)
+		require.NoError(t, vacuum.Close())
+
+		for _, query := range []string{
+			`ATTACH '` + filepath.Join(outside, "evil.db") + `' AS x`,
+			`ATTACH '` + filepath.Join(realRoot, "escape", "evil.db") + `' AS x`,
+			`ATTACH '` + filepath.Join(realRoot, "secret.db") + `' AS x`,
+			`ATTACH 'file:` + filepath.Join(realRoot, "app.db") + `?mode=memory' AS x`,
+			`ATTACH '` + outside + `/' || 'evil.db' AS x`,
+			`VACUUM INTO '` + filepath.Join(outside, "copy.db") + `'`,
+		} {
+			err := exec(query)
+			require.True(t, IsPermissionDenied(err), "%s: %v", query, err)
+		}
+		err = exec(`ATTACH ? AS x`, filepath.Join(outside, "evil.db"))
+		require.True(t, IsPermissionDenied(err), err)
+		err = exec(`VACUUM INTO ?`, filepath.Join(outside, "copy.db"))
+		require.True(t, IsPermissionDenied(err), err)
+		vacuumInto, err := conn.PrepareContext(context.Background(), `VACUUM INTO ?`)
+		require.NoError(t, err)
+		_, err = vacuumInto.Exec(filepath.Join(outside, "copy.db"))
+		require.True(t, IsPermissionDenied(err), err)
+		require.NoError(t, vacuumInto.Close())
+		require.NoFileExists(t, filepath.Join(outside, "evil.db"))
+		require.NoFileExists(t, filepath.Join(outside, "copy.db"))
+	})
+
+	t.Run("unconfined names are cleaned", func(t *testing.T) {
+		addr := startDataDirServer(t, dbwrapper.DataDir{Create: true})
+		dir, err := os.MkdirTemp(".", "unconfined")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+		dbname := filepath.Join(dir, "app.db")
+
+		db, err := sql.Open("sqliteog", addr+"/./"+dbname)
+		require.NoError(t, err)
+		defer db.Close()
+		_, err = db.Exec(`CREATE TABLE t (id INTEGER)`)
+		require.NoError(t, err)
+
+		// the session is found whatever the spelling of the name
+		admin := adminClient(t, Config{Addr: addr})
+		_, err = admin.DropDatabase(context.Background(), &pb.DropDatabaseRequest{DbName: filepath.Join(dir, "sub", "..", "app.db")})
+		require.Equal(t, codes.FailedPrecondition, status.Code(err), err)
+		list, err := admin.ListDatabases(context.Background(), &pb.Empty{})
+		require.NoError(t, err)
+		require.EqualValues(t, 1, findDatabase(list, dbname).GetSessions())
+	})
+}
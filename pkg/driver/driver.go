@@ -2,13 +2,14 @@ package driver
 
 import (
 	"context"
+	"crypto/tls"
 	"database/sql"
 	"database/sql/driver"
-	"fmt"
-	"strings"
+	"sync"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
 )
 
 func init() {
@@ -17,25 +18,40 @@ func init() {
 
 type SQLiteOGConnector struct {
 	driver *SQLiteOGDriver
-	host   string
-	port   string
-	tls    bool
-	dbname string
+	config Config
+	// tlsConfig is nil when TLS is disabled
+	tlsConfig *tls.Config
 }
 
-type callbackFunc func(args ...string) []string
+// callbackFunc is a client side function, a non nil error fails the
+// statement that invoked it.
+type callbackFunc func(args ...string) ([]string, error)
+
+// Aggregator is a client side aggregate function, a new Aggregator is
+// created for every group. Step is called with the arguments of each row
+// in the group and Done returns the result of the group, an error returned
+// by either of them fails the statement.
+type Aggregator interface {
+	Step(args ...string) error
+	Done() (string, error)
+}
+
+func (c *SQLiteOGConnector) dial() (*grpc.ClientConn, error) {
+	return grpc.Dial(c.config.Addr, c.config.dialOptions(c.tlsConfig)...)
+}
 
 func (c *SQLiteOGConnector) Connect(ctx context.Context) (driver.Conn, error) {
-	target := fmt.Sprintf("%s:%s", c.host, c.port)
-	grpcConn, err := grpc.Dial(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	grpcConn, err := c.dial()
 	if err != nil {
 		return nil, err
 	}
 
-	cnx, err := NewConnection(ctx, c.dbname, grpcConn, c.driver.CallbacksEnabled, c.driver.Funcs)
+	cnx, err := NewConnection(ctx, c.config.DBName, grpcConn, c.driver.CallbacksEnabled, c.driver.functions(), c.driver.aggregators(), c.driver.Collations, c.driver.BuiltinCollations, c.driver.Access, c.config.Options, c.config.Timeout)
 	if err != nil {
+		_ = grpcConn.Close()
 		return nil, err
 	}
+	cnx.TxLock = c.driver.TxLock
 	return cnx, nil
 }
 
@@ -44,8 +60,110 @@ func (c *SQLiteOGConnector) Driver() driver.Driver {
 }
 
 type SQLiteOGDriver struct {
-	Funcs            map[string]callbackFunc
-	CallbacksEnabled bool
+	// Funcs are variadic functions taking & returning strings, see
+	// RegisterFunc for typed functions
+	Funcs map[string]callbackFunc
+	// Aggregators maps the names of aggregate functions taking & returning
+	// strings to their constructor, see RegisterAggregator for typed
+	// aggregate functions
+	Aggregators map[string]func() Aggregator
+	// Collations maps the names of collations to their comparison, which
+	// returns a negative, zero or positive int like strings.Compare. Every
+	// comparison is a round trip to the client, prefer BuiltinCollations.
+	Collations map[string]func(a, b string) int
+	// BuiltinCollations are compared on the server, they do not require
+	// callbacks: natural, natural_nocase, unicode, unicode_nocase,
+	// locale_<language> & locale_<language>_nocase (e.g. locale_de).
+	// NATURAL is a keyword, it has to be quoted: COLLATE "natural"
+	BuiltinCollations []string
+	CallbacksEnabled  bool
+	// TxLock is the default locking mode of transactions (BEGIN DEFERRED by default)
+	TxLock pb.TxLock
+	// Access restricts the statements of the connections, the server
+	// enforces it & fails the other statements with codes.PermissionDenied,
+	// see IsPermissionDenied. Connections have full access when it's nil.
+	Access *pb.Access
+
+	funcsMutex sync.Mutex
+	funcs      map[string]*function
+	aggs       map[string]*aggregator
+}
+
+// RegisterFunc makes a Go function available to the statements of the
+// connections opened afterwards, it mirrors go-sqlite3's
+// SQLiteConn.RegisterFunc. The function's arguments & first result may be
+// int64 (or any integer type), float64, string, []byte, bool or interface{},
+// the function may be variadic & may return an error as second result, which
+// fails the statement. sqlite checks the number of arguments of the calls.
+// A deterministic function always returns the same result for the same
+// arguments, which lets sqlite use it in indexes & optimise its calls.
+// Registering a function enables callbacks.
+func (d *SQLiteOGDriver) RegisterFunc(name string, impl interface{}, deterministic bool) error {
+	fn, err := newFunction(impl, deterministic)
+	if err != nil {
+		return err
+	}
+	d.funcsMutex.Lock()
+	defer d.funcsMutex.Unlock()
+	if d.funcs == nil {
+		d.funcs = make(map[string]*function)
+	}
+	d.funcs[name] = fn
+	d.CallbacksEnabled = true
+	return nil
+}
+
+// RegisterAggregator makes a Go aggregate function available to the
+// statements of the connections opened afterwards, it mirrors go-sqlite3's
+// SQLiteConn.RegisterAggregator. impl is a constructor returning the state
+// of a group, e.g. a pointer to a struct, whose Step method is called with
+// the arguments of every row of the group & may return an error, its Done
+// method returns the result of the group & may return an error as second
+// result. The arguments & result are converted like the ones of RegisterFunc.
+// A deterministic aggregate function always returns the same result for the
+// same rows. Registering an aggregate function enables callbacks.
+func (d *SQLiteOGDriver) RegisterAggregator(name string, impl interface{}, deterministic bool) error {
+	agg, err := newAggregator(impl, deterministic)
+	if err != nil {
+		return err
+	}
+	d.funcsMutex.Lock()
+	defer d.funcsMutex.Unlock()
+	if d.aggs == nil {
+		d.aggs = make(map[string]*aggregator)
+	}
+	d.aggs[name] = agg
+	d.CallbacksEnabled = true
+	return nil
+}
+
+// aggregators returns the aggregate functions registered with
+// RegisterAggregator & Aggregators
+func (d *SQLiteOGDriver) aggregators() map[string]*aggregator {
+	d.funcsMutex.Lock()
+	defer d.funcsMutex.Unlock()
+	aggs := make(map[string]*aggregator, len(d.Aggregators)+len(d.aggs))
+	for name, newAggregator := range d.Aggregators {
+		aggs[name] = stringAggregator(newAggregator)
+	}
+	for name, agg := range d.aggs {
+		aggs[name] = agg
+	}
+	return aggs
+}
+
+// functions returns the functions registered with RegisterFunc & Funcs
+func (d *SQLiteOGDriver) functions() map[string]*function {
+	d.funcsMutex.Lock()
+	defer d.funcsMutex.Unlock()
+	funcs := make(map[string]*function, len(d.Funcs)+len(d.funcs))
+	for name, fn := range d.Funcs {
+		funcs[name] = stringFunction(fn)
+	}
+	for name, fn := range d.funcs {
+		funcs[name] = fn
+	}
+	return funcs
 }
 
 func (d *SQLiteOGDriver) Open(dsn string) (driver.Conn, error) {
@@ -57,21 +175,11 @@ func (d *SQLiteOGDriver) Open(dsn string) (driver.Conn, error) {
 	return ctr.Connect(context.Background())
 }
 
+// OpenConnector parses the dsn, see ParseDSN
 func (d *SQLiteOGDriver) OpenConnector(dsn string) (driver.Connector, error) {
-	s1 := strings.Split(dsn, "/")
-	if len(s1) < 2 {
-		return nil, fmt.Errorf("wrong dsn format, must be `host:port/dbname`, got `%s`", dsn)
-	}
-	s2 := strings.Split(s1[0], ":")
-	if len(s2) < 2 {
-		return nil, fmt.Errorf("wrong dsn format, must be `host:port/dbname`, got `%s`", dsn)
+	cfg, err := ParseDSN(dsn)
+	if err != nil {
+		return nil, err
 	}
-	host, port, dbname := s2[0], s2[1], s1[1]
-	return &SQLiteOGConnector{
-		driver: d,
-		host:   host,
-		port:   port,
-		dbname: dbname,
-		tls:    false,
-	}, nil
+	return d.NewConnector(*cfg)
 }
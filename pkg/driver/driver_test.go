@@ -1,21 +1,20 @@
 package driver
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"math/rand"
-	"net"
-	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/require"
-	"google.golang.org/grpc"
 
 	pb "github.com/aousomran/sqlite-og/gen/proto"
 	"github.com/aousomran/sqlite-og/internal/connections"
-	"github.com/aousomran/sqlite-og/internal/server"
+	"github.com/aousomran/sqlite-og/internal/dbwrapper"
 )
 
 type Student struct {
@@ -30,12 +29,6 @@ type Student struct {
 
 const databaseName = "tester.db"
 
-var Listener net.Listener = nil
-var sqliteDB *sql.DB
-var ogDB *sql.DB
-var grpcServer *grpc.Server
-var connectionManager *connections.Manager
-
 func insertRandomData(db *sql.DB, numRows int) error {
 	rand.Seed(time.Now().UnixNano())
 
@@ -90,53 +83,21 @@ func queryStudents(db *sql.DB, sql string) ([]Student, error) {
 	return result, nil
 }
 
-func setupDBConnections(t *testing.T) {
-	db1, err := sql.Open("sqlite3", databaseName)
-	if err != nil {
-		require.NoError(t, err)
-	}
-	sqliteDB = db1
-
-	dsn := fmt.Sprintf("%s/%s", Listener.Addr().String(), databaseName)
-	db2, err := sql.Open("sqliteog", dsn)
-	if err != nil {
-		require.NoError(t, err)
-	}
-	ogDB = db2
+// suite is a server with its own manager, confined to a temporary data
+// directory, & a database seeded with example_table
+type suite struct {
+	addr    string
+	dsn     string
+	dataDir dbwrapper.DataDir
+	manager *connections.Manager
+	// sqliteDB opens the database file directly
+	sqliteDB *sql.DB
+	ogDB     *sql.DB
 }
 
-func setupGRPCServer(t *testing.T) {
-	// setup listener
-	var err error
-	Listener, err = net.Listen("tcp", "localhost:51515")
-	if err != nil {
-		t.Fatalf("failed to listen: %v", err)
-	}
-	if Listener == nil {
-		t.Fatal("could not create listener")
-	}
-
-	//  setup grpc server
-	grpcServer = grpc.NewServer()
-
-	connectionManager = connections.NewManager()
-	ogServer := server.New(connectionManager)
-	pb.RegisterSqliteOGServer(grpcServer, ogServer)
-
-	go func() {
-		errServe := grpcServer.Serve(Listener)
-		if errServe != nil {
-			require.NoError(t, errServe)
-			return
-		}
-		t.Logf("grpc server listening on %s", Listener.Addr().String())
-	}()
-	return
-}
-
-func setupDatabase(t *testing.T) {
+func setupDatabase(t *testing.T, db *sql.DB) {
 	// Create the example_table
-	_, err := sqliteDB.Exec(`
+	_, err := db.Exec(`
 		CREATE TABLE IF NOT EXISTS example_table (
 			id INTEGER PRIMARY KEY,
 			name TEXT,
@@ -152,36 +113,43 @@ func setupDatabase(t *testing.T) {
 	}
 
 	// Insert random data into the example_table for testing
-	err = insertRandomData(sqliteDB, 10)
+	err = insertRandomData(db, 10)
 	if err != nil {
 		t.Fatal(err)
 	}
 }
 
-func setupSuite(t *testing.T) func(t *testing.T) {
-	// setup globals to be used in tests
-	setupGRPCServer(t)
-	setupDBConnections(t)
-	setupDatabase(t)
-
-	// return teardown function
-	return func(t *testing.T) {
-		_ = sqliteDB.Close()
-		_ = ogDB.Close()
-		_ = connectionManager.Close()
-		grpcServer.GracefulStop()
-		_ = os.Remove(fmt.Sprintf("%s", databaseName))
+// setupSuite starts the suite of a test, it's torn down with the test
+func setupSuite(t *testing.T) *suite {
+	s := &suite{
+		dataDir: dbwrapper.DataDir{Root: t.TempDir(), Create: true},
+		manager: connections.NewManager(),
 	}
+	s.manager.DataDir = s.dataDir
+	// keep the callback timeout test short
+	s.manager.CallbackTimeout = time.Second
+	s.addr = startManagerServer(t, s.manager)
+	s.dsn = fmt.Sprintf("%s/%s", s.addr, databaseName)
+
+	var err error
+	s.sqliteDB, err = sql.Open("sqlite3", filepath.Join(s.dataDir.Root, databaseName))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.sqliteDB.Close() })
+	s.ogDB, err = sql.Open("sqliteog", s.dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.ogDB.Close() })
+
+	setupDatabase(t, s.sqliteDB)
+	return s
 }
 
 func TestCRUD(t *testing.T) {
-	teardown := setupSuite(t)
-	defer teardown(t)
+	s := setupSuite(t)
 
 	t.Run("selected records are equal", func(t *testing.T) {
 		query := `SELECT * FROM example_table LIMIT 10`
-		expected, err := queryStudents(sqliteDB, query)
-		actual, err2 := queryStudents(ogDB, query)
+		expected, err := queryStudents(s.sqliteDB, query)
+		actual, err2 := queryStudents(s.ogDB, query)
 
 		require.NoError(t, err)
 		require.NoError(t, err2)
@@ -192,13 +160,13 @@ func TestCRUD(t *testing.T) {
 
 	t.Run("insert works", func(t *testing.T) {
 		query := `SELECT * FROM example_table ORDER BY id DESC LIMIT 1`
-		before, err := queryStudents(sqliteDB, query)
+		before, err := queryStudents(s.sqliteDB, query)
 		require.NoError(t, err)
 
-		err = insertRandomData(ogDB, 1)
+		err = insertRandomData(s.ogDB, 1)
 		require.NoError(t, err)
 
-		after, err := queryStudents(sqliteDB, query)
+		after, err := queryStudents(s.sqliteDB, query)
 		require.NoError(t, err)
 
 		require.NotEqual(t, before, after)
@@ -207,17 +175,17 @@ func TestCRUD(t *testing.T) {
 
 	t.Run("update works", func(t *testing.T) {
 		query := `SELECT * FROM example_table ORDER BY id DESC LIMIT 1`
-		before, err := queryStudents(sqliteDB, query)
+		before, err := queryStudents(s.sqliteDB, query)
 		require.NoError(t, err)
 
 		name := "ao"
-		updateResult, err := ogDB.Exec(`UPDATE example_table SET name=? WHERE id=?`, name, before[0].ID)
+		updateResult, err := s.ogDB.Exec(`UPDATE example_table SET name=? WHERE id=?`, name, before[0].ID)
 		require.NoError(t, err)
 		affected, err := updateResult.RowsAffected()
 		require.NoError(t, err)
 		require.Equal(t, 1, int(affected))
 
-		after, err := queryStudents(sqliteDB, query)
+		after, err := queryStudents(s.sqliteDB, query)
 		require.NoError(t, err)
 
 		require.NotEqual(t, before[0].Name, after[0].Name)
@@ -226,47 +194,169 @@ func TestCRUD(t *testing.T) {
 
 	t.Run("delete works", func(t *testing.T) {
 		query := `SELECT * FROM example_table ORDER BY id DESC LIMIT 1`
-		before, err := queryStudents(ogDB, query)
+		before, err := queryStudents(s.ogDB, query)
 		require.NoError(t, err)
 
-		deleteResult, err := ogDB.Exec("delete from example_table where id=?", fmt.Sprintf("%d", before[0].ID))
+		deleteResult, err := s.ogDB.Exec("delete from example_table where id=?", fmt.Sprintf("%d", before[0].ID))
 		require.NoError(t, err)
 		affected, err := deleteResult.RowsAffected()
 		require.NoError(t, err)
 		require.Equal(t, 1, int(affected))
 
-		after, err := queryStudents(sqliteDB, query)
+		after, err := queryStudents(s.sqliteDB, query)
 		require.NoError(t, err)
 
 		require.NotEqual(t, before, after)
 		require.Less(t, after[0].ID, before[0].ID)
 	})
 
-	t.Run("test callbacks", func(t *testing.T) {
-		sayHello := func(args ...string) []string {
-			return []string{"hello " + args[0]}
-		}
+	t.Run("parameters keep their type", func(t *testing.T) {
+		_, err := s.ogDB.Exec(`CREATE TABLE IF NOT EXISTS typed_params (a, b, c, d, e)`)
+		require.NoError(t, err)
+		ts := time.Date(2023, 9, 1, 10, 30, 0, 0, time.UTC)
+		_, err = s.ogDB.Exec(`INSERT INTO typed_params VALUES (?, ?, ?, ?, ?)`, nil, []byte{1, 2, 3}, 1.5, int64(7), ts)
+		require.NoError(t, err)
+
+		var typeA, typeB, typeC, typeD, e string
+		var b []byte
+		err = s.sqliteDB.QueryRow(`SELECT typeof(a), typeof(b), typeof(c), typeof(d), b, e FROM typed_params`).
+			Scan(&typeA, &typeB, &typeC, &typeD, &b, &e)
+		require.NoError(t, err)
+		require.Equal(t, []string{"null", "blob", "real", "integer"}, []string{typeA, typeB, typeC, typeD})
+		require.Equal(t, []byte{1, 2, 3}, b)
+		require.Equal(t, "2023-09-01 10:30:00+00:00", e)
+	})
 
-		sql.Register("og_custom", &SQLiteOGDriver{
-			Funcs: map[string]callbackFunc{
-				"say_hello": sayHello,
-			},
-			CallbacksEnabled: true,
-		})
+	t.Run("null, empty & blob values are preserved", func(t *testing.T) {
+		_, err := s.ogDB.Exec(`CREATE TABLE IF NOT EXISTS typed_values (n TEXT, e TEXT, b BLOB, i INTEGER, r REAL)`)
+		require.NoError(t, err)
+		_, err = s.ogDB.Exec(`INSERT INTO typed_values VALUES (NULL, '', x'000102', 42, 2.5)`)
+		require.NoError(t, err)
 
-		dsn := fmt.Sprintf("%s/%s", Listener.Addr().String(), databaseName)
-		db, err := sql.Open("og_custom", dsn)
+		var n sql.NullString
+		var e sql.NullString
+		var b []byte
+		var i sql.NullInt64
+		var r interface{}
+		err = s.ogDB.QueryRow(`SELECT n, e, b, i, r FROM typed_values`).Scan(&n, &e, &b, &i, &r)
+		require.NoError(t, err)
+		require.False(t, n.Valid)
+		require.True(t, e.Valid)
+		require.Equal(t, "", e.String)
+		require.Equal(t, []byte{0, 1, 2}, b)
+		require.Equal(t, sql.NullInt64{Int64: 42, Valid: true}, i)
+		require.Equal(t, 2.5, r)
+	})
 
-		rows, err := db.Query(`select say_hello(?)`, "ao")
+	t.Run("typed columns keep their storage class", func(t *testing.T) {
+		_, err := s.ogDB.Exec(`CREATE TABLE IF NOT EXISTS typed_columns (d DATETIME, b BOOLEAN)`)
+		require.NoError(t, err)
+		defer s.ogDB.Exec(`DROP TABLE typed_columns`)
+		_, err = s.ogDB.Exec(`INSERT INTO typed_columns VALUES (1693564200, 2), ('yesterday', 'yes'), (1693564200.5, 0)`)
 		require.NoError(t, err)
-		result := ""
+
+		ctx := context.Background()
+		conn, err := s.ogDB.Conn(ctx)
+		require.NoError(t, err)
+		defer conn.Close()
+		var rows [][]interface{}
+		require.NoError(t, conn.Raw(func(driverConn interface{}) error {
+			cnx := driverConn.(*SQLiteOGConn)
+			result, err := cnx.OGClient.ExecuteOrQuery(ctx, &pb.Statement{CnxId: cnx.ID, Sql: `SELECT d, b FROM typed_columns`})
+			if err != nil {
+				return err
+			}
+			for _, row := range result.GetQueryResult().GetRows() {
+				var values []interface{}
+				for _, field := range row.GetFields() {
+					switch v := field.GetValue().(type) {
+					case *pb.Value_Integer:
+						values = append(values, v.Integer)
+					case *pb.Value_Real:
+						values = append(values, v.Real)
+					case *pb.Value_Text:
+						values = append(values, v.Text)
+					}
+				}
+				rows = append(rows, values)
+			}
+			return nil
+		}))
+		require.Equal(t, [][]interface{}{
+			{int64(1693564200), int64(2)},
+			{"yesterday", "yes"},
+			{1693564200.5, int64(0)},
+		}, rows)
+
+		var d interface{}
+		require.NoError(t, s.ogDB.QueryRow(`SELECT d FROM typed_columns WHERE b = 'yes'`).Scan(&d))
+		require.Equal(t, "yesterday", d)
+	})
+
+	t.Run("named parameters", func(t *testing.T) {
+		var a, b, c, d int64
+		err := s.ogDB.QueryRow(`SELECT :a, @b, $c, ?`, sql.Named("c", 3), sql.Named("b", 2), sql.Named("a", 1), 4).
+			Scan(&a, &b, &c, &d)
+		require.NoError(t, err)
+		require.Equal(t, []int64{1, 2, 3, 4}, []int64{a, b, c, d})
+	})
+
+	t.Run("large result sets are streamed", func(t *testing.T) {
+		const numRows = 20000
+		_, err := s.sqliteDB.Exec(`CREATE TABLE IF NOT EXISTS big_table (id INTEGER PRIMARY KEY, payload TEXT)`)
+		require.NoError(t, err)
+		_, err = s.sqliteDB.Exec(`
+			WITH RECURSIVE seq(n) AS (SELECT 1 UNION ALL SELECT n+1 FROM seq WHERE n < ?)
+			INSERT INTO big_table (id, payload) SELECT n, printf('%.500c', 'x') FROM seq
+		`, numRows)
+		require.NoError(t, err)
+
+		rows, err := s.ogDB.Query(`SELECT id, payload FROM big_table ORDER BY id`)
+		require.NoError(t, err)
+		count := 0
 		for rows.Next() {
-			errScan := rows.Scan(&result)
-			require.NoError(t, errScan)
+			var id int
+			var payload string
+			require.NoError(t, rows.Scan(&id, &payload))
+			count++
+			require.Equal(t, count, id)
+		}
+		require.NoError(t, rows.Err())
+		require.Equal(t, numRows, count)
+
+		// closing early stops the query, the connection stays usable
+		rows, err = s.ogDB.Query(`SELECT id FROM big_table ORDER BY id`)
+		require.NoError(t, err)
+		require.True(t, rows.Next())
+		require.NoError(t, rows.Close())
+
+		var total int
+		require.NoError(t, s.ogDB.QueryRow(`SELECT count(*) FROM big_table`).Scan(&total))
+		require.Equal(t, numRows, total)
+	})
+
+	t.Run("session state is kept on the server connection", func(t *testing.T) {
+		ctx := context.Background()
+		conn, err := s.ogDB.Conn(ctx)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		for i := 0; i < 10; i++ {
+			_, err = conn.ExecContext(ctx, `CREATE TEMP TABLE IF NOT EXISTS session_table (id INTEGER PRIMARY KEY)`)
+			require.NoError(t, err)
+			_, err = conn.ExecContext(ctx, `INSERT INTO session_table DEFAULT VALUES`)
+			require.NoError(t, err)
+			var lastId, changes int
+			err = conn.QueryRowContext(ctx, `SELECT last_insert_rowid(), changes()`).Scan(&lastId, &changes)
+			require.NoError(t, err)
+			require.Equal(t, i+1, lastId)
+			require.Equal(t, 1, changes)
 		}
-		require.Equal(t, "hello ao", result)
-		err = db.Close()
+
+		_, err = conn.ExecContext(ctx, `PRAGMA cache_size = 1234`)
 		require.NoError(t, err)
-		t.Log("test done")
+		var cacheSize int
+		require.NoError(t, conn.QueryRowContext(ctx, `PRAGMA cache_size`).Scan(&cacheSize))
+		require.Equal(t, 1234, cacheSize)
 	})
 }
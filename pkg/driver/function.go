@@ -0,0 +1,445 @@
+package driver
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+type argConverter func(*pb.Value) (reflect.Value, error)
+type retConverter func(reflect.Value) (*pb.Value, error)
+
+// function is a client side function, its arguments & result are converted
+// the same way go-sqlite3's SQLiteConn.RegisterFunc converts them.
+type function struct {
+	impl              reflect.Value
+	argConverters     []argConverter
+	variadicConverter argConverter
+	retConverter      retConverter
+	deterministic     bool
+}
+
+func newFunction(impl interface{}, deterministic bool) (*function, error) {
+	v := reflect.ValueOf(impl)
+	t := v.Type()
+	if t.Kind() != reflect.Func {
+		return nil, errors.New("non-function passed to RegisterFunc")
+	}
+	if t.NumOut() != 1 && t.NumOut() != 2 {
+		return nil, errors.New("SQLite functions must return 1 or 2 values")
+	}
+	if t.NumOut() == 2 && !t.Out(1).Implements(errorType) {
+		return nil, errors.New("second return value of SQLite function must be error")
+	}
+
+	fn, err := newArguments(t, 0)
+	if err != nil {
+		return nil, err
+	}
+	fn.impl, fn.deterministic = v, deterministic
+	fn.retConverter, err = callbackRet(t.Out(0))
+	if err != nil {
+		return nil, err
+	}
+	return fn, nil
+}
+
+// newArguments returns a function converting the arguments of the func
+// type from the first one on, the first argument of a method's type is its
+// receiver.
+func newArguments(t reflect.Type, first int) (*function, error) {
+	fn := &function{}
+	numArgs := t.NumIn()
+	if t.IsVariadic() {
+		numArgs--
+	}
+	for i := first; i < numArgs; i++ {
+		conv, err := callbackArg(t.In(i))
+		if err != nil {
+			return nil, err
+		}
+		fn.argConverters = append(fn.argConverters, conv)
+	}
+	if t.IsVariadic() {
+		conv, err := callbackArg(t.In(numArgs).Elem())
+		if err != nil {
+			return nil, err
+		}
+		fn.variadicConverter = conv
+	}
+	return fn, nil
+}
+
+// numArgs is the number of arguments registered with sqlite, -1 when the
+// function is variadic.
+func (f *function) numArgs() int32 {
+	if f.variadicConverter != nil {
+		return -1
+	}
+	return int32(len(f.argConverters))
+}
+
+func (f *function) call(args []*pb.Value) (*pb.Value, error) {
+	return f.callOn(f.impl, args)
+}
+
+// callOn calls impl, which has the function's signature, e.g. the method of
+// an aggregator's state. impl may return nothing, its result is then NULL.
+func (f *function) callOn(impl reflect.Value, args []*pb.Value) (*pb.Value, error) {
+	if len(args) < len(f.argConverters) {
+		return nil, fmt.Errorf("function requires at least %d arguments", len(f.argConverters))
+	}
+	in := make([]reflect.Value, 0, len(args))
+	for i, arg := range args {
+		conv := f.variadicConverter
+		if i < len(f.argConverters) {
+			conv = f.argConverters[i]
+		}
+		if conv == nil {
+			return nil, fmt.Errorf("function takes %d arguments, got %d", len(f.argConverters), len(args))
+		}
+		v, err := conv(arg)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d: %w", i+1, err)
+		}
+		in = append(in, v)
+	}
+
+	out := impl.Call(in)
+	if len(out) == 0 {
+		return callbackRetNil(reflect.Value{})
+	}
+	last := out[len(out)-1]
+	if last.Type().Implements(errorType) && last.Kind() == reflect.Interface && !last.IsNil() {
+		return nil, last.Interface().(error)
+	}
+	return f.retConverter(out[0])
+}
+
+func callbackArg(typ reflect.Type) (argConverter, error) {
+	switch typ.Kind() {
+	case reflect.Interface:
+		if typ.NumMethod() != 0 {
+			return nil, errors.New("the only supported interface type is interface{}")
+		}
+		return callbackArgGeneric, nil
+	case reflect.Slice:
+		if typ.Elem().Kind() != reflect.Uint8 {
+			return nil, errors.New("the only supported slice type is []byte")
+		}
+		return callbackArgBytes, nil
+	case reflect.String:
+		return callbackArgString, nil
+	case reflect.Bool:
+		return callbackArgBool, nil
+	case reflect.Int64:
+		return callbackArgInt64, nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Int, reflect.Uint:
+		return callbackArgCast(callbackArgInt64, typ), nil
+	case reflect.Float64:
+		return callbackArgFloat64, nil
+	case reflect.Float32:
+		return callbackArgCast(callbackArgFloat64, typ), nil
+	default:
+		return nil, fmt.Errorf("don't know how to convert to %s", typ)
+	}
+}
+
+func callbackArgCast(conv argConverter, typ reflect.Type) argConverter {
+	return func(v *pb.Value) (reflect.Value, error) {
+		val, err := conv(v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return val.Convert(typ), nil
+	}
+}
+
+func callbackArgInt64(v *pb.Value) (reflect.Value, error) {
+	i, ok := v.GetValue().(*pb.Value_Integer)
+	if !ok {
+		return reflect.Value{}, errors.New("argument must be an INTEGER")
+	}
+	return reflect.ValueOf(i.Integer), nil
+}
+
+func callbackArgBool(v *pb.Value) (reflect.Value, error) {
+	i, ok := v.GetValue().(*pb.Value_Integer)
+	if !ok {
+		return reflect.Value{}, errors.New("argument must be an INTEGER")
+	}
+	return reflect.ValueOf(i.Integer != 0), nil
+}
+
+func callbackArgFloat64(v *pb.Value) (reflect.Value, error) {
+	f, ok := v.GetValue().(*pb.Value_Real)
+	if !ok {
+		return reflect.Value{}, errors.New("argument must be a FLOAT")
+	}
+	return reflect.ValueOf(f.Real), nil
+}
+
+func callbackArgBytes(v *pb.Value) (reflect.Value, error) {
+	switch val := v.GetValue().(type) {
+	case *pb.Value_Blob:
+		return reflect.ValueOf(append([]byte{}, val.Blob...)), nil
+	case *pb.Value_Text:
+		return reflect.ValueOf([]byte(val.Text)), nil
+	default:
+		return reflect.Value{}, errors.New("argument must be BLOB or TEXT")
+	}
+}
+
+func callbackArgString(v *pb.Value) (reflect.Value, error) {
+	switch val := v.GetValue().(type) {
+	case *pb.Value_Blob:
+		return reflect.ValueOf(string(val.Blob)), nil
+	case *pb.Value_Text:
+		return reflect.ValueOf(val.Text), nil
+	default:
+		return reflect.Value{}, errors.New("argument must be BLOB or TEXT")
+	}
+}
+
+// callbackArgGeneric passes the value as stored, NULL is passed as nil
+func callbackArgGeneric(v *pb.Value) (reflect.Value, error) {
+	switch v.GetValue().(type) {
+	case *pb.Value_Integer:
+		return callbackArgInt64(v)
+	case *pb.Value_Real:
+		return callbackArgFloat64(v)
+	case *pb.Value_Text:
+		return callbackArgString(v)
+	case *pb.Value_Blob:
+		return callbackArgBytes(v)
+	default:
+		return reflect.Zero(reflect.TypeOf((*interface{})(nil)).Elem()), nil
+	}
+}
+
+func callbackRet(typ reflect.Type) (retConverter, error) {
+	switch typ.Kind() {
+	case reflect.Interface:
+		if typ.Implements(errorType) {
+			return callbackRetNil, nil
+		}
+		if typ.NumMethod() == 0 {
+			return callbackRetGeneric, nil
+		}
+		return nil, errors.New("the only supported interface type is interface{}")
+	case reflect.Slice:
+		if typ.Elem().Kind() != reflect.Uint8 {
+			return nil, errors.New("the only supported slice type is []byte")
+		}
+		return callbackRetBlob, nil
+	case reflect.String:
+		return callbackRetText, nil
+	case reflect.Bool, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Int, reflect.Uint:
+		return callbackRetInteger, nil
+	case reflect.Float32, reflect.Float64:
+		return callbackRetFloat, nil
+	default:
+		return nil, fmt.Errorf("don't know how to convert to %s", typ)
+	}
+}
+
+func callbackRetInteger(v reflect.Value) (*pb.Value, error) {
+	var i int64
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			i = 1
+		}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Int, reflect.Uint:
+		i = v.Convert(reflect.TypeOf(int64(0))).Int()
+	default:
+		return nil, fmt.Errorf("cannot convert %s to INTEGER", v.Type())
+	}
+	return &pb.Value{Value: &pb.Value_Integer{Integer: i}}, nil
+}
+
+func callbackRetFloat(v reflect.Value) (*pb.Value, error) {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return &pb.Value{Value: &pb.Value_Real{Real: v.Float()}}, nil
+	default:
+		return nil, fmt.Errorf("cannot convert %s to FLOAT", v.Type())
+	}
+}
+
+func callbackRetBlob(v reflect.Value) (*pb.Value, error) {
+	if v.Kind() != reflect.Slice || v.Type().Elem().Kind() != reflect.Uint8 {
+		return nil, fmt.Errorf("cannot convert %s to BLOB", v.Type())
+	}
+	if v.IsNil() {
+		return callbackRetNil(v)
+	}
+	return &pb.Value{Value: &pb.Value_Blob{Blob: v.Bytes()}}, nil
+}
+
+func callbackRetText(v reflect.Value) (*pb.Value, error) {
+	if v.Kind() != reflect.String {
+		return nil, fmt.Errorf("cannot convert %s to TEXT", v.Type())
+	}
+	return &pb.Value{Value: &pb.Value_Text{Text: v.String()}}, nil
+}
+
+func callbackRetNil(reflect.Value) (*pb.Value, error) {
+	return &pb.Value{Value: &pb.Value_Null{Null: &pb.Empty{}}}, nil
+}
+
+// callbackRetGeneric converts the dynamic value, nil is returned as NULL
+func callbackRetGeneric(v reflect.Value) (*pb.Value, error) {
+	if v.IsNil() {
+		return callbackRetNil(v)
+	}
+	conv, err := callbackRet(v.Elem().Type())
+	if err != nil {
+		return nil, err
+	}
+	return conv(v.Elem())
+}
+
+// valueToString converts the arguments of string callbacks, aggregators &
+// collations
+func valueToString(v *pb.Value) string {
+	switch val := v.GetValue().(type) {
+	case *pb.Value_Integer:
+		return strconv.FormatInt(val.Integer, 10)
+	case *pb.Value_Real:
+		return strconv.FormatFloat(val.Real, 'g', -1, 64)
+	case *pb.Value_Text:
+		return val.Text
+	case *pb.Value_Blob:
+		return string(val.Blob)
+	default:
+		return ""
+	}
+}
+
+func valuesToStrings(values []*pb.Value) []string {
+	args := make([]string, len(values))
+	for i, v := range values {
+		args[i] = valueToString(v)
+	}
+	return args
+}
+
+// stringFunction wraps a callbackFunc, it's variadic, its arguments are
+// converted to strings & its first result is returned as TEXT. It stays
+// deterministic, as callbackFuncs always were.
+func stringFunction(fn callbackFunc) *function {
+	impl := func(args ...string) (string, error) {
+		result, err := fn(args...)
+		if err != nil || len(result) < 1 {
+			return "", err
+		}
+		return result[0], nil
+	}
+	return &function{
+		impl:              reflect.ValueOf(impl),
+		variadicConverter: callbackArgToString,
+		retConverter:      callbackRetText,
+		deterministic:     true,
+	}
+}
+
+// callbackArgToString converts the arguments of the string callbacks
+func callbackArgToString(v *pb.Value) (reflect.Value, error) {
+	return reflect.ValueOf(valueToString(v)), nil
+}
+
+// aggregator is a client side aggregate function. Its constructor returns
+// the state of a group, the Step method of the state is called with the
+// arguments of every row & its Done method returns the result. They are
+// converted like the ones of function, as go-sqlite3's
+// SQLiteConn.RegisterAggregator converts them.
+type aggregator struct {
+	constructor   reflect.Value
+	step          *function
+	done          *function
+	deterministic bool
+}
+
+func newAggregator(impl interface{}, deterministic bool) (*aggregator, error) {
+	constructor := reflect.ValueOf(impl)
+	t := constructor.Type()
+	if t.Kind() != reflect.Func || t.NumIn() != 0 || t.NumOut() != 1 {
+		return nil, errors.New("SQLite aggregators must be constructors taking no arguments & returning 1 value")
+	}
+	state := t.Out(0)
+	// the methods of interfaces have no receiver
+	first := 1
+	if state.Kind() == reflect.Interface {
+		first = 0
+	}
+
+	stepMethod, ok := state.MethodByName("Step")
+	if !ok {
+		return nil, fmt.Errorf("%s has no Step method", state)
+	}
+	step, err := newArguments(stepMethod.Type, first)
+	if err != nil {
+		return nil, fmt.Errorf("Step: %w", err)
+	}
+	if st := stepMethod.Type; st.NumOut() > 1 || st.NumOut() == 1 && !st.Out(0).Implements(errorType) {
+		return nil, errors.New("Step must return nothing or an error")
+	}
+	step.retConverter = callbackRetNil
+
+	doneMethod, ok := state.MethodByName("Done")
+	if !ok {
+		return nil, fmt.Errorf("%s has no Done method", state)
+	}
+	dt := doneMethod.Type
+	if dt.NumIn() != first {
+		return nil, errors.New("Done must take no arguments")
+	}
+	if dt.NumOut() != 1 && dt.NumOut() != 2 {
+		return nil, errors.New("Done must return 1 or 2 values")
+	}
+	if dt.NumOut() == 2 && !dt.Out(1).Implements(errorType) {
+		return nil, errors.New("second return value of Done must be error")
+	}
+	conv, err := callbackRet(dt.Out(0))
+	if err != nil {
+		return nil, fmt.Errorf("Done: %w", err)
+	}
+	return &aggregator{
+		constructor:   constructor,
+		step:          step,
+		done:          &function{retConverter: conv},
+		deterministic: deterministic,
+	}, nil
+}
+
+// newState returns the state of a new group
+func (a *aggregator) newState() reflect.Value {
+	return a.constructor.Call(nil)[0]
+}
+
+func (a *aggregator) stepOn(state reflect.Value, args []*pb.Value) error {
+	_, err := a.step.callOn(state.MethodByName("Step"), args)
+	return err
+}
+
+func (a *aggregator) doneOn(state reflect.Value) (*pb.Value, error) {
+	return a.done.callOn(state.MethodByName("Done"), nil)
+}
+
+// stringAggregator wraps the constructor of an Aggregator, its arguments
+// are converted to strings & its result is returned as TEXT. It stays
+// deterministic, as Aggregators always were.
+func stringAggregator(newAggregator func() Aggregator) *aggregator {
+	return &aggregator{
+		constructor:   reflect.ValueOf(newAggregator),
+		step:          &function{variadicConverter: callbackArgToString, retConverter: callbackRetNil},
+		done:          &function{retConverter: callbackRetText},
+		deterministic: true,
+	}
+}
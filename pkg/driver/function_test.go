@@ -0,0 +1,209 @@
+package driver
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
+)
+
+func integer(i int64) *pb.Value {
+	return &pb.Value{Value: &pb.Value_Integer{Integer: i}}
+}
+
+func float(f float64) *pb.Value {
+	return &pb.Value{Value: &pb.Value_Real{Real: f}}
+}
+
+func text(s string) *pb.Value {
+	return &pb.Value{Value: &pb.Value_Text{Text: s}}
+}
+
+func null() *pb.Value {
+	return &pb.Value{Value: &pb.Value_Null{Null: &pb.Empty{}}}
+}
+
+func Test_newFunction(t *testing.T) {
+	t.Run("unsupported signatures are rejected", func(t *testing.T) {
+		for name, impl := range map[string]interface{}{
+			"not a function":      42,
+			"no result":           func(int64) {},
+			"too many results":    func() (int64, int64, error) { return 0, 0, nil },
+			"second not an error": func() (int64, int64) { return 0, 0 },
+			"unsupported arg":     func(map[string]int) int64 { return 0 },
+			"unsupported slice":   func([]int) int64 { return 0 },
+			"unsupported result":  func() struct{} { return struct{}{} },
+		} {
+			_, err := newFunction(impl, false)
+			assert.Error(t, err, name)
+		}
+	})
+
+	t.Run("arity is sent to the server", func(t *testing.T) {
+		fn, err := newFunction(func(a, b int64) int64 { return a + b }, true)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(2), fn.numArgs())
+		assert.True(t, fn.deterministic)
+
+		fn, err = newFunction(func(sep string, parts ...string) string { return "" }, false)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(-1), fn.numArgs())
+	})
+
+	t.Run("arguments & results are typed", func(t *testing.T) {
+		fn, err := newFunction(func(a int, b float64, c []byte, d bool) (float64, error) {
+			if d {
+				return float64(a) + b + float64(len(c)), nil
+			}
+			return 0, nil
+		}, false)
+		assert.NoError(t, err)
+		result, err := fn.call([]*pb.Value{
+			integer(1),
+			{Value: &pb.Value_Real{Real: 0.5}},
+			text("abc"),
+			integer(1),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 4.5, result.GetReal())
+
+		// go-sqlite3 does not convert integers to floats either
+		_, err = fn.call([]*pb.Value{integer(1), integer(2), text("abc"), integer(1)})
+		assert.ErrorContains(t, err, "argument 2: argument must be a FLOAT")
+	})
+
+	t.Run("interface{} arguments & results keep NULL", func(t *testing.T) {
+		fn, err := newFunction(func(args ...interface{}) interface{} {
+			return args[len(args)-1]
+		}, false)
+		assert.NoError(t, err)
+		for _, v := range []*pb.Value{null(), integer(3), text("x"), {Value: &pb.Value_Blob{Blob: []byte{1}}}} {
+			result, err := fn.call([]*pb.Value{integer(0), v})
+			assert.NoError(t, err)
+			assert.Equal(t, v.String(), result.String())
+		}
+	})
+
+	t.Run("errors are returned", func(t *testing.T) {
+		fn, err := newFunction(func(s string) (string, error) {
+			return "", errors.New("bad " + s)
+		}, false)
+		assert.NoError(t, err)
+		_, err = fn.call([]*pb.Value{text("input")})
+		assert.EqualError(t, err, "bad input")
+
+		fn, err = newFunction(func() error { return errors.New("only an error") }, false)
+		assert.NoError(t, err)
+		_, err = fn.call(nil)
+		assert.EqualError(t, err, "only an error")
+	})
+
+	t.Run("string callbacks convert their arguments", func(t *testing.T) {
+		fn := stringFunction(func(args ...string) ([]string, error) {
+			return []string{args[0] + args[1] + args[2]}, nil
+		})
+		assert.Equal(t, int32(-1), fn.numArgs())
+		result, err := fn.call([]*pb.Value{integer(1), null(), text("a")})
+		assert.NoError(t, err)
+		assert.Equal(t, "1a", result.GetText())
+	})
+}
+
+type meanState struct {
+	sum   float64
+	count int64
+}
+
+func (s *meanState) Step(v float64, weights ...int64) error {
+	if v < 0 {
+		return errors.New("negative")
+	}
+	s.sum += v
+	s.count++
+	return nil
+}
+
+func (s *meanState) Done() (interface{}, error) {
+	if s.count == 0 {
+		return nil, nil
+	}
+	return s.sum / float64(s.count), nil
+}
+
+type noStep struct{}
+
+func (noStep) Done() int64 { return 0 }
+
+type badDone struct{}
+
+func (badDone) Step(int64)       {}
+func (badDone) Done(int64) int64 { return 0 }
+
+type mapStep struct{}
+
+func (mapStep) Step(map[string]int) {}
+func (mapStep) Done() int64         { return 0 }
+
+func Test_newAggregator(t *testing.T) {
+	t.Run("unsupported signatures are rejected", func(t *testing.T) {
+		for name, impl := range map[string]interface{}{
+			"not a function":   42,
+			"with arguments":   func(int64) *meanState { return nil },
+			"no Step":          func() noStep { return noStep{} },
+			"Done arguments":   func() badDone { return badDone{} },
+			"unsupported arg":  func() mapStep { return mapStep{} },
+			"too many results": func() (*meanState, error) { return nil, nil },
+		} {
+			_, err := newAggregator(impl, false)
+			assert.Error(t, err, name)
+		}
+	})
+
+	t.Run("arguments & results are typed", func(t *testing.T) {
+		agg, err := newAggregator(func() *meanState { return &meanState{} }, false)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(-1), agg.step.numArgs())
+		assert.False(t, agg.deterministic)
+
+		state := agg.newState()
+		assert.NoError(t, agg.stepOn(state, []*pb.Value{float(1)}))
+		assert.NoError(t, agg.stepOn(state, []*pb.Value{float(2), integer(1)}))
+		assert.Error(t, agg.stepOn(state, []*pb.Value{text("3")}))
+		assert.EqualError(t, agg.stepOn(state, []*pb.Value{float(-1)}), "negative")
+		result, err := agg.doneOn(state)
+		assert.NoError(t, err)
+		assert.Equal(t, 1.5, result.GetReal())
+
+		// a group without rows
+		result, err = agg.doneOn(agg.newState())
+		assert.NoError(t, err)
+		assert.NotNil(t, result.GetNull())
+	})
+
+	t.Run("string aggregators convert their arguments", func(t *testing.T) {
+		agg := stringAggregator(func() Aggregator { return &concatAggregator{} })
+		assert.True(t, agg.deterministic)
+		state := agg.newState()
+		assert.NoError(t, agg.stepOn(state, []*pb.Value{integer(1), null()}))
+		assert.NoError(t, agg.stepOn(state, []*pb.Value{text("a")}))
+		result, err := agg.doneOn(state)
+		assert.NoError(t, err)
+		assert.Equal(t, "1a", result.GetText())
+	})
+}
+
+type concatAggregator struct {
+	s string
+}
+
+func (a *concatAggregator) Step(args ...string) error {
+	a.s += strings.Join(args, "")
+	return nil
+}
+
+func (a *concatAggregator) Done() (string, error) {
+	return a.s, nil
+}
@@ -0,0 +1,112 @@
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
+)
+
+func TestConnectionOptions(t *testing.T) {
+	s := setupSuite(t)
+
+	addr := s.addr
+	db, err := sql.Open("sqliteog", fmt.Sprintf("%s/%s?_busy_timeout=1234&_foreign_keys=on&_synchronous=NORMAL", addr, databaseName))
+	require.NoError(t, err)
+	defer db.Close()
+	pragma := func(db *sql.DB, name string) int {
+		var value int
+		require.NoError(t, db.QueryRow(`PRAGMA `+name).Scan(&value))
+		return value
+	}
+	require.Equal(t, 1234, pragma(db, "busy_timeout"))
+	require.Equal(t, 1, pragma(db, "foreign_keys"))
+	require.Equal(t, 1, pragma(db, "synchronous"))
+	// other sessions keep the defaults
+	require.Equal(t, 0, pragma(s.ogDB, "foreign_keys"))
+
+	for _, query := range []string{"_journal_mode=bogus", "_busy_timeout=1%3BDROP", "_unknown=1"} {
+		db, err := sql.Open("sqliteog", fmt.Sprintf("%s/%s?%s", addr, databaseName, query))
+		require.NoError(t, err)
+		err = db.Ping()
+		require.Equal(t, codes.InvalidArgument, status.Code(err), "%s: %v", query, err)
+		_ = db.Close()
+	}
+
+	_, err = sql.Open("sqliteog", fmt.Sprintf("%s/%s?busy_timeout=1", addr, databaseName))
+	require.ErrorContains(t, err, "unknown dsn parameter")
+
+	// restricted sessions cannot change the database with options
+	sql.Register("og_no_ddl_options", &SQLiteOGDriver{Access: &pb.Access{Mode: AccessNoDDL}})
+	db, err = sql.Open("og_no_ddl_options", fmt.Sprintf("%s/%s?_journal_mode=wal", addr, databaseName))
+	require.NoError(t, err)
+	defer db.Close()
+	require.Equal(t, codes.InvalidArgument, status.Code(db.Ping()))
+}
+
+func TestConnectorConfig(t *testing.T) {
+	s := setupSuite(t)
+
+	dir := filepath.Join(s.dataDir.Root, "databases")
+	require.NoError(t, os.Mkdir(dir, 0o755))
+
+	connector, err := NewConnector(Config{
+		Addr:        s.addr,
+		DBName:      filepath.Join(filepath.Base(dir), "nested.db"),
+		Options:     map[string]string{"busy_timeout": "2500"},
+		Timeout:     time.Second,
+		Compression: "gzip",
+		MaxMsgSize:  8 << 20,
+	})
+	require.NoError(t, err)
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	_, err = db.Exec(`CREATE TABLE blobs (data BLOB)`)
+	require.NoError(t, err)
+	// larger than grpc's default 4MB limit
+	_, err = db.Exec(`INSERT INTO blobs VALUES (zeroblob(5000000))`)
+	require.NoError(t, err)
+	var size, timeout int
+	require.NoError(t, db.QueryRow(`SELECT length(data) FROM blobs`).Scan(&size))
+	require.Equal(t, 5000000, size)
+	var data []byte
+	require.NoError(t, db.QueryRow(`SELECT data FROM blobs`).Scan(&data))
+	require.Len(t, data, 5000000)
+	require.NoError(t, db.QueryRow(`PRAGMA busy_timeout`).Scan(&timeout))
+	require.Equal(t, 2500, timeout)
+	require.FileExists(t, filepath.Join(dir, "nested.db"))
+
+	_, err = NewConnector(Config{Addr: "localhost", DBName: "test.db"})
+	require.Error(t, err)
+
+	// the server accepts the connection but never answers
+	silent, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer silent.Close()
+	go func() {
+		for {
+			conn, err := silent.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+	connector, err = NewConnector(Config{Addr: silent.Addr().String(), DBName: "test.db", Timeout: 200 * time.Millisecond})
+	require.NoError(t, err)
+	start := time.Now()
+	_, err = connector.Connect(context.Background())
+	require.Equal(t, codes.DeadlineExceeded, status.Code(err), err)
+	require.Less(t, time.Since(start), 5*time.Second)
+}
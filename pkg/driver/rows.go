@@ -1,6 +1,7 @@
 package driver
 
 import (
+	"context"
 	"database/sql/driver"
 	"fmt"
 	"io"
@@ -12,11 +13,34 @@ import (
 	pb "github.com/aousomran/sqlite-og/gen/proto"
 )
 
+// timestampFormats are the timestamp formats understood by go-sqlite3, the
+// first one is used when sending time.Time parameters.
+var timestampFormats = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
 type Rows struct {
 	mu     sync.RWMutex
 	pbr    *pb.QueryResult
 	index  int
 	closed bool
+	// stream is set when rows are fetched in batches from QueryStream,
+	// cancel stops the query on the server
+	stream resultStream
+	cancel context.CancelFunc
+}
+
+// resultStream is implemented by the QueryStream & QueryPrepared clients
+type resultStream interface {
+	Recv() (*pb.QueryResult, error)
 }
 
 func rowsFromPB(pbResult *pb.QueryResult) (*Rows, error) {
@@ -30,34 +54,64 @@ func rowsFromPB(pbResult *pb.QueryResult) (*Rows, error) {
 	}, nil
 }
 
+// rowsFromStream receives the column names & types, the rows themselves
+// are received lazily by Next. cancel is called when the rows are closed.
+func rowsFromStream(stream resultStream, cancel context.CancelFunc) (*Rows, error) {
+	first, err := stream.Recv()
+	if err == io.EOF {
+		err = fmt.Errorf("query stream ended before sending columns")
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &Rows{
+		mu:     sync.RWMutex{},
+		pbr:    first,
+		index:  0,
+		stream: stream,
+		cancel: cancel,
+	}, nil
+}
+
 func (r *Rows) Columns() []string {
 	return r.pbr.GetColumns()
 }
 
 func (r *Rows) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.closed = true
+	r.endStream()
 	return nil
 }
 
+// endStream stops the server side cursor if the rows are still streaming
+func (r *Rows) endStream() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.stream = nil
+}
+
 func (r *Rows) Next(dest []driver.Value) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	// if we've reached the end of the result set, return io.EOF
-	if r.index == len(r.pbr.GetRows()) {
-		return io.EOF
-	}
-	for k, v := range r.pbr.GetRows()[r.index].Fields {
-		dest[k] = v
-		typeName := r.ColumnTypeDatabaseTypeName(k)
-		if typeName == "DATETIME" || typeName == "DATE" {
-			t, err := time.Parse(time.RFC3339, v)
-
-			fmt.Printf("before: %s, after: %+v | %s", v, t, t.Format(time.DateTime))
-			if err != nil {
-				fmt.Printf("error parsing time: %s", err.Error())
-			}
-			dest[k] = t
+	// fetch the next batch once the current one is consumed, if we've
+	// reached the end of the result set, return io.EOF
+	for r.index == len(r.pbr.GetRows()) {
+		if r.stream == nil {
+			return io.EOF
 		}
+		batch, err := r.stream.Recv()
+		if err != nil {
+			r.endStream()
+			return err
+		}
+		r.pbr.Rows, r.index = batch.GetRows(), 0
+	}
+	for k, v := range r.pbr.GetRows()[r.index].GetFields() {
+		dest[k] = r.fromValue(k, v)
 	}
 	// increment the index so that the subsequent call to .Next()
 	// points to the correct result set
@@ -65,6 +119,54 @@ func (r *Rows) Next(dest []driver.Value) error {
 	return nil
 }
 
+// fromValue converts a typed value into a driver.Value, the conversions of
+// DATE, DATETIME, TIMESTAMP & BOOLEAN columns mirror the ones made by go-sqlite3.
+func (r *Rows) fromValue(index int, v *pb.Value) driver.Value {
+	switch val := v.GetValue().(type) {
+	case *pb.Value_Integer:
+		switch r.ColumnTypeDatabaseTypeName(index) {
+		case "DATE", "DATETIME", "TIMESTAMP":
+			// a 13 digit value is assumed to be a unix timestamp in milliseconds
+			if val.Integer > 1e12 || val.Integer < -1e12 {
+				return time.Unix(0, val.Integer*int64(time.Millisecond)).UTC()
+			}
+			return time.Unix(val.Integer, 0).UTC()
+		case "BOOLEAN":
+			return val.Integer > 0
+		default:
+			return val.Integer
+		}
+	case *pb.Value_Real:
+		return val.Real
+	case *pb.Value_Text:
+		switch r.ColumnTypeDatabaseTypeName(index) {
+		case "DATE", "DATETIME", "TIMESTAMP":
+			return parseTimestamp(val.Text)
+		default:
+			return val.Text
+		}
+	case *pb.Value_Blob:
+		if val.Blob == nil {
+			return []byte{}
+		}
+		return val.Blob
+	default:
+		return nil
+	}
+}
+
+// parseTimestamp tries all the formats understood by go-sqlite3 in order,
+// the text is returned as is if none of them matches so that it's not lost.
+func parseTimestamp(s string) driver.Value {
+	trimmed := strings.TrimSuffix(s, "Z")
+	for _, format := range timestampFormats {
+		if t, err := time.ParseInLocation(format, trimmed, time.UTC); err == nil {
+			return t
+		}
+	}
+	return s
+}
+
 func (r *Rows) ColumnTypeDatabaseTypeName(index int) string {
 	return strings.ToUpper(strings.Split(r.pbr.ColumnTypes[index], "(")[0])
 }
@@ -76,11 +178,13 @@ func (r *Rows) ColumnTypeScanType(index int) reflect.Type {
 		return reflect.TypeOf(int64(0))
 	case "REAL", "FLOAT", "DOUBLE":
 		return reflect.TypeOf(float64(0))
-	case "TEXT", "BLOB", "CHAR", "VARCHAR":
+	case "TEXT", "CHAR", "VARCHAR":
 		return reflect.TypeOf("")
-	case "BOOL":
+	case "BLOB":
+		return reflect.TypeOf([]byte(nil))
+	case "BOOL", "BOOLEAN":
 		return reflect.TypeOf(true)
-	case "TIME", "DATETIME", "DATE":
+	case "TIME", "DATETIME", "DATE", "TIMESTAMP":
 		return reflect.TypeOf(time.Now())
 	default:
 		return reflect.TypeOf("")
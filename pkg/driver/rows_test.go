@@ -0,0 +1,36 @@
+package driver
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
+)
+
+func TestRows_fromValue(t *testing.T) {
+	rows := &Rows{pbr: &pb.QueryResult{ColumnTypes: []string{"DATETIME", "BOOLEAN", "TEXT"}}}
+	text := func(s string) *pb.Value { return &pb.Value{Value: &pb.Value_Text{Text: s}} }
+	integer := func(i int64) *pb.Value { return &pb.Value{Value: &pb.Value_Integer{Integer: i}} }
+
+	tests := []struct {
+		name  string
+		index int
+		value *pb.Value
+		want  driver.Value
+	}{
+		{"timestamp", 0, text("2023-09-01 10:30:00+00:00"), time.Date(2023, 9, 1, 10, 30, 0, 0, time.UTC)},
+		{"utc timestamp", 0, text("2023-09-01T10:30:00Z"), time.Date(2023, 9, 1, 10, 30, 0, 0, time.UTC)},
+		{"unix timestamp", 0, integer(1693564200), time.Date(2023, 9, 1, 10, 30, 0, 0, time.UTC)},
+		{"invalid timestamp", 0, text("yesterday"), "yesterday"},
+		{"boolean", 1, integer(1), true},
+		{"text", 2, text("2023-09-01"), "2023-09-01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, rows.fromValue(tt.index, tt.value))
+		})
+	}
+}
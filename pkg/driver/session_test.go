@@ -0,0 +1,199 @@
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
+	"github.com/aousomran/sqlite-og/internal/connections"
+	"github.com/aousomran/sqlite-og/internal/dbwrapper"
+	"github.com/aousomran/sqlite-og/internal/server"
+)
+
+// startManagerServer serves the manager's sessions & its admin service
+func startManagerServer(t *testing.T, manager *connections.Manager) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	sqliteOG := server.New(manager)
+	pb.RegisterSqliteOGServer(srv, sqliteOG)
+	pb.RegisterAdminServer(srv, server.NewAdmin(sqliteOG))
+	go srv.Serve(listener)
+	t.Cleanup(func() {
+		srv.Stop()
+		_ = manager.Close()
+	})
+	return listener.Addr().String()
+}
+
+func TestIdleSessions(t *testing.T) {
+	manager := connections.NewManager()
+	manager.SessionIdleTimeout = 100 * time.Millisecond
+	addr := startManagerServer(t, manager)
+	ctx := context.Background()
+	reap := func() {
+		time.Sleep(2 * manager.SessionIdleTimeout)
+		manager.ReapSessions(ctx)
+	}
+
+	t.Run("abandoned sessions are closed", func(t *testing.T) {
+		connector, err := NewConnector(Config{Addr: addr, DBName: ":memory:"})
+		require.NoError(t, err)
+		// the client goes away without closing its session
+		abandoned, err := connector.Connect(ctx)
+		require.NoError(t, err)
+		reap()
+		require.False(t, abandoned.(*SQLiteOGConn).IsValid())
+	})
+
+	t.Run("closed connections close their grpc connection", func(t *testing.T) {
+		connector, err := NewConnector(Config{Addr: addr, DBName: ":memory:"})
+		require.NoError(t, err)
+		conn, err := connector.Connect(ctx)
+		require.NoError(t, err)
+		require.NoError(t, conn.Close())
+		require.Equal(t, connectivity.Shutdown, conn.(*SQLiteOGConn).GRPCConn.GetState())
+	})
+
+	t.Run("pooled connections are replaced", func(t *testing.T) {
+		db, err := sql.Open("sqliteog", addr+"/:memory:")
+		require.NoError(t, err)
+		defer db.Close()
+		require.NoError(t, db.Ping())
+		reap()
+		var one int
+		require.NoError(t, db.QueryRow(`SELECT 1`).Scan(&one))
+	})
+
+	t.Run("running statements are not interrupted", func(t *testing.T) {
+		started, release := make(chan struct{}), make(chan struct{})
+		sql.Register("og_idle_sessions", &SQLiteOGDriver{
+			Funcs: map[string]callbackFunc{
+				"wait": func(args ...string) ([]string, error) {
+					close(started)
+					<-release
+					return args, nil
+				},
+			},
+			CallbacksEnabled: true,
+		})
+		db, err := sql.Open("og_idle_sessions", addr+"/:memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		done := make(chan error, 1)
+		go func() {
+			var result string
+			done <- db.QueryRow(`SELECT wait('x')`).Scan(&result)
+		}()
+		<-started
+		reap()
+		close(release)
+		require.NoError(t, <-done)
+	})
+}
+
+func TestCallbackStream(t *testing.T) {
+	addr := startDataDirServer(t, dbwrapper.DataDir{})
+	d := &SQLiteOGDriver{}
+	require.NoError(t, d.RegisterFunc("twice", func(n int64) int64 { return 2 * n }, true))
+	connector, err := d.NewConnector(Config{Addr: addr, DBName: ":memory:"})
+	require.NoError(t, err)
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	t.Run("outlives the context of the statement that connected", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var n int64
+		require.NoError(t, db.QueryRowContext(ctx, `SELECT twice(1)`).Scan(&n))
+		cancel()
+		require.NoError(t, db.QueryRow(`SELECT twice(2)`).Scan(&n))
+		require.Equal(t, int64(4), n)
+	})
+
+	t.Run("connections without it are replaced", func(t *testing.T) {
+		conn, err := db.Conn(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, conn.Raw(func(driverConn any) error {
+			// the stream ends as it does when the server goes away
+			driverConn.(*SQLiteOGConn).callbackCanceller()
+			<-driverConn.(*SQLiteOGConn).callbackDone
+			return nil
+		}))
+		require.NoError(t, conn.Close())
+		var n int64
+		require.NoError(t, db.QueryRow(`SELECT twice(3)`).Scan(&n))
+		require.Equal(t, int64(6), n)
+	})
+}
+
+// TestSessionsClosedConcurrently closes sessions, by reaping them & by
+// dropping their database, while they run statements
+func TestSessionsClosedConcurrently(t *testing.T) {
+	manager := connections.NewManager()
+	manager.DataDir = dbwrapper.DataDir{Root: t.TempDir(), Create: true}
+	manager.SessionIdleTimeout = 20 * time.Millisecond
+	addr := startManagerServer(t, manager)
+	admin := adminClient(t, Config{Addr: addr})
+	ctx := context.Background()
+
+	db, err := sql.Open("sqliteog", addr+"/app.db")
+	require.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(4)
+
+	stop := make(chan struct{})
+	var closers sync.WaitGroup
+	closers.Add(1)
+	go func() {
+		defer closers.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(time.Millisecond):
+			}
+			manager.ReapSessions(ctx)
+			_ = manager.Names()
+			manager.CompactChangelogs(ctx)
+			_, _ = admin.DropDatabase(ctx, &pb.DropDatabaseRequest{DbName: "app.db", Force: true})
+		}
+	}()
+
+	// the statements fail once their session is closed
+	var clients sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		clients.Add(1)
+		go func() {
+			defer clients.Done()
+			for j := 0; j < 50; j++ {
+				_, _ = db.Exec(`CREATE TABLE IF NOT EXISTS t (id INTEGER); INSERT INTO t VALUES (1)`)
+				var n int
+				_ = db.QueryRow(`SELECT count(*) FROM t`).Scan(&n)
+				if tx, err := db.Begin(); err == nil {
+					_, _ = tx.Exec(`INSERT INTO t VALUES (2)`)
+					_ = tx.Commit()
+				}
+			}
+		}()
+	}
+	clients.Wait()
+	close(stop)
+	closers.Wait()
+
+	// the sessions opened afterwards work
+	db.SetMaxOpenConns(1)
+	var one int
+	require.Eventually(t, func() bool {
+		return db.QueryRow(`SELECT 1`).Scan(&one) == nil
+	}, 10*time.Second, 10*time.Millisecond)
+}
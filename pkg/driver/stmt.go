@@ -3,24 +3,62 @@ package driver
 import (
 	"context"
 	"database/sql/driver"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
 )
 
+// SQLiteOGStmt is a statement prepared on the server, it's referenced by
+// its handle so executions skip sending & parsing the sql again.
 type SQLiteOGStmt struct {
 	c        *SQLiteOGConn
-	sql      string
+	id       string
 	numInput int
 }
 
+func (s *SQLiteOGStmt) statement(args []driver.NamedValue) (*pb.Statement, error) {
+	params, err := namedValuesToParams(args)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Statement{
+		Params: params,
+		CnxId:  s.c.ID,
+		StmtId: s.id,
+	}, nil
+}
+
 func (s *SQLiteOGStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
-	return s.c.ExecContext(ctx, s.sql, args)
+	stmt, err := s.statement(args)
+	if err != nil {
+		return nil, err
+	}
+	pbr, err := s.c.OGClient.ExecPrepared(ctx, stmt)
+	if err != nil {
+		return nil, err
+	}
+	return resultFromPB(pbr)
 }
 
 func (s *SQLiteOGStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
-	return s.c.QueryContext(ctx, s.sql, args)
+	stmt, err := s.statement(args)
+	if err != nil {
+		return nil, err
+	}
+	streamCtx, cancel := context.WithCancel(ctx)
+	stream, err := s.c.OGClient.QueryPrepared(streamCtx, stmt)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return rowsFromStream(stream, cancel)
 }
 
 func (s *SQLiteOGStmt) Close() error {
-	return nil
+	_, err := s.c.OGClient.ClosePrepared(context.Background(), &pb.Statement{
+		CnxId:  s.c.ID,
+		StmtId: s.id,
+	})
+	return err
 }
 
 func (s *SQLiteOGStmt) NumInput() int {
@@ -28,25 +66,21 @@ func (s *SQLiteOGStmt) NumInput() int {
 }
 
 func (s *SQLiteOGStmt) Exec(args []driver.Value) (driver.Result, error) {
-	namedValues := make([]driver.NamedValue, len(args))
-	for k, v := range args {
-		namedValues[0] = driver.NamedValue{
-			Name:    "",
-			Ordinal: k,
-			Value:   v,
-		}
-	}
-	return s.ExecContext(context.Background(), namedValues)
+	return s.ExecContext(context.Background(), valuesToNamedValues(args))
 }
 
 func (s *SQLiteOGStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), valuesToNamedValues(args))
+}
+
+func valuesToNamedValues(args []driver.Value) []driver.NamedValue {
 	namedValues := make([]driver.NamedValue, len(args))
 	for k, v := range args {
-		namedValues[0] = driver.NamedValue{
+		namedValues[k] = driver.NamedValue{
 			Name:    "",
-			Ordinal: k,
+			Ordinal: k + 1,
 			Value:   v,
 		}
 	}
-	return s.QueryContext(context.Background(), namedValues)
+	return namedValues
 }
@@ -0,0 +1,40 @@
+package driver
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreparedStatements(t *testing.T) {
+	s := setupSuite(t)
+
+	stmt, err := s.ogDB.Prepare(`SELECT id, name FROM example_table WHERE name <> '?' AND id = ?`)
+	require.NoError(t, err)
+	defer stmt.Close()
+
+	expected, err := queryStudents(s.sqliteDB, `SELECT * FROM example_table ORDER BY id LIMIT 3`)
+	require.NoError(t, err)
+	for _, student := range expected {
+		var id int
+		var name string
+		require.NoError(t, stmt.QueryRow(student.ID).Scan(&id, &name))
+		require.Equal(t, student.ID, id)
+		require.Equal(t, student.Name, name)
+	}
+
+	_, err = s.ogDB.Exec(`CREATE TABLE prepared_items (id INTEGER PRIMARY KEY, name TEXT)`)
+	require.NoError(t, err)
+	insert, err := s.ogDB.Prepare(`INSERT INTO prepared_items (name) VALUES (:name)`)
+	require.NoError(t, err)
+	result, err := insert.Exec(sql.Named("name", "prepared"))
+	require.NoError(t, err)
+	affected, err := result.RowsAffected()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), affected)
+	require.NoError(t, insert.Close())
+
+	_, err = s.ogDB.Prepare(`SELECT * FROM table_that_does_not_exist`)
+	require.Error(t, err)
+}
@@ -0,0 +1,196 @@
+package driver
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"database/sql"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
+	"github.com/aousomran/sqlite-og/internal/auth"
+	"github.com/aousomran/sqlite-og/internal/connections"
+	"github.com/aousomran/sqlite-og/internal/server"
+)
+
+// certificate is a certificate & its key, written to PEM files
+type certificate struct {
+	cert     *x509.Certificate
+	key      *ecdsa.PrivateKey
+	certFile string
+	keyFile  string
+}
+
+// newCertificate creates a certificate signed by parent, or a self signed CA
+// when parent is nil
+func newCertificate(t *testing.T, name string, parent *certificate, template *x509.Certificate) *certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	template.SerialNumber = serial
+	template.Subject = pkix.Name{CommonName: name}
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	c := &certificate{
+		cert:     cert,
+		key:      key,
+		certFile: filepath.Join(dir, name+".crt"),
+		keyFile:  filepath.Join(dir, name+".key"),
+	}
+	require.NoError(t, os.WriteFile(c.certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(c.keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600))
+	return c
+}
+
+func startTLSServer(t *testing.T, opts server.TLSOptions) string {
+	return startAuthServer(t, opts, nil, nil)
+}
+
+// startAuthServer starts a TLS server authenticating its clients, without
+// authentication when authenticators is empty
+func startAuthServer(t *testing.T, opts server.TLSOptions, authenticators auth.Authenticators, policy *auth.Policy) string {
+	tlsConfig, err := server.NewTLSConfig(opts)
+	require.NoError(t, err)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	manager := connections.NewManager()
+	serverOpts := []grpc.ServerOption{grpc.Creds(credentials.NewTLS(tlsConfig))}
+	if len(authenticators) > 0 {
+		serverOpts = append(serverOpts,
+			grpc.UnaryInterceptor(auth.UnaryServerInterceptor(authenticators)),
+			grpc.StreamInterceptor(auth.StreamServerInterceptor(authenticators)),
+		)
+	}
+	srv := grpc.NewServer(serverOpts...)
+	sqliteOG := server.New(manager)
+	sqliteOG.Policy = policy
+	pb.RegisterSqliteOGServer(srv, sqliteOG)
+	pb.RegisterAdminServer(srv, server.NewAdmin(sqliteOG))
+	go srv.Serve(listener)
+	t.Cleanup(func() {
+		srv.Stop()
+		_ = manager.Close()
+	})
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+	return net.JoinHostPort("localhost", port)
+}
+
+// ping opens a session on the server with the configuration
+func ping(cfg Config) error {
+	connector, err := NewConnector(cfg)
+	if err != nil {
+		return err
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return db.PingContext(ctx)
+}
+
+func TestTLS(t *testing.T) {
+	ca := newCertificate(t, "ca", nil, &x509.Certificate{})
+	serverCert := newCertificate(t, "server", ca, &x509.Certificate{
+		DNSNames:    []string{"localhost", "sqliteog.test"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	clientCert := newCertificate(t, "client", ca, &x509.Certificate{
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	otherCA := newCertificate(t, "other-ca", nil, &x509.Certificate{})
+	untrustedCert := newCertificate(t, "untrusted", otherCA, &x509.Certificate{
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+
+	t.Run("server certificates are verified", func(t *testing.T) {
+		addr := startTLSServer(t, server.TLSOptions{CertFile: serverCert.certFile, KeyFile: serverCert.keyFile})
+
+		require.NoError(t, ping(Config{Addr: addr, DBName: ":memory:", TLSCAFile: ca.certFile}))
+		require.Error(t, ping(Config{Addr: addr, DBName: ":memory:"}), "plaintext")
+		require.Error(t, ping(Config{Addr: addr, DBName: ":memory:", TLS: true}), "unknown authority")
+		require.Error(t, ping(Config{Addr: addr, DBName: ":memory:", TLSCAFile: otherCA.certFile}), "other authority")
+
+		// the name verified can differ from the address
+		_, port, _ := net.SplitHostPort(addr)
+		db, err := sql.Open("sqliteog", fmt.Sprintf("sqliteog://127.0.0.1:%s/:memory:?tls_ca=%s&tls_server_name=sqliteog.test", port, ca.certFile))
+		require.NoError(t, err)
+		defer db.Close()
+		var one int
+		require.NoError(t, db.QueryRow(`SELECT 1`).Scan(&one))
+		require.Error(t, ping(Config{Addr: addr, DBName: ":memory:", TLSCAFile: ca.certFile, TLSServerName: "other.test"}))
+
+		// a custom configuration is used as is
+		pool := x509.NewCertPool()
+		pool.AddCert(ca.cert)
+		require.NoError(t, ping(Config{Addr: addr, DBName: ":memory:", TLSConfig: &tls.Config{RootCAs: pool}}))
+	})
+
+	t.Run("mutual TLS", func(t *testing.T) {
+		addr := startTLSServer(t, server.TLSOptions{
+			CertFile:          serverCert.certFile,
+			KeyFile:           serverCert.keyFile,
+			ClientCAFile:      ca.certFile,
+			RequireClientCert: true,
+		})
+
+		require.NoError(t, ping(Config{
+			Addr:        addr,
+			DBName:      ":memory:",
+			TLSCAFile:   ca.certFile,
+			TLSCertFile: clientCert.certFile,
+			TLSKeyFile:  clientCert.keyFile,
+		}))
+		require.Error(t, ping(Config{Addr: addr, DBName: ":memory:", TLSCAFile: ca.certFile}), "no client certificate")
+		require.Error(t, ping(Config{
+			Addr:        addr,
+			DBName:      ":memory:",
+			TLSCAFile:   ca.certFile,
+			TLSCertFile: untrustedCert.certFile,
+			TLSKeyFile:  untrustedCert.keyFile,
+		}), "untrusted client certificate")
+	})
+
+	t.Run("invalid configurations", func(t *testing.T) {
+		_, err := NewConnector(Config{Addr: "localhost:9091", TLSCertFile: clientCert.certFile})
+		require.ErrorContains(t, err, "both a certificate & a key")
+		_, err = NewConnector(Config{Addr: "localhost:9091", TLSCAFile: clientCert.keyFile})
+		require.ErrorContains(t, err, "no certificate found")
+		_, err = server.NewTLSConfig(server.TLSOptions{CertFile: serverCert.certFile, KeyFile: serverCert.keyFile, RequireClientCert: true})
+		require.ErrorContains(t, err, "without a client CA")
+	})
+}
@@ -0,0 +1,67 @@
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
+)
+
+// Transaction locking modes, they select between BEGIN DEFERRED,
+// BEGIN IMMEDIATE & BEGIN EXCLUSIVE on the server.
+const (
+	TxLockDeferred  = pb.TxLock_DEFERRED
+	TxLockImmediate = pb.TxLock_IMMEDIATE
+	TxLockExclusive = pb.TxLock_EXCLUSIVE
+)
+
+type txLockKey struct{}
+
+// WithTxLock returns a context that makes db.BeginTx use the given locking
+// mode instead of the one configured on SQLiteOGDriver.
+func WithTxLock(ctx context.Context, lock pb.TxLock) context.Context {
+	return context.WithValue(ctx, txLockKey{}, lock)
+}
+
+type SQLiteOGTx struct {
+	c *SQLiteOGConn
+}
+
+func (t *SQLiteOGTx) Commit() error {
+	_, err := t.c.OGClient.Commit(context.Background(), &pb.ConnectionId{Id: t.c.ID})
+	return err
+}
+
+func (t *SQLiteOGTx) Rollback() error {
+	_, err := t.c.OGClient.Rollback(context.Background(), &pb.ConnectionId{Id: t.c.ID})
+	return err
+}
+
+func (c *SQLiteOGConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *SQLiteOGConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	switch sql.IsolationLevel(opts.Isolation) {
+	case sql.LevelDefault, sql.LevelSerializable:
+	default:
+		return nil, fmt.Errorf("isolation level %s is not supported", sql.IsolationLevel(opts.Isolation))
+	}
+
+	lock := c.TxLock
+	if l, ok := ctx.Value(txLockKey{}).(pb.TxLock); ok {
+		lock = l
+	}
+
+	_, err := c.OGClient.Begin(ctx, &pb.BeginRequest{
+		CnxId:    c.ID,
+		ReadOnly: opts.ReadOnly,
+		Lock:     lock,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &SQLiteOGTx{c: c}, nil
+}
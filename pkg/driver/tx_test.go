@@ -0,0 +1,50 @@
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactions(t *testing.T) {
+	s := setupSuite(t)
+
+	_, err := s.ogDB.Exec(`CREATE TABLE IF NOT EXISTS tx_table (id INTEGER PRIMARY KEY, name TEXT)`)
+	require.NoError(t, err)
+	count := func() int {
+		var c int
+		require.NoError(t, s.sqliteDB.QueryRow(`SELECT count(*) FROM tx_table`).Scan(&c))
+		return c
+	}
+
+	tx, err := s.ogDB.Begin()
+	require.NoError(t, err)
+	_, err = tx.Exec(`INSERT INTO tx_table (name) VALUES (?)`, "rolled back")
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback())
+	require.Equal(t, 0, count())
+
+	tx, err = s.ogDB.BeginTx(WithTxLock(context.Background(), TxLockImmediate), nil)
+	require.NoError(t, err)
+	_, err = tx.Exec(`INSERT INTO tx_table (name) VALUES (?)`, "committed")
+	require.NoError(t, err)
+	var name string
+	require.NoError(t, tx.QueryRow(`SELECT name FROM tx_table`).Scan(&name))
+	require.Equal(t, "committed", name)
+	require.NoError(t, tx.Commit())
+	require.Equal(t, 1, count())
+
+	tx, err = s.ogDB.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: true})
+	require.NoError(t, err)
+	_, err = tx.Exec(`INSERT INTO tx_table (name) VALUES (?)`, "read only")
+	require.Error(t, err)
+	require.NoError(t, tx.Rollback())
+	require.Equal(t, 1, count())
+
+	// the connection is writable again once the read only transaction is over
+	_, err = s.ogDB.Exec(`INSERT INTO tx_table (name) VALUES (?)`, "after read only")
+	require.NoError(t, err)
+	require.Equal(t, 2, count())
+}
@@ -0,0 +1,230 @@
+package driver
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
+)
+
+// Change operations
+const (
+	ChangeInsert = pb.ChangeOperation_INSERT
+	ChangeUpdate = pb.ChangeOperation_UPDATE
+	ChangeDelete = pb.ChangeOperation_DELETE
+)
+
+const minWatchBackoff = 100 * time.Millisecond
+const defaultMaxWatchBackoff = 30 * time.Second
+
+// Change is a change of a row committed by a client of the server
+type Change struct {
+	Table     string
+	Operation pb.ChangeOperation
+	RowID     int64
+	// Values maps the columns of the row to their values as read right after
+	// the commit, it's nil unless WatchOptions.IncludeValues is set, for
+	// deletes & for rows that no longer exist.
+	Values map[string]driver.Value
+	// Token is the position of the change in the changelog, pass it as
+	// ChangesOptions.After to resume after it. It's zero for watched changes.
+	Token uint64
+}
+
+type WatchOptions struct {
+	// Tables are the tables to watch, every table is watched when empty
+	Tables        []string
+	IncludeValues bool
+	// MaxBackoff caps the delay between reconnection attempts (30s by default)
+	MaxBackoff time.Duration
+	// OnError is called with the error ending the stream before reconnecting
+	// or closing the channel, errors are logged when it's nil.
+	OnError func(error)
+}
+
+// Watch streams the changes committed to the database of the dsn, see
+// SQLiteOGConnector.Watch.
+func Watch(ctx context.Context, dsn string, opts WatchOptions) (<-chan Change, error) {
+	ctr, err := (&SQLiteOGDriver{}).OpenConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return ctr.(*SQLiteOGConnector).Watch(ctx, opts)
+}
+
+// Watch streams the changes committed to the connector's database, it
+// returns once the server is watching. The stream is re-established with an
+// exponential backoff when it fails, changes committed while it's
+// disconnected are not delivered, see Changes for a durable stream. The
+// channel is closed once ctx is done.
+func (c *SQLiteOGConnector) Watch(ctx context.Context, opts WatchOptions) (<-chan Change, error) {
+	req := &pb.WatchRequest{
+		DbName:        c.config.DBName,
+		Tables:        opts.Tables,
+		IncludeValues: opts.IncludeValues,
+	}
+	return c.follow(ctx, opts.MaxBackoff, opts.OnError, func(ctx context.Context, client pb.SqliteOGClient) (changeStream, error) {
+		return client.Watch(ctx, req)
+	}, nil)
+}
+
+// changeStream is a Watch or a Changes stream
+type changeStream interface {
+	Header() (metadata.MD, error)
+	Recv() (*pb.ChangeEvent, error)
+}
+
+// follow sends the changes of the streams returned by open to a channel, it
+// returns once the first stream is established. A failed stream is reopened
+// with an exponential backoff unless it failed with a status that retrying
+// cannot fix, the channel is closed then & once ctx is done. sent is called
+// with every change sent to the channel.
+func (c *SQLiteOGConnector) follow(ctx context.Context, maxBackoff time.Duration, onError func(error),
+	open func(context.Context, pb.SqliteOGClient) (changeStream, error), sent func(Change)) (<-chan Change, error) {
+	grpcConn, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+	client := pb.NewSqliteOGClient(grpcConn)
+	stream, err := subscribe(ctx, client, open)
+	if err != nil {
+		_ = grpcConn.Close()
+		return nil, err
+	}
+
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxWatchBackoff
+	}
+	if onError == nil {
+		onError = func(err error) {
+			log.Printf("streaming the changes of %s failed: %v", c.config.DBName, err)
+		}
+	}
+
+	changes := make(chan Change)
+	go func() {
+		defer close(changes)
+		defer grpcConn.Close()
+		for {
+			err := receiveChanges(ctx, stream, changes, sent)
+			if ctx.Err() != nil {
+				return
+			}
+			onError(err)
+			if !retryable(err) {
+				return
+			}
+
+			backoff := minWatchBackoff
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(backoff):
+				}
+				stream, err = subscribe(ctx, client, open)
+				if err == nil {
+					break
+				}
+				if ctx.Err() != nil {
+					return
+				}
+				onError(err)
+				if !retryable(err) {
+					return
+				}
+				backoff = min(2*backoff, maxBackoff)
+			}
+		}
+	}()
+	return changes, nil
+}
+
+// retryable reports whether reopening a stream that failed with err may
+// succeed
+func retryable(err error) bool {
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange,
+		codes.Unimplemented, codes.PermissionDenied, codes.Unauthenticated:
+		return false
+	default:
+		return true
+	}
+}
+
+// subscribe opens a stream & waits for the server to be streaming
+func subscribe(ctx context.Context, client pb.SqliteOGClient,
+	open func(context.Context, pb.SqliteOGClient) (changeStream, error)) (changeStream, error) {
+	stream, err := open(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	md, err := stream.Header()
+	if err != nil {
+		return nil, err
+	}
+	if md == nil {
+		// the stream ended without headers, Recv returns its status
+		if _, err = stream.Recv(); err == nil || err == io.EOF {
+			err = errors.New("change stream ended")
+		}
+		return nil, err
+	}
+	return stream, nil
+}
+
+func receiveChanges(ctx context.Context, stream changeStream, changes chan<- Change, sent func(Change)) error {
+	for {
+		ev, err := stream.Recv()
+		if err == io.EOF {
+			return errors.New("change stream ended")
+		}
+		if err != nil {
+			return err
+		}
+		change := Change{
+			Table:     ev.GetTable(),
+			Operation: ev.GetOperation(),
+			RowID:     ev.GetRowid(),
+			Token:     ev.GetToken(),
+		}
+		if ev.GetValues() != nil {
+			change.Values = make(map[string]driver.Value, len(ev.GetColumns()))
+			for i, col := range ev.GetColumns() {
+				change.Values[col] = plainValue(ev.GetValues().GetFields()[i])
+			}
+		}
+		select {
+		case changes <- change:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if sent != nil {
+			sent(change)
+		}
+	}
+}
+
+// plainValue converts a value without a column type, as stored by sqlite
+func plainValue(v *pb.Value) driver.Value {
+	switch val := v.GetValue().(type) {
+	case *pb.Value_Integer:
+		return val.Integer
+	case *pb.Value_Real:
+		return val.Real
+	case *pb.Value_Text:
+		return val.Text
+	case *pb.Value_Blob:
+		return val.Blob
+	default:
+		return nil
+	}
+}
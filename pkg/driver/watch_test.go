@@ -0,0 +1,159 @@
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	pb "github.com/aousomran/sqlite-og/gen/proto"
+	"github.com/aousomran/sqlite-og/internal/connections"
+	"github.com/aousomran/sqlite-og/internal/server"
+)
+
+func TestWatch(t *testing.T) {
+	s := setupSuite(t)
+
+	_, err := s.ogDB.Exec(`CREATE TABLE IF NOT EXISTS watched_items (id INTEGER PRIMARY KEY, name TEXT, qty INTEGER)`)
+	require.NoError(t, err)
+	_, err = s.ogDB.Exec(`CREATE TABLE IF NOT EXISTS unwatched_items (id INTEGER PRIMARY KEY, name TEXT)`)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes, err := Watch(ctx, s.dsn, WatchOptions{Tables: []string{"watched_items"}, IncludeValues: true})
+	require.NoError(t, err)
+
+	next := func() Change {
+		select {
+		case change := <-changes:
+			return change
+		case <-time.After(5 * time.Second):
+			t.Fatal("no change received")
+			return Change{}
+		}
+	}
+	none := func() {
+		select {
+		case change := <-changes:
+			t.Fatalf("unexpected change %+v", change)
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+
+	result, err := s.ogDB.Exec(`INSERT INTO watched_items (name, qty) VALUES (?, ?)`, "apple", 3)
+	require.NoError(t, err)
+	id, err := result.LastInsertId()
+	require.NoError(t, err)
+	change := next()
+	require.Equal(t, "watched_items", change.Table)
+	require.Equal(t, ChangeInsert, change.Operation)
+	require.Equal(t, id, change.RowID)
+	require.Equal(t, map[string]driver.Value{"id": id, "name": "apple", "qty": int64(3)}, change.Values)
+
+	// other tables are filtered
+	_, err = s.ogDB.Exec(`INSERT INTO unwatched_items (name) VALUES ('pear')`)
+	require.NoError(t, err)
+	none()
+
+	// changes are only sent once committed
+	tx, err := s.ogDB.Begin()
+	require.NoError(t, err)
+	_, err = tx.Exec(`UPDATE watched_items SET qty = 4 WHERE id = ?`, id)
+	require.NoError(t, err)
+	none()
+	require.NoError(t, tx.Commit())
+	change = next()
+	require.Equal(t, ChangeUpdate, change.Operation)
+	require.Equal(t, int64(4), change.Values["qty"])
+
+	// rolled back & failed statements are never sent
+	tx, err = s.ogDB.Begin()
+	require.NoError(t, err)
+	_, err = tx.Exec(`DELETE FROM watched_items`)
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback())
+	_, err = s.ogDB.Exec(`INSERT INTO watched_items (id, name) VALUES (?, 'duplicate')`, id)
+	require.Error(t, err)
+	none()
+
+	_, err = s.ogDB.Exec(`DELETE FROM watched_items WHERE id = ?`, id)
+	require.NoError(t, err)
+	change = next()
+	require.Equal(t, ChangeDelete, change.Operation)
+	require.Equal(t, id, change.RowID)
+	require.Nil(t, change.Values)
+
+	_, err = Watch(ctx, fmt.Sprintf("%s/:memory:", s.addr), WatchOptions{})
+	require.ErrorContains(t, err, "cannot be watched")
+
+	cancel()
+	_, open := <-changes
+	require.False(t, open)
+}
+
+func TestWatchReconnects(t *testing.T) {
+	s := setupSuite(t)
+
+	start := func(listener net.Listener) *grpc.Server {
+		manager := connections.NewManager()
+		manager.DataDir = s.dataDir
+		srv := grpc.NewServer()
+		pb.RegisterSqliteOGServer(srv, server.New(manager))
+		go srv.Serve(listener)
+		return srv
+	}
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	srv := start(listener)
+
+	_, err = s.ogDB.Exec(`CREATE TABLE IF NOT EXISTS watched_items (id INTEGER PRIMARY KEY, name TEXT, qty INTEGER)`)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errs := make(chan error, 10)
+	changes, err := Watch(ctx, fmt.Sprintf("%s/%s", addr, databaseName), WatchOptions{
+		MaxBackoff: 200 * time.Millisecond,
+		OnError:    func(err error) { errs <- err },
+	})
+	require.NoError(t, err)
+
+	srv.Stop()
+	select {
+	case err := <-errs:
+		require.Error(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream failure not reported")
+	}
+
+	listener, err = net.Listen("tcp", addr)
+	require.NoError(t, err)
+	srv = start(listener)
+	defer srv.Stop()
+
+	// changes are sent again once the watch is re-established
+	db, err := sql.Open("sqliteog", fmt.Sprintf("%s/%s", addr, databaseName))
+	require.NoError(t, err)
+	defer db.Close()
+	deadline := time.After(10 * time.Second)
+	for {
+		_, err = db.Exec(`INSERT INTO watched_items (name) VALUES ('after restart')`)
+		require.NoError(t, err)
+		select {
+		case change := <-changes:
+			require.Equal(t, ChangeInsert, change.Operation)
+			return
+		case <-time.After(100 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("watch was not re-established")
+		}
+	}
+}